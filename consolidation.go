@@ -0,0 +1,327 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ConsolidationMove a pod rescheduled from a drained node to the target node
+type ConsolidationMove struct {
+	Pod    Pod
+	Target string
+}
+
+// NodeConsolidation result of the simulation for one node
+// Pods are the pods running in the node at the end of the simulation, empty if the node is removable
+type NodeConsolidation struct {
+	Node      Node
+	Removable bool
+	Reason    string
+	Moves     []ConsolidationMove
+	Pods      []Pod
+}
+
+// GetAllocatedCPU % of allocatable cpu requested by the pods before the simulation
+func (c NodeConsolidation) GetAllocatedCPU() float32 {
+	w := Wrapper{Pods: c.Node.Pods}
+	return percentage(w.GetRequestsMilliCPU(), c.Node.GetAllocatableMilliCPU())
+}
+
+// GetAllocatedMemory % of allocatable memory requested by the pods before the simulation
+func (c NodeConsolidation) GetAllocatedMemory() float32 {
+	w := Wrapper{Pods: c.Node.Pods}
+	return percentage(w.GetRequestsMiMemory(), c.Node.GetAllocatableMiMemory())
+}
+
+// GetTargets returns eg. "node-a (3 pods),node-b (1 pod)"
+func (c NodeConsolidation) GetTargets() string {
+	counts := make(map[string]int)
+	targets := []string{}
+	for _, move := range c.Moves {
+		if counts[move.Target] == 0 {
+			targets = append(targets, move.Target)
+		}
+		counts[move.Target]++
+	}
+	str := ""
+	for _, target := range targets {
+		if len(str) > 0 {
+			str += ","
+		}
+		str += fmt.Sprintf("%s (%s)", target, plural(counts[target], "pod"))
+	}
+	return str
+}
+
+// NodepoolConsolidation per node pool rollup of the simulation
+type NodepoolConsolidation struct {
+	Name  string
+	Nodes []NodeConsolidation
+}
+
+// CountRemovable ..
+func (np NodepoolConsolidation) CountRemovable() int {
+	count := 0
+	for _, c := range np.Nodes {
+		if c.Removable {
+			count++
+		}
+	}
+	return count
+}
+
+// GetInstanceTypes returns the instance types of the nodes of the pool, see Nodepool.GetInstanceTypes
+func (np NodepoolConsolidation) GetInstanceTypes() string {
+	nodes := []Node{}
+	for _, c := range np.Nodes {
+		nodes = append(nodes, c.Node)
+	}
+	return Nodepool{Nodes: nodes}.GetInstanceTypes()
+}
+
+// GetFreedAllocatableMilliCPU allocatable cpu of the removable nodes
+func (np NodepoolConsolidation) GetFreedAllocatableMilliCPU() int {
+	total := 0
+	for _, c := range np.Nodes {
+		if c.Removable {
+			total += c.Node.GetAllocatableMilliCPU()
+		}
+	}
+	return total
+}
+
+// GetFreedAllocatableMiMemory allocatable memory of the removable nodes
+func (np NodepoolConsolidation) GetFreedAllocatableMiMemory() int {
+	total := 0
+	for _, c := range np.Nodes {
+		if c.Removable {
+			total += c.Node.GetAllocatableMiMemory()
+		}
+	}
+	return total
+}
+
+// GetAllocatedCPUAfter % of allocatable cpu requested in the remaining nodes at the end of the simulation
+func (np NodepoolConsolidation) GetAllocatedCPUAfter() float32 {
+	requests, allocatable := 0, 0
+	for _, c := range np.Nodes {
+		if !c.Removable {
+			requests += Wrapper{Pods: c.Pods}.GetRequestsMilliCPU()
+			allocatable += c.Node.GetAllocatableMilliCPU()
+		}
+	}
+	return percentage(requests, allocatable)
+}
+
+// GetAllocatedMemoryAfter % of allocatable memory requested in the remaining nodes at the end of the simulation
+func (np NodepoolConsolidation) GetAllocatedMemoryAfter() float32 {
+	requests, allocatable := 0, 0
+	for _, c := range np.Nodes {
+		if !c.Removable {
+			requests += Wrapper{Pods: c.Pods}.GetRequestsMiMemory()
+			allocatable += c.Node.GetAllocatableMiMemory()
+		}
+	}
+	return percentage(requests, allocatable)
+}
+
+// schedulingNode keeps the pods assigned to a node during the simulation
+type schedulingNode struct {
+	node    Node
+	pods    []Pod
+	removed bool
+}
+
+func (s *schedulingNode) getAllocated() float32 {
+	w := Wrapper{Pods: s.pods}
+	cpu := percentage(w.GetRequestsMilliCPU(), s.node.GetAllocatableMilliCPU())
+	memory := percentage(w.GetRequestsMiMemory(), s.node.GetAllocatableMiMemory())
+	if cpu > memory {
+		return cpu
+	}
+	return memory
+}
+
+// fits returns true if the node has room for the pod requests and the pod can be scheduled on it
+func (s *schedulingNode) fits(pod Pod) bool {
+	if s.removed || s.node.IsUnschedulable() || !s.node.IsReady() {
+		return false
+	}
+	if !pod.ToleratesTaints(s.node) || !pod.MatchesNodeSelector(s.node) {
+		return false
+	}
+	w := Wrapper{Pods: s.pods}
+	if w.GetRequestsMilliCPU()+pod.GetRequestsMilliCPU() > s.node.GetAllocatableMilliCPU() ||
+		w.GetRequestsMiMemory()+pod.GetRequestsMiMemory() > s.node.GetAllocatableMiMemory() ||
+		len(s.pods)+1 > s.node.GetAllocatablePods() {
+		return false
+	}
+	for _, name := range pod.GetExtendedResourceNames() {
+		requested := 0
+		for _, p := range s.pods {
+			requested += p.GetRequestsExtended(name)
+		}
+		if requested+pod.GetRequestsExtended(name) > s.node.GetAllocatableExtended(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// isNodeBoundPod returns true for DaemonSet and static pods, they go away with the node and don't need to be rescheduled
+func isNodeBoundPod(pod Pod) bool {
+	kind := pod.GetWorkloadKind()
+	return kind == "DaemonSet" || kind == "Node"
+}
+
+// checkDrainBlockers returns why the pods can't be evicted: bare pods are not recreated
+// and a pdb can't lose more pods than its disruptions allowed
+func checkDrainBlockers(pods []Pod, pdbList []Pdb) string {
+	evictions := make(map[string]int)
+	for _, pod := range pods {
+		if pod.GetWorkloadKind() == "Pod" {
+			return fmt.Sprintf("bare pod %s/%s would not be recreated", pod.Metadata.Namespace, pod.Metadata.Name)
+		}
+		for _, pdb := range pdbList {
+			if pdb.Metadata.Namespace == pod.Metadata.Namespace && pdb.match(pod.Metadata.Labels) {
+				key := pdb.Metadata.Namespace + "/" + pdb.Metadata.Name
+				evictions[key]++
+				if evictions[key] > pdb.Status.DisruptionsAllowed {
+					return fmt.Sprintf("pdb %s allows %d disruptions", key, pdb.Status.DisruptionsAllowed)
+				}
+				break
+			}
+		}
+	}
+	return ""
+}
+
+// SimulateConsolidation runs a scheduler-like bin-packing pass: nodes are drained from the least allocated to
+// the most allocated, each pod (biggest requests first) is placed on the most allocated node where it fits.
+// Requests, extended resources, taints/tolerations, nodeSelector and required node affinity are respected.
+// Pods with required pod anti-affinity or DoNotSchedule topology spread constraints block the drain.
+// DaemonSet and static pods are ignored, bare pods and pdbs without enough disruptions allowed block the drain.
+// Nodes are drained one by one, so the pdb budget is checked per node.
+// The result is sorted by node pool and node name
+func SimulateConsolidation(nodeList []Node, pdbList []Pdb) []NodeConsolidation {
+	state := []*schedulingNode{}
+	for _, node := range nodeList {
+		state = append(state, &schedulingNode{node: node, pods: append([]Pod{}, node.Pods...)})
+	}
+	candidates := append([]*schedulingNode{}, state...)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		ai, aj := candidates[i].getAllocated(), candidates[j].getAllocated()
+		if ai != aj {
+			return ai < aj
+		}
+		return candidates[i].node.GetName() < candidates[j].node.GetName()
+	})
+
+	results := make(map[string]NodeConsolidation)
+	for _, candidate := range candidates {
+		result := NodeConsolidation{Node: candidate.node}
+		pods := []Pod{}
+		for _, pod := range candidate.pods {
+			if !isNodeBoundPod(pod) {
+				pods = append(pods, pod)
+			}
+		}
+		result.Reason = checkDrainBlockers(pods, pdbList)
+		if result.Reason == "" {
+			result.Moves, result.Reason = placePods(candidate, pods, state)
+		}
+		if result.Reason == "" {
+			result.Removable = true
+			candidate.removed = true
+			candidate.pods = nil
+			for _, move := range result.Moves {
+				for _, target := range state {
+					if target.node.GetName() == move.Target {
+						target.pods = append(target.pods, move.Pod)
+					}
+				}
+			}
+		} else {
+			result.Moves = nil
+		}
+		results[candidate.node.GetName()] = result
+	}
+
+	consolidations := []NodeConsolidation{}
+	for _, s := range state {
+		result := results[s.node.GetName()]
+		result.Pods = s.pods
+		consolidations = append(consolidations, result)
+	}
+	sort.SliceStable(consolidations, func(i, j int) bool {
+		pi, pj := consolidations[i].Node.GetNodepool(), consolidations[j].Node.GetNodepool()
+		if pi != pj {
+			return pi < pj
+		}
+		return consolidations[i].Node.GetName() < consolidations[j].Node.GetName()
+	})
+	return consolidations
+}
+
+// placePods returns where each pod goes, or the reason why a pod doesn't fit anywhere
+// pods with required anti-affinity or DoNotSchedule spread constraints block the move, they are not simulated
+// the placements are not applied to the state
+func placePods(candidate *schedulingNode, pods []Pod, state []*schedulingNode) ([]ConsolidationMove, string) {
+	for _, pod := range pods {
+		if constraint := pod.GetUnsimulatedConstraint(); constraint != "" {
+			return nil, fmt.Sprintf("%s/%s has %s", pod.Metadata.Namespace, pod.Metadata.Name, constraint)
+		}
+	}
+	sorted := append([]Pod{}, pods...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ci, cj := sorted[i].GetRequestsMilliCPU(), sorted[j].GetRequestsMilliCPU()
+		if ci != cj {
+			return ci > cj
+		}
+		return sorted[i].GetRequestsMiMemory() > sorted[j].GetRequestsMiMemory()
+	})
+
+	// work on copies, so a failed drain leaves the state untouched
+	targets := []*schedulingNode{}
+	for _, s := range state {
+		if s != candidate && !s.removed {
+			targets = append(targets, &schedulingNode{node: s.node, pods: append([]Pod{}, s.pods...)})
+		}
+	}
+
+	moves := []ConsolidationMove{}
+	for _, pod := range sorted {
+		sort.SliceStable(targets, func(i, j int) bool {
+			return targets[i].getAllocated() > targets[j].getAllocated()
+		})
+		placed := false
+		for _, target := range targets {
+			if target.fits(pod) {
+				target.pods = append(target.pods, pod)
+				moves = append(moves, ConsolidationMove{Pod: pod, Target: target.node.GetName()})
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			return nil, fmt.Sprintf("no node fits %s/%s (%dm, %dMi)", pod.Metadata.Namespace, pod.Metadata.Name, pod.GetRequestsMilliCPU(), pod.GetRequestsMiMemory())
+		}
+	}
+	return moves, ""
+}
+
+// BuildNodepoolConsolidations groups the simulation results by node pool
+func BuildNodepoolConsolidations(consolidations []NodeConsolidation) []NodepoolConsolidation {
+	nodepools := []NodepoolConsolidation{}
+	for _, c := range consolidations {
+		name := c.Node.GetNodepool()
+		if name == "" {
+			name = "<none>"
+		}
+		if len(nodepools) == 0 || nodepools[len(nodepools)-1].Name != name {
+			nodepools = append(nodepools, NodepoolConsolidation{Name: name})
+		}
+		nodepools[len(nodepools)-1].Nodes = append(nodepools[len(nodepools)-1].Nodes, c)
+	}
+	return nodepools
+}
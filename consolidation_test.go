@@ -0,0 +1,140 @@
+package main
+
+import (
+	"testing"
+)
+
+const consolidationNodes = `{"items": [
+	{"metadata": {"name": "node-a", "labels": {"cloud.google.com/gke-nodepool": "default"}},
+	 "status": {"allocatable": {"cpu": "4", "memory": "8Gi", "pods": "110"}, "conditions": [{"type": "Ready", "status": "True"}]}},
+	{"metadata": {"name": "node-b", "labels": {"cloud.google.com/gke-nodepool": "default"}},
+	 "status": {"allocatable": {"cpu": "4", "memory": "8Gi", "pods": "110"}, "conditions": [{"type": "Ready", "status": "True"}]}},
+	{"metadata": {"name": "node-c", "labels": {"cloud.google.com/gke-nodepool": "batch", "workload": "batch"}},
+	 "spec": {"taints": [{"key": "dedicated", "value": "batch", "effect": "NoSchedule"}]},
+	 "status": {"allocatable": {"cpu": "4", "memory": "8Gi", "pods": "110"}, "conditions": [{"type": "Ready", "status": "True"}]}}
+]}`
+
+const consolidationPods = `{"items": [
+	{"metadata": {"name": "api-7d9f8b6c5d-x1x1x", "namespace": "shop", "labels": {"app": "api"}, "ownerReferences": [{"kind": "ReplicaSet", "name": "api-7d9f8b6c5d"}]},
+	 "spec": {"nodeName": "node-a", "containers": [{"name": "c", "resources": {"requests": {"cpu": "500m", "memory": "512Mi"}}}]}},
+	{"metadata": {"name": "fluentd-a", "namespace": "kube-system", "ownerReferences": [{"kind": "DaemonSet", "name": "fluentd"}]},
+	 "spec": {"nodeName": "node-a", "containers": [{"name": "c", "resources": {"requests": {"cpu": "100m", "memory": "128Mi"}}}]}},
+	{"metadata": {"name": "worker-6b5c4d3e2f-y2y2y", "namespace": "shop", "labels": {"app": "worker"}, "ownerReferences": [{"kind": "ReplicaSet", "name": "worker-6b5c4d3e2f"}]},
+	 "spec": {"nodeName": "node-b", "containers": [{"name": "c", "resources": {"requests": {"cpu": "3", "memory": "4Gi"}}}]}},
+	{"metadata": {"name": "fluentd-b", "namespace": "kube-system", "ownerReferences": [{"kind": "DaemonSet", "name": "fluentd"}]},
+	 "spec": {"nodeName": "node-b", "containers": [{"name": "c", "resources": {"requests": {"cpu": "100m", "memory": "128Mi"}}}]}},
+	{"metadata": {"name": "report-1572566400-z3z3z", "namespace": "batch", "ownerReferences": [{"kind": "Job", "name": "report-1572566400"}]},
+	 "spec": {"nodeName": "node-c", "nodeSelector": {"workload": "batch"}, "tolerations": [{"key": "dedicated", "operator": "Equal", "value": "batch", "effect": "NoSchedule"}],
+	  "containers": [{"name": "c", "resources": {"requests": {"cpu": "1", "memory": "1Gi"}}}]}}
+]}`
+
+func loadConsolidationNodes() []Node {
+	pods := buildPodList(consolidationPods).Items
+	nodes := buildNodeList(consolidationNodes).Items
+	for i, node := range nodes {
+		for _, pod := range pods {
+			if pod.Spec.NodeName == node.GetName() {
+				nodes[i].Pods = append(nodes[i].Pods, pod)
+			}
+		}
+	}
+	return nodes
+}
+
+func TestSimulateConsolidation(t *testing.T) {
+	consolidations := SimulateConsolidation(loadConsolidationNodes(), []Pdb{})
+
+	// sorted by node pool, then node name
+	c, a, b := consolidations[0], consolidations[1], consolidations[2]
+	if !a.Removable || len(a.Moves) != 1 || a.GetTargets() != "node-b (1 pod)" || len(a.Pods) != 0 {
+		t.Fatalf("Test failed! %+v", a)
+	}
+	if c.Removable || c.Reason != "no node fits batch/report-1572566400-z3z3z (1000m, 1024Mi)" {
+		t.Fatalf("Test failed! %+v", c)
+	}
+	// the worker doesn't tolerate the batch taint
+	if b.Removable || b.Reason != "no node fits shop/worker-6b5c4d3e2f-y2y2y (3000m, 4096Mi)" || len(b.Pods) != 3 {
+		t.Fatalf("Test failed! %+v", b)
+	}
+
+	nodepools := BuildNodepoolConsolidations(consolidations)
+	if len(nodepools) != 2 || nodepools[1].Name != "default" || nodepools[1].CountRemovable() != 1 ||
+		nodepools[1].GetFreedAllocatableMilliCPU() != 4000 || nodepools[1].GetFreedAllocatableMiMemory() != 8192 ||
+		nodepools[1].GetAllocatedCPUAfter() != 90 {
+		t.Fatalf("Test failed! %+v", nodepools)
+	}
+}
+
+func TestSimulateConsolidationBlockers(t *testing.T) {
+	pdb := Pdb{}
+	pdb.Metadata.Name = "api"
+	pdb.Metadata.Namespace = "shop"
+	pdb.Spec.Selector.MatchLabels = map[string]string{"app": "api"}
+	consolidations := SimulateConsolidation(loadConsolidationNodes(), []Pdb{pdb})
+	if a := consolidations[1]; a.Removable || a.Reason != "pdb shop/api allows 0 disruptions" {
+		t.Fatalf("Test failed! %+v", a)
+	}
+
+	nodes := loadConsolidationNodes()
+	nodes[0].Pods[0].Metadata.OwnerReferences = nil
+	consolidations = SimulateConsolidation(nodes, []Pdb{})
+	if a := consolidations[1]; a.Removable || a.Reason != "bare pod shop/api-7d9f8b6c5d-x1x1x would not be recreated" {
+		t.Fatalf("Test failed! %+v", a)
+	}
+
+	nodes = loadConsolidationNodes()
+	nodes[0].Pods[0].Spec.TopologySpreadConstraints = []TopologySpreadConstraint{{MaxSkew: 1, TopologyKey: "topology.kubernetes.io/zone", WhenUnsatisfiable: "DoNotSchedule"}}
+	consolidations = SimulateConsolidation(nodes, []Pdb{})
+	if a := consolidations[1]; a.Removable || a.Reason != "shop/api-7d9f8b6c5d-x1x1x has DoNotSchedule topology spread constraint on topology.kubernetes.io/zone" {
+		t.Fatalf("Test failed! %+v", a)
+	}
+
+	nodes = loadConsolidationNodes()
+	nodes[0].Pods[0].Spec.Affinity.PodAntiAffinity.Required = []PodAffinityTerm{{TopologyKey: "kubernetes.io/hostname"}}
+	consolidations = SimulateConsolidation(nodes, []Pdb{})
+	if a := consolidations[1]; a.Removable || a.Reason != "shop/api-7d9f8b6c5d-x1x1x has required pod anti-affinity on kubernetes.io/hostname" {
+		t.Fatalf("Test failed! %+v", a)
+	}
+
+	// once node-b can't receive pods, the batch node is the only target left, but only the job tolerates it
+	nodes = loadConsolidationNodes()
+	nodes[1].Spec.Unschedulable = true
+	consolidations = SimulateConsolidation(nodes, []Pdb{})
+	if a := consolidations[1]; a.Removable {
+		t.Fatalf("Test failed! %+v", a)
+	}
+}
+
+func TestSchedulingPredicates(t *testing.T) {
+	nodes := loadConsolidationNodes()
+	job, api := nodes[2].Pods[0], nodes[0].Pods[0]
+	if !job.ToleratesTaints(nodes[2]) || !job.MatchesNodeSelector(nodes[2]) || job.MatchesNodeSelector(nodes[0]) {
+		t.Fatalf("Test failed! %+v", job.Spec)
+	}
+	if api.ToleratesTaints(nodes[2]) || !api.ToleratesTaints(nodes[0]) {
+		t.Fatalf("Test failed! %+v", api.Spec)
+	}
+
+	tests := []struct {
+		requirement NodeSelectorRequirement
+		expected    bool
+	}{
+		{NodeSelectorRequirement{Key: "workload", Operator: "In", Values: []string{"batch", "ml"}}, true},
+		{NodeSelectorRequirement{Key: "workload", Operator: "NotIn", Values: []string{"batch"}}, false},
+		{NodeSelectorRequirement{Key: "workload", Operator: "Exists"}, true},
+		{NodeSelectorRequirement{Key: "gpu", Operator: "DoesNotExist"}, true},
+		{NodeSelectorRequirement{Key: "cores", Operator: "Gt", Values: []string{"8"}}, true},
+		{NodeSelectorRequirement{Key: "cores", Operator: "Lt", Values: []string{"8"}}, false},
+	}
+	labels := map[string]string{"workload": "batch", "cores": "16"}
+	for _, test := range tests {
+		if test.requirement.Matches(labels) != test.expected {
+			t.Fatalf("Test failed! %+v expected %t", test.requirement, test.expected)
+		}
+	}
+
+	if !(Toleration{Operator: "Exists"}).Tolerates(Taint{Key: "any", Effect: "NoExecute"}) ||
+		(Toleration{Key: "dedicated", Operator: "Equal", Value: "ml"}).Tolerates(Taint{Key: "dedicated", Value: "batch", Effect: "NoSchedule"}) {
+		t.Fatalf("Test failed! tolerations")
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+// PodContainer a container spec of a pod with its respective top usage
+type PodContainer struct {
+	Pod  Pod
+	Spec ContainerSpec
+	Top  Container
+}
+
+// GetRequestsMilliCPU ..
+func (c PodContainer) GetRequestsMilliCPU() int {
+	return c.Spec.Resources.Requests.GetMilliCPU()
+}
+
+// GetTopMilliCPU ..
+func (c PodContainer) GetTopMilliCPU() int {
+	return c.Top.GetMilliCPU()
+}
+
+// GetUsageCPU %
+func (c PodContainer) GetUsageCPU() float32 {
+	top := float32(c.GetTopMilliCPU())
+	requests := float32(c.GetRequestsMilliCPU())
+	if top == 0 && requests != 0 {
+		return 0
+	} else if requests == 0 {
+		return 100
+	}
+	return top / requests * 100
+}
+
+// GetRequestsMiMemory ..
+func (c PodContainer) GetRequestsMiMemory() int {
+	return c.Spec.Resources.Requests.GetMiMemory()
+}
+
+// GetTopMiMemory ..
+func (c PodContainer) GetTopMiMemory() int {
+	return c.Top.GetMiMemory()
+}
+
+// GetUsageMemory %
+func (c PodContainer) GetUsageMemory() float32 {
+	top := float32(c.GetTopMiMemory())
+	requests := float32(c.GetRequestsMiMemory())
+	if top == 0 && requests != 0 {
+		return 0
+	} else if requests == 0 {
+		return 100
+	}
+	return top / requests * 100
+}
+
+// GetLimitsMilliCPU ..
+func (c PodContainer) GetLimitsMilliCPU() int {
+	return c.Spec.Resources.Limits.GetMilliCPU()
+}
+
+// GetLimitsMiMemory ..
+func (c PodContainer) GetLimitsMiMemory() int {
+	return c.Spec.Resources.Limits.GetMiMemory()
+}
+
+// BuildPodContainers returns one entry per container of each pod
+func BuildPodContainers(podList []Pod) (ret []PodContainer) {
+	for _, pod := range podList {
+		for _, spec := range pod.Spec.Containers {
+			top, _ := pod.Top.GetContainer(spec.Name)
+			ret = append(ret, PodContainer{Pod: pod, Spec: spec, Top: top})
+		}
+	}
+	return
+}
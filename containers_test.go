@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestBuildPodContainers(t *testing.T) {
+	pods := loadPodsWithTop(t, "test-data/many-pods.json", "test-data/top-many-pods.txt")
+	containers := BuildPodContainers(pods)
+
+	ex := 0
+	for _, pod := range pods {
+		ex += len(pod.Spec.Containers)
+	}
+	if l := len(containers); l != ex {
+		t.Fatalf("Test failed! found %d expected %d", l, ex)
+	}
+
+	server := containers[0]
+	if server.Pod.Metadata.Name != "adservice-74c5fd9c95-mmhkn" || server.Spec.Name != "server" {
+		t.Fatalf("Test failed! %s/%s", server.Pod.Metadata.Name, server.Spec.Name)
+	}
+	if server.GetRequestsMilliCPU() != 200 || server.GetTopMilliCPU() != 20 || server.GetUsageCPU() != 10 ||
+		server.GetRequestsMiMemory() != 180 || server.GetTopMiMemory() != 179 ||
+		server.GetLimitsMilliCPU() != 300 || server.GetLimitsMiMemory() != 300 {
+		t.Fatalf("Test failed! %+v", server)
+	}
+	if server.Spec.LivenessProbe.String() != "Exec: /bin/grpc_health_probe -addr=:9555" || server.Spec.HasPreStop() {
+		t.Fatalf("Test failed! %s", server.Spec.LivenessProbe.String())
+	}
+
+	proxy := containers[1]
+	if proxy.Spec.Name != "istio-proxy" || proxy.GetTopMilliCPU() != 5 || proxy.GetTopMiMemory() != 38 {
+		t.Fatalf("Test failed! %+v", proxy)
+	}
+}
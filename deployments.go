@@ -87,7 +87,7 @@ func (d Deployment) GetLifecyclePreStop() string {
 
 // RetrieveDeployments executes kubectl get deployments command
 // if ns is empty, then all namespaces are used
-func RetrieveDeployments(nsFilter string, podList []Pod) []Deployment {
+func RetrieveDeployments(nsFilter string, podList []Pod, pdbList []Pdb) []Deployment {
 	cmd := "kubectl get deployments --all-namespaces --no-headers"
 	out, err := exec.Command("bash", "-c", cmd).CombinedOutput()
 	if err != nil {
@@ -95,13 +95,12 @@ func RetrieveDeployments(nsFilter string, podList []Pod) []Deployment {
 	}
 	data := string(out)
 	deploys := buildDeploymentList(data, nsFilter, podList)
-	deploys = enrichDeployWithPdb(deploys)
+	deploys = enrichDeployWithPdb(deploys, pdbList)
 	return deploys
 }
 
-func enrichDeployWithPdb(deploys []Deployment) (ret []Deployment) {
+func enrichDeployWithPdb(deploys []Deployment, pdbs []Pdb) (ret []Deployment) {
 	//TODO: improve performance in this func
-	pdbs := RetrievePdbs()
 	for _, deploy := range deploys {
 		if len(deploy.Pods) > 0 {
 			for _, pdb := range pdbs {
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NodeDrain the drain plan of one node
+// Wave is the step in which the node is drained, nodes of the same wave are drained in parallel, 0 if the node can't be drained
+// Evicted are the pods evicted by kubectl drain at that time, DaemonSet and static pods stay on the node
+type NodeDrain struct {
+	Node         Node
+	Wave         int
+	Evicted      []Pod
+	Unmanaged    []Pod
+	LocalStorage []Pod
+	Blockers     []string
+	PdbEvictions map[string]int
+}
+
+// IsBlocked ..
+func (d NodeDrain) IsBlocked() bool {
+	return len(d.Blockers) > 0
+}
+
+// GetWave returns the wave, - when the node can't be drained
+func (d NodeDrain) GetWave() string {
+	if d.Wave == 0 {
+		return "-"
+	}
+	return strconv.Itoa(d.Wave)
+}
+
+// GetStatus returns blocked, the data lost by the drain or ok
+func (d NodeDrain) GetStatus() string {
+	switch {
+	case d.IsBlocked():
+		return "blocked"
+	case len(d.Unmanaged) > 0 && len(d.LocalStorage) > 0:
+		return "pods and local data lost"
+	case len(d.Unmanaged) > 0:
+		return "pods lost"
+	case len(d.LocalStorage) > 0:
+		return "local data lost"
+	}
+	return "ok"
+}
+
+// GetPdbs returns the pdbs touched by the drain, eg. "shop/api (2/1)" means 2 evictions and 1 disruption allowed
+func (d NodeDrain) GetPdbs(pdbList []Pdb) string {
+	pdbs := []string{}
+	for _, pdb := range pdbList {
+		if count, ok := d.PdbEvictions[pdb.GetKey()]; ok {
+			pdbs = append(pdbs, fmt.Sprintf("%s (%d/%d)", pdb.GetKey(), count, pdb.Status.DisruptionsAllowed))
+		}
+	}
+	sort.Strings(pdbs)
+	return strings.Join(pdbs, ",")
+}
+
+// getPodNames returns namespace/name of the pods joined by comma
+func getPodNames(pods []Pod) string {
+	names := []string{}
+	for _, pod := range pods {
+		names = append(names, pod.Metadata.Namespace+"/"+pod.Metadata.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+// buildNodeDrain returns what draining the node evicts and what blocks it: pdbs with 0 disruptions allowed
+// and pods covered by more than one pdb, the eviction api refuses them
+func buildNodeDrain(node Node, pods []Pod, pdbList []Pdb) NodeDrain {
+	drain := NodeDrain{Node: node, PdbEvictions: make(map[string]int)}
+	blockers := make(map[string]bool)
+	for _, pod := range pods {
+		if isNodeBoundPod(pod) {
+			continue
+		}
+		drain.Evicted = append(drain.Evicted, pod)
+		if pod.GetWorkloadKind() == "Pod" {
+			drain.Unmanaged = append(drain.Unmanaged, pod)
+		}
+		if pod.HasLocalStorage() {
+			drain.LocalStorage = append(drain.LocalStorage, pod)
+		}
+		matched := []string{}
+		for _, pdb := range pdbList {
+			if pdb.Metadata.Namespace == pod.Metadata.Namespace && pdb.match(pod.Metadata.Labels) {
+				matched = append(matched, pdb.GetKey())
+				drain.PdbEvictions[pdb.GetKey()]++
+				if pdb.Status.DisruptionsAllowed == 0 {
+					blockers[fmt.Sprintf("pdb %s allows 0 disruptions", pdb.GetKey())] = true
+				}
+			}
+		}
+		if len(matched) > 1 {
+			blockers[fmt.Sprintf("pod %s/%s is covered by pdbs %s", pod.Metadata.Namespace, pod.Metadata.Name, strings.Join(matched, ","))] = true
+		}
+	}
+	for blocker := range blockers {
+		drain.Blockers = append(drain.Blockers, blocker)
+	}
+	sort.Strings(drain.Blockers)
+	return drain
+}
+
+// fitsWave returns true if the evictions of the wave plus the ones of the node stay within the disruptions allowed by each pdb.
+// A node that needs more evictions than allowed is drained alone for that pdb, kubectl drain retries the evictions as the
+// replacement pods become ready
+func fitsWave(drain NodeDrain, waveEvictions map[string]int, pdbList []Pdb) bool {
+	for _, pdb := range pdbList {
+		count := drain.PdbEvictions[pdb.GetKey()]
+		if count > 0 && waveEvictions[pdb.GetKey()] > 0 && waveEvictions[pdb.GetKey()]+count > pdb.Status.DisruptionsAllowed {
+			return false
+		}
+	}
+	return true
+}
+
+// BuildDrainPlan groups the nodes in waves drained in parallel. A node joins the current wave when the pdbs allow the
+// extra evictions and its pods fit on the nodes that are not being drained, nodes are tried by node pool and name.
+// Between two waves the evicted pods are rescheduled and become ready, so the pdb budgets are restored, and the drained
+// nodes come back empty (upgraded) and schedulable. Blocked nodes and nodes whose pods don't fit anywhere get wave 0.
+// The result is sorted by wave, node pool and node name, blocked nodes last
+func BuildDrainPlan(nodeList []Node, pdbList []Pdb) []NodeDrain {
+	state := []*schedulingNode{}
+	for _, node := range nodeList {
+		state = append(state, &schedulingNode{node: node, pods: append([]Pod{}, node.Pods...)})
+	}
+	sort.SliceStable(state, func(i, j int) bool {
+		pi, pj := state[i].node.GetNodepool(), state[j].node.GetNodepool()
+		if pi != pj {
+			return pi < pj
+		}
+		return state[i].node.GetName() < state[j].node.GetName()
+	})
+
+	plan := []NodeDrain{}
+	remaining := []*schedulingNode{}
+	for _, s := range state {
+		drain := buildNodeDrain(s.node, s.pods, pdbList)
+		if drain.IsBlocked() {
+			plan = append(plan, drain)
+		} else {
+			remaining = append(remaining, s)
+		}
+	}
+
+	for wave := 1; len(remaining) > 0; wave++ {
+		waveEvictions := make(map[string]int)
+		waveNodes := []*schedulingNode{}
+		next := []*schedulingNode{}
+		// the nodes of a wave are cordoned together, a node that received pods can't join the wave
+		targets := make(map[string]bool)
+		for _, s := range remaining {
+			drain := buildNodeDrain(s.node, s.pods, pdbList)
+			if targets[s.node.GetName()] || !fitsWave(drain, waveEvictions, pdbList) {
+				next = append(next, s)
+				continue
+			}
+			moves, reason := placePods(s, drain.Evicted, state)
+			if reason != "" {
+				if len(waveNodes) == 0 {
+					// the node doesn't fit even when drained alone
+					drain.Blockers = []string{reason}
+					plan = append(plan, drain)
+				} else {
+					next = append(next, s)
+				}
+				continue
+			}
+			for _, move := range moves {
+				for _, target := range state {
+					if target.node.GetName() == move.Target {
+						target.pods = append(target.pods, move.Pod)
+					}
+				}
+				targets[move.Target] = true
+			}
+			for key, count := range drain.PdbEvictions {
+				waveEvictions[key] += count
+			}
+			drain.Wave = wave
+			plan = append(plan, drain)
+			s.removed = true
+			waveNodes = append(waveNodes, s)
+		}
+		// the drained nodes come back empty, only the node bound pods are kept
+		for _, s := range waveNodes {
+			pods := []Pod{}
+			for _, pod := range s.pods {
+				if isNodeBoundPod(pod) {
+					pods = append(pods, pod)
+				}
+			}
+			s.pods = pods
+			s.removed = false
+		}
+		remaining = next
+	}
+
+	sort.SliceStable(plan, func(i, j int) bool {
+		wi, wj := plan[i].Wave, plan[j].Wave
+		if wi != wj {
+			return wj == 0 || (wi != 0 && wi < wj)
+		}
+		pi, pj := plan[i].Node.GetNodepool(), plan[j].Node.GetNodepool()
+		if pi != pj {
+			return pi < pj
+		}
+		return plan[i].Node.GetName() < plan[j].Node.GetName()
+	})
+	return plan
+}
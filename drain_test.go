@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestBuildDrainPlan(t *testing.T) {
+	pods := buildPodList(`{"items": [
+		{"metadata": {"name": "api-7d9f8b6c5d-x1x1x", "namespace": "shop", "labels": {"app": "api"}, "ownerReferences": [{"kind": "ReplicaSet", "name": "api-7d9f8b6c5d"}]},
+		 "spec": {"nodeName": "node-1", "containers": [{"name": "c", "resources": {"requests": {"cpu": "500m", "memory": "512Mi"}}}]}},
+		{"metadata": {"name": "cache", "namespace": "shop"},
+		 "spec": {"nodeName": "node-1", "volumes": [{"name": "data", "emptyDir": {}}], "containers": [{"name": "c", "resources": {"requests": {"cpu": "500m", "memory": "512Mi"}}}]}},
+		{"metadata": {"name": "api-7d9f8b6c5d-x2x2x", "namespace": "shop", "labels": {"app": "api"}, "ownerReferences": [{"kind": "ReplicaSet", "name": "api-7d9f8b6c5d"}]},
+		 "spec": {"nodeName": "node-2", "containers": [{"name": "c", "resources": {"requests": {"cpu": "500m", "memory": "512Mi"}}}]}},
+		{"metadata": {"name": "web-5c8d7e6f5a-w1w1w", "namespace": "shop", "labels": {"app": "web"}, "ownerReferences": [{"kind": "ReplicaSet", "name": "web-5c8d7e6f5a"}]},
+		 "spec": {"nodeName": "node-3", "containers": [{"name": "c", "resources": {"requests": {"cpu": "2", "memory": "2Gi"}}}]}},
+		{"metadata": {"name": "fluentd-4", "namespace": "kube-system", "ownerReferences": [{"kind": "DaemonSet", "name": "fluentd"}]},
+		 "spec": {"nodeName": "node-4", "containers": [{"name": "c", "resources": {"requests": {"cpu": "100m", "memory": "128Mi"}}}]}}
+	]}`).Items
+	nodes := buildNodeList(`{"items": [
+		{"metadata": {"name": "node-1"}, "status": {"allocatable": {"cpu": "4", "memory": "8Gi", "pods": "110"}, "conditions": [{"type": "Ready", "status": "True"}]}},
+		{"metadata": {"name": "node-2"}, "status": {"allocatable": {"cpu": "4", "memory": "8Gi", "pods": "110"}, "conditions": [{"type": "Ready", "status": "True"}]}},
+		{"metadata": {"name": "node-3"}, "status": {"allocatable": {"cpu": "4", "memory": "8Gi", "pods": "110"}, "conditions": [{"type": "Ready", "status": "True"}]}},
+		{"metadata": {"name": "node-4"}, "status": {"allocatable": {"cpu": "4", "memory": "8Gi", "pods": "110"}, "conditions": [{"type": "Ready", "status": "True"}]}}
+	]}`).Items
+	for i, node := range nodes {
+		for _, pod := range pods {
+			if pod.Spec.NodeName == node.GetName() {
+				nodes[i].Pods = append(nodes[i].Pods, pod)
+			}
+		}
+	}
+	pdbs := buildPdbItems(`{"items": [
+		{"metadata": {"name": "api", "namespace": "shop"}, "spec": {"minAvailable": 1, "selector": {"matchLabels": {"app": "api"}}}, "status": {"disruptionsAllowed": 1}},
+		{"metadata": {"name": "web", "namespace": "shop"}, "spec": {"minAvailable": 1, "selector": {"matchLabels": {"app": "web"}}}, "status": {"disruptionsAllowed": 0}},
+		{"metadata": {"name": "queue", "namespace": "shop"}, "spec": {"minAvailable": 1, "selector": {"matchExpressions": [{"key": "app", "operator": "In", "values": ["queue"]}]}}, "status": {"disruptionsAllowed": 0}}
+	]}`).Items
+
+	// the queue pdb selects no pod, it must neither block the nodes nor count evictions
+	plan := BuildDrainPlan(nodes, pdbs)
+	expected := []string{"1 node-1 pods and local data lost", "1 node-4 ok", "2 node-2 ok", "- node-3 blocked"}
+	if len(plan) != len(expected) {
+		t.Fatalf("Test failed! found %d expected %d: %+v", len(plan), len(expected), plan)
+	}
+	for i, d := range plan {
+		if actual := d.GetWave() + " " + d.Node.GetName() + " " + d.GetStatus(); actual != expected[i] {
+			t.Fatalf("Test failed! found %s expected %s", actual, expected[i])
+		}
+	}
+	// the api pdb allows one eviction at a time, so node-1 and node-2 are drained in different waves
+	if n1 := plan[0]; len(n1.Evicted) != 2 || getPodNames(n1.Unmanaged) != "shop/cache" || getPodNames(n1.LocalStorage) != "shop/cache" || n1.GetPdbs(pdbs) != "shop/api (1/1)" || n1.PdbEvictions["shop/queue"] != 0 {
+		t.Fatalf("Test failed! %+v", n1)
+	}
+	if n4 := plan[1]; len(n4.Evicted) != 0 {
+		t.Fatalf("Test failed! %+v", n4)
+	}
+	if n3 := plan[3]; len(n3.Blockers) != 1 || n3.Blockers[0] != "pdb shop/web allows 0 disruptions" {
+		t.Fatalf("Test failed! %+v", n3)
+	}
+}
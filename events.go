@@ -0,0 +1,220 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// EventItems a list of events
+type EventItems struct {
+	Items []Event
+}
+
+// Event only the fields used by the image pull analysis are decoded
+type Event struct {
+	InvolvedObject struct {
+		Kind      string `json:"kind"`
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"involvedObject"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+	Count   int    `json:"count"`
+	Source  struct {
+		Host string `json:"host"`
+	} `json:"source"`
+}
+
+// RetrieveEvents executes kubectl get events command, only the pod events are retrieved
+func RetrieveEvents(ns string) []Event {
+	cmd := "kubectl get events --all-namespaces --field-selector involvedObject.kind=Pod -o json"
+	if ns != "" {
+		cmd = fmt.Sprintf("kubectl get events -n %s --field-selector involvedObject.kind=Pod -o json", ns)
+	}
+	out, err := exec.Command("bash", "-c", cmd).CombinedOutput()
+	if err != nil {
+		log.Fatalf("Failed to execute command: %s", cmd)
+	}
+	return buildEventItems(string(out)).Items
+}
+
+func buildEventItems(str string) (events EventItems) {
+	err := json.Unmarshal([]byte(str), &events)
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+	return events
+}
+
+// ImagePull a Pulled event of the kubelet, Cached when the image was already present on the node
+// Count is the number of times the event happened, Duration and SizeBytes are the ones of the last pull
+type ImagePull struct {
+	Image     string
+	Node      string
+	Cached    bool
+	Count     int
+	Duration  time.Duration
+	SizeBytes int64
+}
+
+// eg. Successfully pulled image "nginx:1.19" in 12.345s (12.345s including waiting). Image size: 68034432 bytes.
+var pulledPattern = regexp.MustCompile(`^Successfully pulled image "([^"]+)"(?: in ([0-9.a-zµ]+))?`)
+var imageSizePattern = regexp.MustCompile(`Image size: (\d+) bytes`)
+
+// eg. Container image "nginx:1.19" already present on machine
+var alreadyPresentPattern = regexp.MustCompile(`^Container image "([^"]+)" already present on machine`)
+
+// parseImagePull returns the pull of a Pulled event, false for the other events
+func parseImagePull(event Event) (ImagePull, bool) {
+	if event.Reason != "Pulled" {
+		return ImagePull{}, false
+	}
+	pull := ImagePull{Node: event.Source.Host, Count: event.Count}
+	if pull.Count == 0 {
+		pull.Count = 1
+	}
+	if m := alreadyPresentPattern.FindStringSubmatch(event.Message); m != nil {
+		pull.Image = m[1]
+		pull.Cached = true
+		return pull, true
+	}
+	m := pulledPattern.FindStringSubmatch(event.Message)
+	if m == nil {
+		return ImagePull{}, false
+	}
+	pull.Image = m[1]
+	pull.Duration, _ = time.ParseDuration(m[2])
+	if size := imageSizePattern.FindStringSubmatch(event.Message); size != nil {
+		pull.SizeBytes, _ = strconv.ParseInt(size[1], 10, 64)
+	}
+	return pull, true
+}
+
+// BuildImagePulls parses the Pulled events, the node comes from the pod when the event has no source host
+func BuildImagePulls(events []Event, podList []Pod) []ImagePull {
+	podNodes := make(map[string]string)
+	for _, pod := range podList {
+		podNodes[pod.GetPodKey()] = pod.Spec.NodeName
+	}
+	pulls := []ImagePull{}
+	for _, event := range events {
+		pull, ok := parseImagePull(event)
+		if !ok {
+			continue
+		}
+		if pull.Node == "" {
+			pull.Node = podNodes[event.InvolvedObject.Namespace+"|"+event.InvolvedObject.Name]
+		}
+		pulls = append(pulls, pull)
+	}
+	return pulls
+}
+
+// PullGroup image pulls grouped by image or by node
+type PullGroup struct {
+	Name  string
+	Pulls []ImagePull
+}
+
+// CountPulls returns the number of images downloaded
+func (g PullGroup) CountPulls() int {
+	count := 0
+	for _, p := range g.Pulls {
+		if !p.Cached {
+			count += p.Count
+		}
+	}
+	return count
+}
+
+// CountCacheHits returns the number of images already present on the node
+func (g PullGroup) CountCacheHits() int {
+	count := 0
+	for _, p := range g.Pulls {
+		if p.Cached {
+			count += p.Count
+		}
+	}
+	return count
+}
+
+// GetCacheHitRatio returns cache hits / (pulls + cache hits) * 100
+func (g PullGroup) GetCacheHitRatio() float32 {
+	return percentage(g.CountCacheHits(), g.CountPulls()+g.CountCacheHits())
+}
+
+// GetPullPercentile returns the percentile of the pull durations, pulls without duration are ignored
+// an aggregated event is weighted by its count, as in CountPulls and GetTotalPullTime
+func (g PullGroup) GetPullPercentile(p int) time.Duration {
+	durations := []int{}
+	for _, pull := range g.Pulls {
+		if !pull.Cached && pull.Duration > 0 {
+			for i := 0; i < pull.Count; i++ {
+				durations = append(durations, int(pull.Duration/time.Millisecond))
+			}
+		}
+	}
+	return time.Duration(percentile(durations, p)) * time.Millisecond
+}
+
+// GetTotalPullTime returns the sum of the pull durations
+func (g PullGroup) GetTotalPullTime() time.Duration {
+	total := time.Duration(0)
+	for _, p := range g.Pulls {
+		if !p.Cached {
+			total += p.Duration * time.Duration(p.Count)
+		}
+	}
+	return total
+}
+
+// GetPulledMiB returns the MiB downloaded, only the events with the image size are accounted
+func (g PullGroup) GetPulledMiB() int {
+	total := int64(0)
+	for _, p := range g.Pulls {
+		if !p.Cached {
+			total += p.SizeBytes * int64(p.Count)
+		}
+	}
+	return int(total / 1024 / 1024)
+}
+
+// buildPullGroups groups the pulls by key, the result is sorted by total pull time descending, then by name
+func buildPullGroups(pulls []ImagePull, key func(p ImagePull) string) []PullGroup {
+	groups := []PullGroup{}
+	index := make(map[string]int)
+	for _, pull := range pulls {
+		name := key(pull)
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, PullGroup{Name: name})
+		}
+		groups[i].Pulls = append(groups[i].Pulls, pull)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		ti, tj := groups[i].GetTotalPullTime(), groups[j].GetTotalPullTime()
+		if ti != tj {
+			return ti > tj
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
+
+// BuildImagePullGroups groups the pulls by image
+func BuildImagePullGroups(pulls []ImagePull) []PullGroup {
+	return buildPullGroups(pulls, func(p ImagePull) string { return p.Image })
+}
+
+// BuildNodePullGroups groups the pulls by node
+func BuildNodePullGroups(pulls []ImagePull) []PullGroup {
+	return buildPullGroups(pulls, func(p ImagePull) string { return p.Node })
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestBuildImagePulls(t *testing.T) {
+	b, err := ioutil.ReadFile("test-data/events.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pods := buildPodList(`{"items": [{"metadata": {"name": "web-5c8d7e6f5a-w1w1w", "namespace": "shop"}, "spec": {"nodeName": "node-b"}}]}`).Items
+	pulls := BuildImagePulls(buildEventItems(string(b)).Items, pods)
+	if len(pulls) != 4 {
+		t.Fatalf("Test failed! found %d pulls: %+v", len(pulls), pulls)
+	}
+	if p := pulls[1]; p.Image != "gcr.io/acme/api:1.0" || p.Node != "node-b" || p.Cached || p.Duration != 64500*time.Millisecond || p.SizeBytes != 268435456 {
+		t.Fatalf("Test failed! %+v", p)
+	}
+	// the node comes from the pod when the event has no source host
+	if p := pulls[3]; p.Image != "nginx:1.19" || p.Node != "node-b" || p.Duration != 850*time.Millisecond || p.SizeBytes != 0 {
+		t.Fatalf("Test failed! %+v", p)
+	}
+
+	images := BuildImagePullGroups(pulls)
+	api := images[0]
+	if api.Name != "gcr.io/acme/api:1.0" || api.CountPulls() != 2 || api.CountCacheHits() != 3 || fmt.Sprintf("%.2f", api.GetCacheHitRatio()) != "60.00" || api.GetPullPercentile(50) != 12300*time.Millisecond ||
+		api.GetPullPercentile(100) != 64500*time.Millisecond || api.GetTotalPullTime() != 76800*time.Millisecond || api.GetPulledMiB() != 512 {
+		t.Fatalf("Test failed! %+v", api)
+	}
+
+	nodes := BuildNodePullGroups(pulls)
+	if len(nodes) != 2 || nodes[0].Name != "node-b" || nodes[0].CountPulls() != 2 || nodes[0].GetCacheHitRatio() != 0 || nodes[1].Name != "node-a" || nodes[1].GetCacheHitRatio() != 75 {
+		t.Fatalf("Test failed! %+v", nodes)
+	}
+}
+
+func TestPullGroupAggregatedEvents(t *testing.T) {
+	// the 4 pulls of the aggregated event outweigh the single fast pull
+	g := PullGroup{Name: "acme/api:1.0", Pulls: []ImagePull{
+		{Duration: 10 * time.Second, Count: 1},
+		{Duration: 60 * time.Second, Count: 4},
+		{Count: 2, Cached: true},
+	}}
+	if g.CountPulls() != 5 || g.GetTotalPullTime() != 250*time.Second || g.GetPullPercentile(50) != 60*time.Second || g.GetPullPercentile(0) != 10*time.Second {
+		t.Fatalf("Test failed! %d %s %s %s", g.CountPulls(), g.GetTotalPullTime(), g.GetPullPercentile(50), g.GetPullPercentile(0))
+	}
+}
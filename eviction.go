@@ -0,0 +1,82 @@
+package main
+
+import (
+	"sort"
+)
+
+// NodeEvictionRisk pods of a node ranked by the likelihood of being evicted under node memory pressure
+type NodeEvictionRisk struct {
+	Node Node
+	Pods []Pod
+}
+
+// GetMemoryAboveRequests returns top - requests memory, negative if the pod uses less than requested
+func (p Pod) GetMemoryAboveRequests() int {
+	return p.GetTopMiMemory() - p.GetRequestsMiMemory()
+}
+
+// GetTopMiMemory actual node memory usage, falls back to the sum of pods usage if node metrics are not available
+func (r NodeEvictionRisk) GetTopMiMemory() int {
+	if top := r.Node.GetTopMiMemory(); top > 0 {
+		return top
+	}
+	w := Wrapper{Pods: r.Pods}
+	return w.GetTopMiMemory()
+}
+
+// GetUsageMemory % of node allocatable memory in use
+func (r NodeEvictionRisk) GetUsageMemory() float32 {
+	return percentage(r.GetTopMiMemory(), r.Node.GetAllocatableMiMemory())
+}
+
+// CountPodsAboveRequests number of pods using more memory than requested, they are the first ones to be evicted
+func (r NodeEvictionRisk) CountPodsAboveRequests() int {
+	count := 0
+	for _, p := range r.Pods {
+		if p.GetMemoryAboveRequests() > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// GetMemoryAboveRequests sum of memory used above requests
+func (r NodeEvictionRisk) GetMemoryAboveRequests() int {
+	total := 0
+	for _, p := range r.Pods {
+		if above := p.GetMemoryAboveRequests(); above > 0 {
+			total += above
+		}
+	}
+	return total
+}
+
+// rankForEviction sorts pods the same way the kubelet does under memory pressure:
+// pods using more memory than requested first, then lower priority first, then bigger usage above requests first.
+// BestEffort pods have no requests, so any usage is above requests
+func rankForEviction(pods []Pod) []Pod {
+	ranked := append([]Pod{}, pods...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		ai, aj := ranked[i].GetMemoryAboveRequests(), ranked[j].GetMemoryAboveRequests()
+		if (ai > 0) != (aj > 0) {
+			return ai > 0
+		}
+		if pi, pj := ranked[i].GetPriority(), ranked[j].GetPriority(); pi != pj {
+			return pi < pj
+		}
+		return ai > aj
+	})
+	return ranked
+}
+
+// BuildEvictionRisks ranks the pods of each node, nodes are sorted by memory usage descending
+func BuildEvictionRisks(nodeList []Node) []NodeEvictionRisk {
+	risks := []NodeEvictionRisk{}
+	for _, node := range nodeList {
+		risks = append(risks, NodeEvictionRisk{Node: node, Pods: rankForEviction(node.Pods)})
+	}
+	sort.SliceStable(risks, func(i, j int) bool {
+		return risks[i].GetUsageMemory() > risks[j].GetUsageMemory()
+	})
+	return risks
+}
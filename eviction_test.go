@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestRankForEviction(t *testing.T) {
+	pods := buildPodList(`{"items": [
+		{"metadata": {"name": "guaranteed"}, "spec": {"containers": [{"name": "c", "resources": {"requests": {"cpu": "1", "memory": "1Gi"}, "limits": {"cpu": "1", "memory": "1Gi"}}}]}},
+		{"metadata": {"name": "burstable-above-critical"}, "spec": {"priority": 1000000, "priorityClassName": "critical", "containers": [{"name": "c", "resources": {"requests": {"memory": "100Mi"}}}]}},
+		{"metadata": {"name": "burstable-below"}, "spec": {"containers": [{"name": "c", "resources": {"requests": {"memory": "512Mi"}}}]}},
+		{"metadata": {"name": "burstable-above"}, "spec": {"containers": [{"name": "c", "resources": {"requests": {"memory": "100Mi"}}}]}},
+		{"metadata": {"name": "best-effort"}, "spec": {"containers": [{"name": "c"}]}}
+	]}`).Items
+	tops := []string{"900Mi", "400Mi", "200Mi", "150Mi", "80Mi"}
+	for i := range pods {
+		pods[i].Top = Top{Containers: []Container{{Name: "c", CPU: "1m", Memory: tops[i]}}}
+	}
+
+	ranked := rankForEviction(pods)
+	expected := []string{"best-effort", "burstable-above", "burstable-above-critical", "guaranteed", "burstable-below"}
+	for i, pod := range ranked {
+		if pod.Metadata.Name != expected[i] {
+			t.Fatalf("Test failed! %s but expected %s at rank %d", pod.Metadata.Name, expected[i], i+1)
+		}
+	}
+	if ranked[0].GetQosClass() != "BestEffort" || ranked[1].GetQosClass() != "Burstable" || ranked[3].GetQosClass() != "Guaranteed" {
+		t.Fatalf("Test failed! %s %s %s", ranked[0].GetQosClass(), ranked[1].GetQosClass(), ranked[3].GetQosClass())
+	}
+	if ranked[2].GetPriority() != 1000000 || ranked[2].Spec.PriorityClassName != "critical" {
+		t.Fatalf("Test failed! %d %s", ranked[2].GetPriority(), ranked[2].Spec.PriorityClassName)
+	}
+
+	node := Node{Pods: pods}
+	node.Status.Allocatable.Memory = "2Gi"
+	risk := BuildEvictionRisks([]Node{node})[0]
+	if risk.CountPodsAboveRequests() != 3 || risk.GetMemoryAboveRequests() != 80+50+300 || risk.GetTopMiMemory() != 1730 {
+		t.Fatalf("Test failed! %d %d %d", risk.CountPodsAboveRequests(), risk.GetMemoryAboveRequests(), risk.GetTopMiMemory())
+	}
+}
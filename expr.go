@@ -0,0 +1,518 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Expressions of the rules file, eg. workload.replicas > 3 && !workload.hasPdb
+// Values are numbers (float64), strings and booleans. Supported:
+//   literals     1, 2.5, "text", 'text', true, false
+//   variables    workload.requests.cpu, workload.labels.team, workload.labels["cost-center"]
+//   operators    ! - * / % + - < <= > >= == != && || and parentheses
+//   functions    contains(s, sub), startsWith(s, prefix), endsWith(s, suffix), matches(s, regex)
+
+// errDivisionByZero the only evaluation error that depends on the values, rules silently skip it
+var errDivisionByZero = errors.New("division by zero")
+
+// exprNode a node of the parsed expression
+type exprNode interface {
+	eval(env map[string]interface{}) (interface{}, error)
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+type variableNode struct {
+	path []string
+}
+
+type unaryNode struct {
+	op      string
+	operand exprNode
+}
+
+type binaryNode struct {
+	op    string
+	left  exprNode
+	right exprNode
+}
+
+type callNode struct {
+	name string
+	args []exprNode
+}
+
+// exprFunctions the functions available in the expressions, all of them take and return strings or booleans
+var exprFunctions = map[string]func(args []string) (interface{}, error){
+	"contains":   func(args []string) (interface{}, error) { return strings.Contains(args[0], args[1]), nil },
+	"startsWith": func(args []string) (interface{}, error) { return strings.HasPrefix(args[0], args[1]), nil },
+	"endsWith":   func(args []string) (interface{}, error) { return strings.HasSuffix(args[0], args[1]), nil },
+	"matches": func(args []string) (interface{}, error) {
+		re, err := regexp.Compile(args[1])
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString(args[0]), nil
+	},
+}
+
+func (n literalNode) eval(env map[string]interface{}) (interface{}, error) {
+	return n.value, nil
+}
+
+// eval walks the nested maps of the environment, a missing key of a map of labels or annotations is ""
+func (n variableNode) eval(env map[string]interface{}) (interface{}, error) {
+	var value interface{} = env
+	for i, key := range n.path {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s is not an object", strings.Join(n.path[:i], "."))
+		}
+		value, ok = m[key]
+		if !ok {
+			if i > 0 && (n.path[i-1] == "labels" || n.path[i-1] == "annotations") {
+				return "", nil
+			}
+			return nil, fmt.Errorf("unknown variable %s", strings.Join(n.path[:i+1], "."))
+		}
+	}
+	return value, nil
+}
+
+// Types of the expression values
+const (
+	exprNumber = "number"
+	exprString = "string"
+	exprBool   = "boolean"
+)
+
+// valueType returns the type of an expression value, an error for the objects
+func valueType(value interface{}) (string, error) {
+	switch value.(type) {
+	case float64:
+		return exprNumber, nil
+	case string:
+		return exprString, nil
+	case bool:
+		return exprBool, nil
+	}
+	return "", fmt.Errorf("%v is not a number, a string or a boolean", value)
+}
+
+// exprType infers the type of the expression without evaluating it, the types of the variables come from the schema.
+// Both operands of the && and || operators are checked, and == and != only compare values of the same type
+func exprType(node exprNode, schema map[string]interface{}) (string, error) {
+	switch n := node.(type) {
+	case literalNode:
+		return valueType(n.value)
+	case variableNode:
+		value, err := n.eval(schema)
+		if err != nil {
+			return "", err
+		}
+		if _, ok := value.(map[string]interface{}); ok {
+			return "", fmt.Errorf("%s is an object, not a value", strings.Join(n.path, "."))
+		}
+		return valueType(value)
+	case unaryNode:
+		t, err := exprType(n.operand, schema)
+		if err != nil {
+			return "", err
+		}
+		if n.op == "!" && t != exprBool {
+			return "", fmt.Errorf("! expects a boolean, found a %s", t)
+		}
+		if n.op == "-" && t != exprNumber {
+			return "", fmt.Errorf("- expects a number, found a %s", t)
+		}
+		return t, nil
+	case binaryNode:
+		left, err := exprType(n.left, schema)
+		if err != nil {
+			return "", err
+		}
+		right, err := exprType(n.right, schema)
+		if err != nil {
+			return "", err
+		}
+		switch n.op {
+		case "&&", "||":
+			if left != exprBool || right != exprBool {
+				return "", fmt.Errorf("%s expects booleans, found a %s and a %s", n.op, left, right)
+			}
+			return exprBool, nil
+		case "==", "!=", "<", "<=", ">", ">=":
+			if left != right || (left == exprBool && n.op != "==" && n.op != "!=") {
+				return "", fmt.Errorf("%s can't compare a %s with a %s", n.op, left, right)
+			}
+			return exprBool, nil
+		case "+":
+			if left != right || left == exprBool {
+				return "", fmt.Errorf("+ expects numbers or strings, found a %s and a %s", left, right)
+			}
+			return left, nil
+		default:
+			if left != exprNumber || right != exprNumber {
+				return "", fmt.Errorf("%s expects numbers, found a %s and a %s", n.op, left, right)
+			}
+			return exprNumber, nil
+		}
+	case callNode:
+		for _, arg := range n.args {
+			t, err := exprType(arg, schema)
+			if err != nil {
+				return "", err
+			}
+			if t != exprString {
+				return "", fmt.Errorf("%s expects strings, found a %s", n.name, t)
+			}
+		}
+		if pattern, ok := n.args[1].(literalNode); ok && n.name == "matches" {
+			if _, err := regexp.Compile(pattern.value.(string)); err != nil {
+				return "", err
+			}
+		}
+		return exprBool, nil
+	}
+	return "", fmt.Errorf("unknown expression %v", node)
+}
+
+func (n unaryNode) eval(env map[string]interface{}) (interface{}, error) {
+	value, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "!":
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("! expects a boolean, found %v", value)
+		}
+		return !b, nil
+	default:
+		f, ok := value.(float64)
+		if !ok {
+			return nil, fmt.Errorf("- expects a number, found %v", value)
+		}
+		return -f, nil
+	}
+}
+
+func (n binaryNode) eval(env map[string]interface{}) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	// short-circuit
+	if n.op == "&&" || n.op == "||" {
+		l, ok := left.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s expects booleans, found %v", n.op, left)
+		}
+		if (n.op == "&&" && !l) || (n.op == "||" && l) {
+			return l, nil
+		}
+		right, err := n.right.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		r, ok := right.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s expects booleans, found %v", n.op, right)
+		}
+		return r, nil
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return left == right, nil
+	case "!=":
+		return left != right, nil
+	}
+	if ls, ok := left.(string); ok {
+		rs, ok := right.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s can't compare %q with %v", n.op, ls, right)
+		}
+		switch n.op {
+		case "+":
+			return ls + rs, nil
+		case "<":
+			return ls < rs, nil
+		case "<=":
+			return ls <= rs, nil
+		case ">":
+			return ls > rs, nil
+		case ">=":
+			return ls >= rs, nil
+		}
+		return nil, fmt.Errorf("%s expects numbers, found %q", n.op, ls)
+	}
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+	if !lok || !rok {
+		return nil, fmt.Errorf("%s expects numbers, found %v and %v", n.op, left, right)
+	}
+	switch n.op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/", "%":
+		if r == 0 {
+			return nil, errDivisionByZero
+		}
+		if n.op == "%" {
+			return math.Mod(l, r), nil
+		}
+		return l / r, nil
+	case "<":
+		return l < r, nil
+	case "<=":
+		return l <= r, nil
+	case ">":
+		return l > r, nil
+	default:
+		return l >= r, nil
+	}
+}
+
+func (n callNode) eval(env map[string]interface{}) (interface{}, error) {
+	args := []string{}
+	for _, arg := range n.args {
+		value, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s expects strings, found %v", n.name, value)
+		}
+		args = append(args, s)
+	}
+	return exprFunctions[n.name](args)
+}
+
+// exprToken kind is number, string, ident or op
+type exprToken struct {
+	kind  string
+	value string
+}
+
+// tokenize splits the expression, identifiers keep their dots, eg. workload.requests.cpu
+func tokenize(str string) ([]exprToken, error) {
+	tokens := []exprToken{}
+	for i := 0; i < len(str); {
+		c := str[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(str) && (str[j] >= '0' && str[j] <= '9' || str[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, exprToken{"number", str[i:j]})
+			i = j
+		case c == '"' || c == '\'':
+			j := strings.IndexByte(str[i+1:], c)
+			if j == -1 {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			tokens = append(tokens, exprToken{"string", str[i+1 : i+1+j]})
+			i += j + 2
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			j := i
+			for j < len(str) && (str[j] == '_' || str[j] == '.' || str[j] >= 'a' && str[j] <= 'z' || str[j] >= 'A' && str[j] <= 'Z' || str[j] >= '0' && str[j] <= '9') {
+				j++
+			}
+			tokens = append(tokens, exprToken{"ident", str[i:j]})
+			i = j
+		default:
+			op := ""
+			for _, candidate := range []string{"&&", "||", "==", "!=", "<=", ">=", "!", "<", ">", "+", "-", "*", "/", "%", "(", ")", "[", "]", ","} {
+				if strings.HasPrefix(str[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q at %d", c, i)
+			}
+			tokens = append(tokens, exprToken{"op", op})
+			i += len(op)
+		}
+	}
+	return tokens, nil
+}
+
+// exprParser recursive descent parser, from the lowest to the highest precedence: || && comparison + - * / % unary
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+}
+
+// ParseExpression parses the expression of a rule
+func ParseExpression(str string) (exprNode, error) {
+	tokens, err := tokenize(str)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	node, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos].value)
+	}
+	return node, nil
+}
+
+// exprPrecedence binary operators by precedence level
+var exprPrecedence = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!=", "<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *exprParser) peek(values ...string) string {
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind == "op" {
+		for _, v := range values {
+			if p.tokens[p.pos].value == v {
+				return v
+			}
+		}
+	}
+	return ""
+}
+
+func (p *exprParser) expect(value string) error {
+	if p.peek(value) == "" {
+		return fmt.Errorf("expected %q", value)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *exprParser) parseBinary(level int) (exprNode, error) {
+	if level == len(exprPrecedence) {
+		return p.parseUnary()
+	}
+	left, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek(exprPrecedence[level]...)
+		if op == "" {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if op := p.peek("!", "-"); op != "" {
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unaryNode{op: op, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	switch t.kind {
+	case "number":
+		f, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s", t.value)
+		}
+		return literalNode{f}, nil
+	case "string":
+		return literalNode{t.value}, nil
+	case "ident":
+		switch t.value {
+		case "true":
+			return literalNode{true}, nil
+		case "false":
+			return literalNode{false}, nil
+		}
+		if p.peek("(") != "" {
+			return p.parseCall(t.value)
+		}
+		node := variableNode{path: strings.Split(t.value, ".")}
+		for p.peek("[") != "" {
+			p.pos++
+			if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != "string" {
+				return nil, fmt.Errorf("expected a string key after [")
+			}
+			node.path = append(node.path, p.tokens[p.pos].value)
+			p.pos++
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+		}
+		return node, nil
+	default:
+		if t.value == "(" {
+			node, err := p.parseBinary(0)
+			if err != nil {
+				return nil, err
+			}
+			return node, p.expect(")")
+		}
+		return nil, fmt.Errorf("unexpected %q", t.value)
+	}
+}
+
+func (p *exprParser) parseCall(name string) (exprNode, error) {
+	if _, ok := exprFunctions[name]; !ok {
+		return nil, fmt.Errorf("unknown function %s", name)
+	}
+	p.pos++
+	call := callNode{name: name}
+	for p.peek(")") == "" {
+		if len(call.args) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := p.parseBinary(0)
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, arg)
+	}
+	p.pos++
+	if len(call.args) != 2 {
+		return nil, fmt.Errorf("%s expects 2 arguments", name)
+	}
+	return call, nil
+}
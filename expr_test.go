@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseExpression(t *testing.T) {
+	env := map[string]interface{}{
+		"workload": map[string]interface{}{
+			"name":     "api",
+			"replicas": float64(4),
+			"hasPdb":   false,
+			"labels":   map[string]interface{}{"team": "shop", "cost-center": "cc-100"},
+			"requests": map[string]interface{}{"memory": float64(0)},
+		},
+	}
+	expressions := map[string]interface{}{
+		"workload.replicas > 3 && !workload.hasPdb":                       true,
+		"workload.replicas > 3 && workload.hasPdb":                        false,
+		"1 + 2 * 3 - 4 / 2":                                               float64(5),
+		"(1 + 2) * 3 % 4":                                                 float64(1),
+		"workload.replicas % 0.5":                                         float64(0),
+		"5.5 % 2":                                                         float64(1.5),
+		"-workload.replicas < 0 || workload.missing":                      true,
+		"workload.labels.team == 'shop'":                                  true,
+		`workload.labels["cost-center"] != ""`:                            true,
+		"workload.labels.owner == ''":                                     true,
+		`contains(workload.name, "p") && matches(workload.name, "^a.i$")`: true,
+		`workload.name + "-v2" == "api-v2"`:                               true,
+	}
+	for str, expected := range expressions {
+		expr, err := ParseExpression(str)
+		if err != nil {
+			t.Fatalf("Test failed! %s: %s", str, err)
+		}
+		value, err := expr.eval(env)
+		if err != nil || value != expected {
+			t.Fatalf("Test failed! %s found %v (%v) expected %v", str, value, err, expected)
+		}
+	}
+
+	invalid := []string{"workload.replicas >", "(1 + 2", "unknown(1, 2)", "1 $ 2", "'open"}
+	for _, str := range invalid {
+		if _, err := ParseExpression(str); err == nil {
+			t.Fatalf("Test failed! %s must be invalid", str)
+		}
+	}
+
+	errors := []string{"workload.missing", "1 / workload.requests.memory", "workload.name > 1", "!workload.name"}
+	for _, str := range errors {
+		expr, err := ParseExpression(str)
+		if err != nil {
+			t.Fatalf("Test failed! %s: %s", str, err)
+		}
+		if _, err := expr.eval(env); err == nil {
+			t.Fatalf("Test failed! %s must fail", str)
+		}
+	}
+}
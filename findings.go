@@ -0,0 +1,34 @@
+package main
+
+import (
+	"sort"
+)
+
+// Finding severities
+const (
+	SeverityInfo    = "info"
+	SeverityWarning = "warning"
+	SeverityError   = "error"
+)
+
+// Finding a problem detected in the snapshot
+type Finding struct {
+	ID        string `json:"id"`
+	Severity  string `json:"severity"`
+	Namespace string `json:"namespace"`
+	Object    string `json:"object"`
+	Message   string `json:"message"`
+}
+
+// sortFindings sorts by namespace, object and id
+func sortFindings(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Namespace != findings[j].Namespace {
+			return findings[i].Namespace < findings[j].Namespace
+		}
+		if findings[i].Object != findings[j].Object {
+			return findings[i].Object < findings[j].Object
+		}
+		return findings[i].ID < findings[j].ID
+	})
+}
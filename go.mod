@@ -2,4 +2,7 @@ module github.com/fbrubbo/kubectl-snapshot
 
 go 1.13
 
-require github.com/sirupsen/logrus v1.4.2
+require (
+	github.com/sirupsen/logrus v1.4.2
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// gpuResourcePrefixes device plugin resources considered gpus (nvidia.com/gpu, amd.com/gpu, nvidia.com/mig-1g.5gb, gpu.intel.com/i915, ..)
+var gpuResourcePrefixes = []string{"nvidia.com/gpu", "nvidia.com/mig-", "amd.com/gpu", "gpu.intel.com/"}
+
+// isGpuResource ..
+func isGpuResource(name string) bool {
+	for _, prefix := range gpuResourcePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// extendedResources returns all resources but cpu, memory, ephemeral-storage and pods
+// attachable-volumes-* are ignored, they are only reported by nodes and never requested by pods
+func extendedResources(values map[string]string) map[string]string {
+	var extended map[string]string
+	for name, value := range values {
+		switch {
+		case name == "cpu" || name == "memory" || name == "ephemeral-storage" || name == "pods":
+		case strings.HasPrefix(name, "attachable-volumes-"):
+		default:
+			if extended == nil {
+				extended = make(map[string]string)
+			}
+			extended[name] = value
+		}
+	}
+	return extended
+}
+
+// formatExtendedResources returns eg. "hugepages-2Mi=256Mi,nvidia.com/gpu=1"
+func formatExtendedResources(values map[string]int) string {
+	names := []string{}
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := []string{}
+	for _, name := range names {
+		if strings.HasPrefix(name, "hugepages-") {
+			parts = append(parts, fmt.Sprintf("%s=%dMi", name, values[name]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s=%d", name, values[name]))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// sortedNames ..
+func sortedNames(m map[string]string) []string {
+	names := []string{}
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetRequestsGpus total of all pods
+func (w Workload) GetRequestsGpus() int {
+	total := 0
+	for _, pod := range w.Pods {
+		total += pod.GetRequestsGpus()
+	}
+	return total
+}
+
+// GetExtendedResources returns the extended resources of a single pod, eg. "nvidia.com/gpu=1"
+func (w Workload) GetExtendedResources() string {
+	if len(w.Pods) > 0 {
+		return w.Pods[0].GetExtendedResources()
+	}
+	return ""
+}
+
+// GetNodeNames returns the sorted distinct nodes running the pods
+func (w Workload) GetNodeNames() []string {
+	nodeMap := make(map[string]string)
+	for _, pod := range w.Pods {
+		nodeMap[pod.Spec.NodeName] = pod.Spec.NodeName
+	}
+	return sortedNames(nodeMap)
+}
+
+// GetIdleGpus returns allocatable - requested
+func (n Node) GetIdleGpus() int {
+	return n.GetAllocatableGpus() - n.GetRequestsGpus()
+}
+
+// BuildGpuNodes returns the nodes providing gpus
+// the result is sorted by idle gpus descending, then by name
+func BuildGpuNodes(nodeList []Node) []Node {
+	nodes := []Node{}
+	for _, node := range nodeList {
+		if node.GetAllocatableGpus() > 0 {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		ii, ij := nodes[i].GetIdleGpus(), nodes[j].GetIdleGpus()
+		if ii != ij {
+			return ii > ij
+		}
+		return nodes[i].GetName() < nodes[j].GetName()
+	})
+	return nodes
+}
+
+// BuildGpuWorkloads returns the workloads holding gpus
+// the result is sorted by cpu usage ascending, the workloads on the top probably keep their gpus idle
+func BuildGpuWorkloads(workloadList []Workload) []Workload {
+	workloads := []Workload{}
+	for _, workload := range workloadList {
+		if workload.GetRequestsGpus() > 0 {
+			workloads = append(workloads, workload)
+		}
+	}
+	sort.SliceStable(workloads, func(i, j int) bool {
+		ui := Wrapper{Pods: workloads[i].Pods}.GetUsageCPU()
+		uj := Wrapper{Pods: workloads[j].Pods}.GetUsageCPU()
+		if ui != uj {
+			return ui < uj
+		}
+		return workloads[i].GetWorkloadKey() < workloads[j].GetWorkloadKey()
+	})
+	return workloads
+}
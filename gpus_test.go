@@ -0,0 +1,73 @@
+package main
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func loadGpuNodes(t *testing.T) ([]Pod, []Node) {
+	b, err := ioutil.ReadFile("test-data/gpu-pods.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pods := buildPodList(string(b)).Items
+
+	b, err = ioutil.ReadFile("test-data/node-gpu.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := buildNodeList(string(b)).Items
+	for i, node := range nodes {
+		for _, pod := range pods {
+			if pod.Spec.NodeName == node.GetName() {
+				nodes[i].Pods = append(nodes[i].Pods, pod)
+			}
+		}
+	}
+	return pods, nodes
+}
+
+func TestExtendedResources(t *testing.T) {
+	pods, nodes := loadGpuNodes(t)
+
+	trainer, inference, plugin := pods[0], pods[1], pods[2]
+	if trainer.GetRequestsGpus() != 1 || trainer.GetExtendedResources() != "nvidia.com/gpu=1" ||
+		trainer.GetRequestsMilliCPU() != 2000 || trainer.GetRequestsMiMemory() != 8192 {
+		t.Fatalf("Test failed! %+v", trainer)
+	}
+	// gpu only in the limits
+	if inference.GetRequestsGpus() != 2 || inference.GetExtendedResources() != "hugepages-2Mi=256Mi,nvidia.com/gpu=2" {
+		t.Fatalf("Test failed! %+v", inference)
+	}
+	if plugin.GetRequestsGpus() != 0 || plugin.GetExtendedResources() != "" {
+		t.Fatalf("Test failed! %+v", plugin)
+	}
+
+	node := nodes[0]
+	if node.GetAllocatableGpus() != 4 || node.GetRequestsGpus() != 3 || node.GetIdleGpus() != 1 ||
+		node.GetAllocatableMilliCPU() != 47810 || node.GetAllocatablePods() != 234 ||
+		node.GetExtendedResources() != "hugepages-2Mi=256Mi/1024Mi,nvidia.com/gpu=3/4" {
+		t.Fatalf("Test failed! %+v", node)
+	}
+	if _, ok := node.Status.Allocatable.Extended["attachable-volumes-aws-ebs"]; ok {
+		t.Fatalf("Test failed! %+v", node.Status.Allocatable.Extended)
+	}
+}
+
+func TestBuildGpuNodesAndWorkloads(t *testing.T) {
+	pods, nodes := loadGpuNodes(t)
+
+	gpuNodes := BuildGpuNodes(nodes)
+	if len(gpuNodes) != 2 ||
+		gpuNodes[0].GetName() != "ip-10-0-1-30.ec2.internal" || gpuNodes[0].GetIdleGpus() != 1 ||
+		gpuNodes[1].GetName() != "ip-10-0-2-20.ec2.internal" {
+		t.Fatalf("Test failed! %+v", gpuNodes)
+	}
+
+	workloads := BuildGpuWorkloads(BuildWorkloads(pods, []Pdb{}))
+	if len(workloads) != 2 ||
+		workloads[0].GetWorkloadKey() != "ml|Deployment/trainer" ||
+		workloads[1].GetWorkloadKey() != "ml|StatefulSet/inference" || workloads[1].GetRequestsGpus() != 2 {
+		t.Fatalf("Test failed! %+v", workloads)
+	}
+}
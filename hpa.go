@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"fmt"
 	"log"
 	"os/exec"
 	"regexp"
@@ -111,9 +112,55 @@ func (h Hpa) GetLifecyclePreStop() string {
 	return "N/A"
 }
 
+// GetContainersWithoutCPURequests returns the containers of the hpa pods without a cpu request,
+// a single one is enough for the hpa to report <unknown>
+func (h Hpa) GetContainersWithoutCPURequests() []string {
+	missing := []string{}
+	if len(h.Pods) > 0 {
+		for _, c := range h.Pods[0].Spec.Containers {
+			if c.Resources.Requests.CPU == "" {
+				missing = append(missing, c.Name)
+			}
+		}
+	}
+	return missing
+}
+
+// BuildHpaFindings flags hpas that can't do their job: pinned at max or at min with low usage, unknown metrics,
+// targets too high or too low and min == max
+func BuildHpaFindings(hpaList []Hpa) []Finding {
+	findings := []Finding{}
+	for _, h := range hpaList {
+		add := func(id string, severity string, message string) {
+			findings = append(findings, Finding{ID: id, Severity: severity, Namespace: h.Namespace, Object: "HorizontalPodAutoscaler/" + h.Name, Message: message})
+		}
+		if h.MinPods == h.MaxPods {
+			add("HPA-MIN-EQUALS-MAX", SeverityWarning, fmt.Sprintf("minReplicas and maxReplicas are both %d, the hpa never scales %s", h.MinPods, h.GetReference()))
+		} else if h.Replicas >= h.MaxPods {
+			add("HPA-AT-MAX", SeverityError, fmt.Sprintf("running at maxReplicas %d with cpu %s, %s is under-provisioned and can't scale further", h.MaxPods, h.GetUsageAndTarget(), h.GetReference()))
+		} else if h.Replicas == h.MinPods && h.MinPods > 1 && h.UsageCPU != -1 && h.UsageCPU < h.Target/2 {
+			add("HPA-AT-MIN-LOW-USAGE", SeverityInfo, fmt.Sprintf("running at minReplicas %d with cpu %s, less than half of the target, minReplicas is probably too high", h.MinPods, h.GetUsageAndTarget()))
+		}
+		if h.UsageCPU == -1 {
+			reason := "metrics-server is not reporting usage for its pods"
+			if missing := h.GetContainersWithoutCPURequests(); len(missing) > 0 {
+				reason = fmt.Sprintf("containers %s have no cpu requests", strings.Join(missing, ", "))
+			}
+			add("HPA-UNKNOWN-METRICS", SeverityError, fmt.Sprintf("current cpu is <unknown>, the hpa can't scale because %s", reason))
+		}
+		if h.Target > 90 {
+			add("HPA-TARGET-HIGH", SeverityWarning, fmt.Sprintf("target cpu %d%% leaves no headroom to absorb spikes while new pods start", h.Target))
+		} else if h.Target < 30 {
+			add("HPA-TARGET-LOW", SeverityWarning, fmt.Sprintf("target cpu %d%% keeps the pods mostly idle, most of the requested cpu is wasted", h.Target))
+		}
+	}
+	sortFindings(findings)
+	return findings
+}
+
 // RetrieveHpas executes kubectl get hpas command
 // if ns is empty, then all namespaces are used
-func RetrieveHpas(nsFilter string, podList []Pod) []Hpa {
+func RetrieveHpas(nsFilter string, podList []Pod, pdbList []Pdb) []Hpa {
 	cmd := "kubectl get hpa --all-namespaces --no-headers"
 	out, err := exec.Command("bash", "-c", cmd).CombinedOutput()
 	if err != nil {
@@ -122,13 +169,12 @@ func RetrieveHpas(nsFilter string, podList []Pod) []Hpa {
 	data := string(out)
 
 	hpas := buildHpaList(data, nsFilter, podList)
-	hpas = enrichHpaWithPdb(hpas)
+	hpas = enrichHpaWithPdb(hpas, pdbList)
 	return hpas
 }
 
-func enrichHpaWithPdb(hpas []Hpa) (ret []Hpa) {
+func enrichHpaWithPdb(hpas []Hpa, pdbs []Pdb) (ret []Hpa) {
 	//TODO: improve performance in this func
-	pdbs := RetrievePdbs()
 	for _, hpa := range hpas {
 		if len(hpa.Pods) > 0 {
 			for _, pdb := range pdbs {
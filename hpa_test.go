@@ -58,3 +58,48 @@ default        paymentservice                                          Deploymen
 		t.Fatalf("Test failed! hpa does not match data")
 	}
 }
+
+func TestBuildHpaFindings(t *testing.T) {
+	data := `shop           api        Deployment/api        95%/80%         2         10        10         3d
+shop           web        Deployment/web        10%/80%         4         20        4          3d
+shop           worker     Deployment/worker     <unknown>/80%   1         5         1          3d
+shop           fixed      Deployment/fixed      50%/95%         3         3         3          3d
+shop           idle       Deployment/idle       5%/20%          1         5         2          3d`
+	findings := BuildHpaFindings(buildHpaList(data, "", []Pod{}))
+	expected := []string{
+		"HorizontalPodAutoscaler/api HPA-AT-MAX error",
+		"HorizontalPodAutoscaler/fixed HPA-MIN-EQUALS-MAX warning",
+		"HorizontalPodAutoscaler/fixed HPA-TARGET-HIGH warning",
+		"HorizontalPodAutoscaler/idle HPA-TARGET-LOW warning",
+		"HorizontalPodAutoscaler/web HPA-AT-MIN-LOW-USAGE info",
+		"HorizontalPodAutoscaler/worker HPA-UNKNOWN-METRICS error",
+	}
+	if len(findings) != len(expected) {
+		t.Fatalf("Test failed! found %d expected %d: %+v", len(findings), len(expected), findings)
+	}
+	for i, f := range findings {
+		if actual := f.Object + " " + f.ID + " " + f.Severity; actual != expected[i] {
+			t.Fatalf("Test failed! found %s expected %s", actual, expected[i])
+		}
+	}
+	if findings[5].Message != "current cpu is <unknown>, the hpa can't scale because metrics-server is not reporting usage for its pods" {
+		t.Fatalf("Test failed! %s", findings[5].Message)
+	}
+}
+
+func TestBuildHpaFindingsMissingCPURequests(t *testing.T) {
+	app := ContainerSpec{Name: "app"}
+	app.Resources.Requests.CPU = "100m"
+	sidecar := ContainerSpec{Name: "istio-proxy"}
+	pod := Pod{}
+	pod.Spec.Containers = []ContainerSpec{app, sidecar}
+
+	hpa := Hpa{Namespace: "shop", Name: "worker", ReferenceKind: "Deployment", ReferenceName: "worker", UsageCPU: -1, Target: 80, MinPods: 1, MaxPods: 5, Replicas: 2, Pods: []Pod{pod}}
+	findings := BuildHpaFindings([]Hpa{hpa})
+	if len(findings) != 1 || findings[0].ID != "HPA-UNKNOWN-METRICS" {
+		t.Fatalf("Test failed! found %+v expected HPA-UNKNOWN-METRICS", findings)
+	}
+	if findings[0].Message != "current cpu is <unknown>, the hpa can't scale because containers istio-proxy have no cpu requests" {
+		t.Fatalf("Test failed! %s", findings[0].Message)
+	}
+}
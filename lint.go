@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// severityLevels order of the severities, used by -fail-on
+var severityLevels = []string{SeverityInfo, SeverityWarning, SeverityError}
+
+// severityLevel returns the position of the severity in severityLevels, -1 if unknown
+func severityLevel(severity string) int {
+	for i, s := range severityLevels {
+		if s == severity {
+			return i
+		}
+	}
+	return -1
+}
+
+// HasFindingsAbove returns true if at least one finding has the severity or a higher one
+func HasFindingsAbove(findings []Finding, severity string) bool {
+	level := severityLevel(severity)
+	for _, f := range findings {
+		if severityLevel(f.Severity) >= level {
+			return true
+		}
+	}
+	return false
+}
+
+// lintContext data shared by the rules
+type lintContext struct {
+	hpaTargets map[string]bool
+}
+
+func newLintContext(hpaList []Hpa) lintContext {
+	ctx := lintContext{hpaTargets: make(map[string]bool)}
+	for _, h := range hpaList {
+		ctx.hpaTargets[h.GetDeploymentKey()] = true
+	}
+	return ctx
+}
+
+// LintRule a best practice checked on each workload
+// check returns one message per violation
+type LintRule struct {
+	ID          string
+	Severity    string
+	Description string
+	check       func(w Workload, ctx lintContext) []string
+}
+
+// isServing returns true for workloads that run long lived pods behind a service, jobs and static pods are excluded
+func (w Workload) isServing() bool {
+	return w.Kind == "Deployment" || w.Kind == "StatefulSet" || w.Kind == "DaemonSet" || w.Kind == "ReplicaSet"
+}
+
+// forEachContainer returns the message of each container of the first pod that fails the test
+func forEachContainer(w Workload, test func(c ContainerSpec) string) []string {
+	messages := []string{}
+	if len(w.Pods) == 0 {
+		return messages
+	}
+	for _, c := range w.Pods[0].Spec.Containers {
+		if message := test(c); message != "" {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+// getImageTag returns the tag of the image, latest when it has no tag and "" when it is pinned by digest
+func getImageTag(image string) string {
+	if strings.Contains(image, "@") {
+		return ""
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	if i := strings.LastIndex(name, ":"); i != -1 {
+		return name[i+1:]
+	}
+	return "latest"
+}
+
+// lintRules the built-in rules
+var lintRules = []LintRule{
+	{"LINT-NO-READINESS-PROBE", SeverityWarning, "serving container without readiness probe", func(w Workload, ctx lintContext) []string {
+		if !w.isServing() {
+			return nil
+		}
+		return forEachContainer(w, func(c ContainerSpec) string {
+			if !c.ReadinessProbe.IsSet() {
+				return fmt.Sprintf("container %s has no readiness probe, it receives traffic before it is ready", c.Name)
+			}
+			return ""
+		})
+	}},
+	{"LINT-NO-LIVENESS-PROBE", SeverityInfo, "serving container without liveness nor readiness probe", func(w Workload, ctx lintContext) []string {
+		if !w.isServing() {
+			return nil
+		}
+		// containers with a readiness probe are reported by PROBE-READINESS-WITHOUT-LIVENESS
+		return forEachContainer(w, func(c ContainerSpec) string {
+			if !c.LivenessProbe.IsSet() && !c.ReadinessProbe.IsSet() {
+				return fmt.Sprintf("container %s has no liveness probe, a hung process is never restarted", c.Name)
+			}
+			return ""
+		})
+	}},
+	{"LINT-NO-REQUESTS", SeverityError, "container without cpu or memory requests", func(w Workload, ctx lintContext) []string {
+		return forEachContainer(w, func(c ContainerSpec) string {
+			missing := []string{}
+			if c.Resources.Requests.CPU == "" && c.Resources.Limits.CPU == "" {
+				missing = append(missing, "cpu")
+			}
+			if c.Resources.Requests.Memory == "" && c.Resources.Limits.Memory == "" {
+				missing = append(missing, "memory")
+			}
+			if len(missing) > 0 {
+				return fmt.Sprintf("container %s has no %s requests, the scheduler can't place it properly", c.Name, strings.Join(missing, " and "))
+			}
+			return ""
+		})
+	}},
+	{"LINT-NO-LIMITS", SeverityWarning, "container without memory limit", func(w Workload, ctx lintContext) []string {
+		return forEachContainer(w, func(c ContainerSpec) string {
+			if c.Resources.Limits.Memory == "" {
+				return fmt.Sprintf("container %s has no memory limit, it can use all the memory of the node", c.Name)
+			}
+			return ""
+		})
+	}},
+	{"LINT-MEMORY-LIMIT-BELOW-REQUEST", SeverityError, "memory limit lower than the memory request", func(w Workload, ctx lintContext) []string {
+		return forEachContainer(w, func(c ContainerSpec) string {
+			requests, limits := c.Resources.Requests.GetMiMemory(), c.Resources.Limits.GetMiMemory()
+			if c.Resources.Requests.Memory != "" && c.Resources.Limits.Memory != "" && limits < requests {
+				return fmt.Sprintf("container %s has memory limit %dMi lower than its request %dMi", c.Name, limits, requests)
+			}
+			return ""
+		})
+	}},
+	{"LINT-SINGLE-REPLICA-NO-PDB", SeverityWarning, "single replica Deployment or StatefulSet without pdb", func(w Workload, ctx lintContext) []string {
+		if (w.Kind == "Deployment" || w.Kind == "StatefulSet") && len(w.Pods) == 1 && !w.HasPdb() {
+			return []string{"single replica without pdb, every node drain or upgrade is an outage"}
+		}
+		return nil
+	}},
+	{"LINT-PDB-ZERO-DISRUPTIONS", SeverityError, "pdb that allows zero disruptions", func(w Workload, ctx lintContext) []string {
+		if w.HasPdb() && w.Pdb.Status.DisruptionsAllowed == 0 {
+			return []string{fmt.Sprintf("pdb %s allows 0 disruptions, node drains are blocked", w.Pdb.Metadata.Name)}
+		}
+		return nil
+	}},
+	{"LINT-HPA-NO-PRESTOP", SeverityWarning, "container of an hpa workload without preStop hook", func(w Workload, ctx lintContext) []string {
+		if !ctx.hpaTargets[w.GetWorkloadKey()] {
+			return nil
+		}
+		return forEachContainer(w, func(c ContainerSpec) string {
+			if !c.HasPreStop() {
+				return fmt.Sprintf("container %s has no preStop hook, requests in flight are dropped on scale down", c.Name)
+			}
+			return ""
+		})
+	}},
+	{"LINT-LATEST-TAG", SeverityWarning, "image with the latest tag or without tag", func(w Workload, ctx lintContext) []string {
+		return forEachContainer(w, func(c ContainerSpec) string {
+			if getImageTag(c.Image) == "latest" {
+				return fmt.Sprintf("container %s uses image %s, the :latest tag is not reproducible", c.Name, c.Image)
+			}
+			return ""
+		})
+	}},
+}
+
+// BuildLintFindings runs the built-in and the probe rules on each workload, static pods are ignored
+func BuildLintFindings(workloads []Workload, hpaList []Hpa) []Finding {
+	rules := append(append([]LintRule{}, lintRules...), probeRules...)
+	return runLintRules(rules, workloads, newLintContext(hpaList))
+}
+
+func runLintRules(rules []LintRule, workloads []Workload, ctx lintContext) []Finding {
+	findings := []Finding{}
+	for _, w := range workloads {
+		if w.Kind == "Node" {
+			continue
+		}
+		for _, rule := range rules {
+			for _, message := range rule.check(w, ctx) {
+				findings = append(findings, Finding{ID: rule.ID, Severity: rule.Severity, Namespace: w.Namespace, Object: w.GetReference(), Message: message})
+			}
+		}
+	}
+	sortFindings(findings)
+	return findings
+}
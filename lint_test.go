@@ -0,0 +1,71 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestGetImageTag(t *testing.T) {
+	images := map[string]string{
+		"nginx":                       "latest",
+		"nginx:latest":                "latest",
+		"nginx:1.19":                  "1.19",
+		"localhost:5000/acme/api":     "latest",
+		"localhost:5000/acme/api:2.0": "2.0",
+		"gcr.io/acme/api@sha256:0123456789abcdef": "",
+	}
+	for image, expected := range images {
+		if tag := getImageTag(image); tag != expected {
+			t.Fatalf("Test failed! %s found %s expected %s", image, tag, expected)
+		}
+	}
+}
+
+func TestBuildLintFindings(t *testing.T) {
+	pods := buildPodList(`{"items": [
+		{"metadata": {"name": "api-7d9f-xk2lp", "namespace": "shop", "labels": {"app": "api"}, "ownerReferences": [{"kind": "ReplicaSet", "name": "api-7d9f"}]},
+		 "spec": {"containers": [{"name": "server", "image": "acme/api:1.0",
+		  "readinessProbe": {"httpGet": {"path": "/ready"}}, "livenessProbe": {"tcpSocket": {"port": 8080}},
+		  "resources": {"requests": {"cpu": "100m", "memory": "256Mi"}, "limits": {"memory": "128Mi"}}}]}},
+		{"metadata": {"name": "api-7d9f-pq7rt", "namespace": "shop", "labels": {"app": "api"}, "ownerReferences": [{"kind": "ReplicaSet", "name": "api-7d9f"}]},
+		 "spec": {"containers": [{"name": "server", "image": "acme/api:1.0"}]}},
+		{"metadata": {"name": "db-0", "namespace": "shop", "labels": {"app": "db"}, "ownerReferences": [{"kind": "StatefulSet", "name": "db"}]},
+		 "spec": {"containers": [{"name": "postgres", "image": "postgres",
+		  "readinessProbe": {"exec": {"command": ["pg_isready"]}}, "livenessProbe": {"exec": {"command": ["pg_isready"]}},
+		  "resources": {"requests": {"cpu": "1", "memory": "1Gi"}, "limits": {"memory": "1Gi"}}}]}},
+		{"metadata": {"name": "report-1572566400-z3z3z", "namespace": "batch", "ownerReferences": [{"kind": "Job", "name": "report-1572566400"}]},
+		 "spec": {"containers": [{"name": "report", "image": "acme/report:2.0", "resources": {"limits": {"cpu": "1", "memory": "1Gi"}}}]}},
+		{"metadata": {"name": "kube-proxy-node-a", "namespace": "kube-system", "ownerReferences": [{"kind": "Node", "name": "node-a"}]},
+		 "spec": {"containers": [{"name": "kube-proxy", "image": "kube-proxy"}]}}
+	]}`).Items
+	pdbs := buildPdbItems(`{"items": [
+		{"metadata": {"name": "api", "namespace": "shop"}, "spec": {"minAvailable": 2, "selector": {"matchLabels": {"app": "api"}}}, "status": {"disruptionsAllowed": 0}}
+	]}`).Items
+	hpas := buildHpaList("shop           api        Deployment/api        60%/80%         2         10        2          3d", "", pods)
+
+	findings := BuildLintFindings(BuildWorkloads(pods, pdbs), hpas)
+	expected := []string{
+		"shop Deployment/api LINT-HPA-NO-PRESTOP warning",
+		"shop Deployment/api LINT-MEMORY-LIMIT-BELOW-REQUEST error",
+		"shop Deployment/api LINT-PDB-ZERO-DISRUPTIONS error",
+		"shop Deployment/api PROBE-SHORT-TIMEOUT info",
+		"shop StatefulSet/db LINT-LATEST-TAG warning",
+		"shop StatefulSet/db LINT-SINGLE-REPLICA-NO-PDB warning",
+		"shop StatefulSet/db PROBE-SAME-ENDPOINT warning",
+		"shop StatefulSet/db PROBE-SHORT-TIMEOUT info",
+	}
+	if len(findings) != len(expected) {
+		t.Fatalf("Test failed! found %d expected %d: %+v", len(findings), len(expected), findings)
+	}
+	for i, f := range findings {
+		if actual := f.Namespace + " " + f.Object + " " + f.ID + " " + f.Severity; actual != expected[i] {
+			t.Fatalf("Test failed! found %s expected %s", actual, expected[i])
+		}
+	}
+	if findings[1].Message != "container server has memory limit 128Mi lower than its request 256Mi" {
+		t.Fatalf("Test failed! %s", findings[1].Message)
+	}
+
+	if !HasFindingsAbove(findings, SeverityError) || HasFindingsAbove(findings[3:], SeverityError) || !HasFindingsAbove(findings[3:], SeverityInfo) {
+		t.Fatalf("Test failed! -fail-on")
+	}
+}
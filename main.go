@@ -6,11 +6,19 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"text/tabwriter"
 	"time"
 )
 
+// commands are given as the first argument, eg. kubectl resource-snapshot simulate-consolidation -csv-output test
+var commands = []string{"simulate-consolidation", "drain-plan", "recommend", "rewrite-manifests", "what-if", "lint"}
+
+// clusterCommands simulate the scheduling of the whole cluster, their nodes keep all pods whatever the -n, -p and -d filters
+var clusterCommands = []string{"simulate-consolidation", "drain-plan", "what-if"}
+
 const version = "0.1.3"
 const versionDesciption = "Small change to improve get deployment name method"
 
@@ -20,11 +28,60 @@ func main() {
 	d := flag.String("d", "", "Filter by the deployment name (default:empty means all deployments)")
 	n := flag.String("n", "", "Filter by namespace name (default:empty means all namespaces)")
 	v := flag.Bool("v", false, "Show the plugin version")
-	show := flag.String("print", "all", "Define what will be printed. Valid values all|pods|hpas|nodes ")
-	csv := flag.String("csv-output", "", "Save the result to files with format 'kubectl-snapshot-<date>-<csv-output>-<pods|hpas|nohpa|nodes|all>.csv'")
+	show := flag.String("print", "all", "Define what will be printed. Valid values all|pods|containers|workloads|hpas|nodes|nodepools|namespaces|eviction|gpus|pdbs|probes|startup|pulls ")
+	csv := flag.String("csv-output", "", "Save the result to files with format 'kubectl-snapshot-<date>-<csv-output>-<pods|containers|workloads|hpas|nohpa|nodes|nodepools|namespaces|eviction|gpu-nodes|gpu-workloads|pdbs|probes|startup-workloads|startup-nodepools|startup-images|pulls-images|pulls-nodes>.csv'")
+	nodepoolLabel := flag.String("nodepool-label", "", "Comma separated node labels used to detect the node pool, checked before the built-in GKE, EKS, AKS, Karpenter and kOps labels")
+	ephemeralUsage := flag.Bool("ephemeral-usage", false, "Collect ephemeral-storage usage from the kubelet stats summary (one request per node, requires nodes/proxy permission)")
+	samples := flag.Int("samples", 1, "recommend: number of kubectl top snapshots used as usage samples")
+	sampleInterval := flag.Duration("sample-interval", 30*time.Second, "recommend: interval between two kubectl top snapshots")
+	prometheus := flag.String("prometheus", "", "recommend: prometheus url, eg. http://localhost:9090, used instead of kubectl top")
+	prometheusRange := flag.String("prometheus-range", "7d", "recommend: time range of the prometheus usage")
+	headroom := flag.Int("headroom", 20, "recommend: % added to the observed usage")
+	usagePercentile := flag.Int("percentile", 95, "recommend: usage percentile (0-100)")
+	minCPU := flag.Int("min-cpu", 10, "recommend: minimum cpu request (m)")
+	minMemory := flag.Int("min-memory", 32, "recommend: minimum memory request (Mi)")
+	patchFormat := flag.String("patch-format", "", "recommend: print the recommendations as patches instead of tables. Valid values yaml|script|kustomize")
+	patchDir := flag.String("patch-dir", "", "recommend: directory where the patches are written (required by kustomize, default:empty means standard output)")
+	manifestsDir := flag.String("manifests-dir", "", "rewrite-manifests: directory of the yaml manifests, the resources are edited in place")
+	whatIfTarget := flag.Int("target", 0, "what-if: new target cpu % of all hpas (default:0 means keep the current target)")
+	cpuRequestsChange := flag.Int("cpu-requests-change", 0, "what-if: % added to the cpu requests of the hpa pods, eg. -30")
+	traffic := flag.Float64("traffic", 1, "what-if: cpu usage multiplier, eg. 2 means traffic doubled")
+	pricingFile := flag.String("pricing", "", "Pricing yaml file with the hourly price of one vCPU and one GiB, optionally per instance type, zone and spot, used by the monthly cost columns")
+	var groupByLabels labelFlag
+	flag.Var(&groupByLabels, "group-by-label", "Print the showback of the pods grouped by this label, falls back to the namespace label (repeatable, eg. -group-by-label team -group-by-label cost-center)")
+	failOn := flag.String("fail-on", "", "lint: exit with code 1 if a finding has this severity or a higher one. Valid values info|warning|error (default:empty means never fail)")
+	rulesFile := flag.String("rules", "", "Yaml file with user-defined rules, evaluated with the built-in ones by lint and -o json, and printed after the -print view otherwise")
+	output := flag.String("o", "table", "Output format. Valid values table|json, json prints one document with the pods, hpas, deployments, nodes, pdbs and findings, supported without command and by lint")
 	debug := flag.Bool("debug", false, "Show debug info")
-	flag.Parse()
-	printFlags(*p, *d, *n, *v, *show, *csv, *debug)
+	command := ""
+	if len(os.Args) > 1 && contains(commands, os.Args[1]) {
+		command = os.Args[1]
+		flag.CommandLine.Parse(os.Args[2:])
+	} else {
+		flag.Parse()
+	}
+	if *output != "table" && *output != "json" {
+		log.Fatalf("Invalid -o %s. Valid values table|json", *output)
+	}
+	if *output == "json" && command != "" && command != "lint" {
+		log.Fatalf("-o json is not supported by %s", command)
+	}
+	if *usagePercentile < 0 || *usagePercentile > 100 {
+		log.Fatalf("Invalid -percentile %d. Valid values 0-100", *usagePercentile)
+	}
+	if *headroom < 0 {
+		log.Fatalf("Invalid -headroom %d. It must be 0 or more", *headroom)
+	}
+	if *failOn != "" && severityLevel(*failOn) == -1 {
+		log.Fatalf("Invalid -fail-on %s. Valid values info|warning|error", *failOn)
+	}
+	if *nodepoolLabel != "" {
+		AddNodepoolLabels(strings.Split(*nodepoolLabel, ","))
+	}
+	if *pricingFile != "" {
+		LoadPricing(*pricingFile)
+	}
+	printFlags(command, *p, *d, *n, *v, *show, *csv, *debug)
 
 	if *v || *debug {
 		fmt.Printf("Plugin Version: %s (%s)\n", version, versionDesciption)
@@ -46,8 +103,17 @@ func main() {
 		podList = filterPod(podList, func(pod Pod) bool { return pod.GetDeploymentName() == *d })
 	}
 
+	// Ephemeral storage usage from kubelet stats summary ..
+	statsMap := make(map[string]StatsSummary)
+	if *ephemeralUsage {
+		statsMap = RetrieveStatsSummaryMap(podList)
+		podList = enrichPodsWithStats(podList, statsMap)
+	}
+
+	pdbList := RetrievePdbs()
+
 	// Hpas, use podList to confirm resource usgage ..
-	hpaList := RetrieveHpas(*n, podList)
+	hpaList := RetrieveHpas(*n, podList, pdbList)
 	if *p != "" {
 		hpaList = filterHpa(hpaList, func(h Hpa) bool { return h.ContainsPod(*p) })
 	} else if *d != "" {
@@ -55,7 +121,7 @@ func main() {
 	}
 
 	// Deployments for non-hpas, use podList to confirm resource usgage ..
-	deploymentList := RetrieveDeployments(*n, podList)
+	deploymentList := RetrieveDeployments(*n, podList, pdbList)
 	if *p != "" {
 		deploymentList = filterDeployment(deploymentList, func(deploy Deployment) bool { return deploy.ContainsPod(*p) })
 	} else if *d != "" {
@@ -72,32 +138,171 @@ func main() {
 		}
 	}
 
+	// Workloads, grouped by the controller that created the pods ..
+	workloadList := BuildWorkloads(podList, pdbList)
+
 	// Nodes, use podList to confirm resource usgage ..
-	nodeList := RetrieveNodes(podList)
+	nodeList := enrichNodesWithStats(RetrieveNodes(podList), statsMap)
+	SetNodePrices(nodeList)
 	// TODO: filter
 
+	// Nodes with all their pods for the scheduling simulations ..
+	// Pdbs of the pdb report, -p and -d keep the pdbs covering the filtered pods ..
+	reportPdbList := pdbList
+	if *n != "" {
+		reportPdbList = filterPdb(reportPdbList, func(pdb Pdb) bool { return pdb.Metadata.Namespace == *n })
+	}
+	if *p != "" || *d != "" {
+		reportPdbList = filterPdb(reportPdbList, func(pdb Pdb) bool { return pdb.matchesAny(podList) })
+	}
+
+	clusterNodeList := nodeList
+	if contains(clusterCommands, command) && (*n != "" || *p != "" || *d != "") {
+		clusterNodeList = RetrieveNodes(RetrievePods(""))
+		SetNodePrices(clusterNodeList)
+	}
+
+	// JSON document, the lint command exits with code 1 depending on the lint findings ..
+	if *output == "json" {
+		reports := BuildPdbReports(reportPdbList, podList)
+		lintFindings := buildLintAndRuleFindings(workloadList, hpaList, nodeList, *rulesFile)
+		findings := append(BuildHpaFindings(hpaList), BuildSpreadFindings(workloadList, nodeList)...)
+		findings = append(append(findings, BuildPdbFindings(reports)...), lintFindings...)
+		sortFindings(findings)
+		metadata := JSONMetadata{Time: time.Now().UTC(), Context: RetrieveCurrentContext(), Namespace: *n}
+		err := WriteJSONDocument(os.Stdout, BuildJSONDocument(metadata, podList, hpaList, deploymentList, nodeList, reports, findings))
+		if err != nil {
+			log.Fatalf("%+v", err)
+		}
+		if command == "lint" && *failOn != "" && HasFindingsAbove(lintFindings, *failOn) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Commands ..
+	switch command {
+	case "simulate-consolidation":
+		consolidations := SimulateConsolidation(clusterNodeList, pdbList)
+		printConsolidationTab(consolidations, BuildNodepoolConsolidations(consolidations), csvFilePrefix, *debug)
+		return
+	case "drain-plan":
+		printDrainPlanTab(BuildDrainPlan(clusterNodeList, pdbList), pdbList, csvFilePrefix, *debug)
+		return
+	case "lint":
+		findings := buildLintAndRuleFindings(workloadList, hpaList, nodeList, *rulesFile)
+		printFindingsTab("LINT FINDINGs", findings, csvFilePrefix, "lint", *debug)
+		if *failOn != "" && HasFindingsAbove(findings, *failOn) {
+			os.Exit(1)
+		}
+		return
+	case "what-if":
+		opts := WhatIfOptions{Target: *whatIfTarget, CPURequestsChange: *cpuRequestsChange, Traffic: *traffic}
+		results, cluster := SimulateWhatIf(hpaList, clusterNodeList, opts)
+		printWhatIfTab(results, cluster, csvFilePrefix, *debug)
+		return
+	case "recommend", "rewrite-manifests":
+		if command == "rewrite-manifests" && *manifestsDir == "" {
+			log.Fatalf("-manifests-dir is required by rewrite-manifests")
+		}
+		var usage map[string]*UsageSamples
+		if *prometheus != "" {
+			usage = RetrievePrometheusUsageSamples(*prometheus, *prometheusRange, *usagePercentile, podList)
+		} else if *samples > 1 {
+			usage = BuildTopUsageSamples(podList, RetrieveTopSamples(*n, *samples, *sampleInterval))
+		} else {
+			topMap := make(map[string]Top)
+			for _, pod := range podList {
+				topMap[pod.GetPodKey()] = pod.Top
+			}
+			usage = BuildTopUsageSamples(podList, []map[string]Top{topMap})
+		}
+		opts := RecommendOptions{Headroom: *headroom, Percentile: *usagePercentile, MinMilliCPU: *minCPU, MinMiMemory: *minMemory}
+		recommendations := BuildRecommendations(workloadList, usage, opts)
+		if command == "rewrite-manifests" {
+			printManifestsTab(RewriteManifests(*manifestsDir, recommendations), csvFilePrefix, *debug)
+			return
+		}
+		if *patchFormat != "" {
+			printPatches(BuildResourcePatches(recommendations), *patchFormat, *patchDir)
+			return
+		}
+		printRecommendTab(recommendations, opts, csvFilePrefix, *debug)
+		return
+	}
+
+	// Showback by label ..
+	if len(groupByLabels) > 0 {
+		printLabelGroupsTab(groupByLabels, BuildLabelGroups(podList, groupByLabels, RetrieveNamespaceLabels()), csvFilePrefix, *debug)
+		return
+	}
+
 	// Print standard io or send to csv files ..
 	switch *show {
 	case "pod":
 	case "pods":
 		printPodsTab(podList, csvFilePrefix, *debug)
+	case "containers":
+		printContainersTab(BuildPodContainers(podList), csvFilePrefix, *debug)
+	case "workloads":
+		printWorkloadsTab(workloadList, nodeList, csvFilePrefix, *debug)
+		printFindingsTab("SPREAD FINDINGs", BuildSpreadFindings(workloadList, nodeList), csvFilePrefix, "spread-findings", *debug)
 	case "hpa":
 	case "hpas":
 		printHpaTab(hpaList, csvFilePrefix, *debug)
+		printFindingsTab("HPA FINDINGs", BuildHpaFindings(hpaList), csvFilePrefix, "hpa-findings", *debug)
 		printNoHpaTab(deploymentWithoutHpa, csvFilePrefix, *debug)
 	case "node":
 	case "nodes":
 		printNodesTab(nodeList, csvFilePrefix, *debug)
+	case "nodepools":
+		printNodepoolsTab(BuildNodepools(nodeList), csvFilePrefix, *debug)
+	case "eviction":
+		printEvictionTab(BuildEvictionRisks(nodeList), csvFilePrefix, *debug)
+	case "gpus":
+		printGpusTab(BuildGpuNodes(nodeList), BuildGpuWorkloads(workloadList), csvFilePrefix, *debug)
+	case "pdbs":
+		reports := BuildPdbReports(reportPdbList, podList)
+		printPdbsTab(reports, csvFilePrefix, *debug)
+		printFindingsTab("PDB FINDINGs", BuildPdbFindings(reports), csvFilePrefix, "pdb-findings", *debug)
+	case "probes":
+		printProbesTab(workloadList, csvFilePrefix, *debug)
+		printFindingsTab("PROBE FINDINGs", BuildProbeFindings(workloadList), csvFilePrefix, "probe-findings", *debug)
+	case "startup":
+		printStartupTab("STARTUP BY WORKLOAD", "Workload", BuildWorkloadStartups(workloadList), csvFilePrefix, "startup-workloads", *debug)
+		printStartupTab("STARTUP BY NODE POOL", "Node Pool", BuildNodepoolStartups(nodeList), csvFilePrefix, "startup-nodepools", *debug)
+		printStartupTab("STARTUP BY IMAGE", "Image", BuildImageStartups(podList), csvFilePrefix, "startup-images", *debug)
+	case "pulls":
+		pulls := BuildImagePulls(RetrieveEvents(*n), podList)
+		printPullGroupsTab("IMAGE PULLs BY IMAGE", "Image", BuildImagePullGroups(pulls), csvFilePrefix, "pulls-images", *debug)
+		printPullGroupsTab("IMAGE PULLs BY NODE", "Node", BuildNodePullGroups(pulls), csvFilePrefix, "pulls-nodes", *debug)
+	case "namespaces":
+		printNamespacesTab(BuildNamespaceSummaries(podList, workloadList), csvFilePrefix, *debug)
 	default:
 		printPodsTab(podList, csvFilePrefix, *debug)
 		printHpaTab(hpaList, csvFilePrefix, *debug)
+		printFindingsTab("HPA FINDINGs", BuildHpaFindings(hpaList), csvFilePrefix, "hpa-findings", *debug)
 		printNoHpaTab(deploymentWithoutHpa, csvFilePrefix, *debug)
 		printNodesTab(nodeList, csvFilePrefix, *debug)
 	}
 
+	// User-defined rules, printed after any view ..
+	if *rulesFile != "" {
+		printFindingsTab("RULE FINDINGs", BuildRuleFindings(LoadRules(*rulesFile), workloadList, hpaList, nodeList), csvFilePrefix, "rule-findings", *debug)
+	}
+}
+
+// buildLintAndRuleFindings returns the built-in lint findings plus the ones of the -rules file
+func buildLintAndRuleFindings(workloadList []Workload, hpaList []Hpa, nodeList []Node, rulesFile string) []Finding {
+	findings := BuildLintFindings(workloadList, hpaList)
+	if rulesFile != "" {
+		findings = append(findings, BuildRuleFindings(LoadRules(rulesFile), workloadList, hpaList, nodeList)...)
+		sortFindings(findings)
+	}
+	return findings
 }
 
-func printFlags(p string, d string, n string, v bool, show string, csv string, debug bool) {
+func printFlags(command string, p string, d string, n string, v bool, show string, csv string, debug bool) {
 	if debug {
 		fmt.Println("---------------------------------------------")
 		fmt.Println("[debug] FLAGS: ")
@@ -105,8 +310,10 @@ func printFlags(p string, d string, n string, v bool, show string, csv string, d
 		fmt.Println("   -d [DEPLOYMENT] is: ", d)
 		fmt.Println("   -o [NAMESPACE] is: ", n)
 		fmt.Println("   -v [VERSION] is: ", v)
+		fmt.Println("   [COMMAND] is: ", command)
 		fmt.Println("   -print [PRINT IN STANDARD OUTPUT] is: ", show)
 		fmt.Println("   -csv-output [SAVE TO FILES] is: ", csv)
+		fmt.Println("   -nodepool-label [NODE POOL LABELS] is: ", nodepoolLabels)
 		fmt.Println("---------------------------------------------")
 		fmt.Println()
 	}
@@ -116,17 +323,17 @@ func printPodsTab(podList []Pod, csvFilePrefix string, debug bool) {
 	result := Wrapper{Pods: podList}
 
 	if csvFilePrefix == "" || debug {
-		formatHeader := "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n"
-		formatValues := "%v\t%v\t%vm\t%vm\t%0.2f%%\t%vMi\t%vMi\t%0.2f%%\t%vm\t%vMi\t%v\n"
+		formatHeader := "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v%v\n"
+		formatValues := "%v\t%v\t%vm\t%vm\t%0.2f%%\t%vMi\t%vMi\t%0.2f%%\t%vm\t%vMi\t%v\t%v\t%v\t%v\t%vMi\t%vMi\t%v\t%vMi\t%v%v\n"
 		fmt.Println("\nPODs SNAPSHOT:")
 		w := tabwriter.NewWriter(os.Stdout, 0, 1, 2, ' ', tabwriter.TabIndent)
-		fmt.Fprintf(w, formatHeader, "Namespace", "Pod Name", "Requests CPU (m)", "TOP CPU (m)", "Usage CPU (%)", "Requests Memory (Mi)", "TOP Memory (Mi)", "Usage Memory (%)", "Limits CPU (m)", "Limitis Memory (Mi)", "Pod Startup Duration (AVG)")
-		fmt.Fprintf(w, formatHeader, "---------", "--------", "----------------", "-----------", "-------------", "--------------------", "---------------", "----------------", "--------------", "-------------------", "--------------------------")
+		fmt.Fprintf(w, formatHeader, "Namespace", "Pod Name", "Requests CPU (m)", "TOP CPU (m)", "Usage CPU (%)", "Requests Memory (Mi)", "TOP Memory (Mi)", "Usage Memory (%)", "Limits CPU (m)", "Limitis Memory (Mi)", "Pod Startup Duration (AVG)", "QoS Class", "Priority Class", "Priority", "Requests Ephemeral Storage (Mi)", "Used Ephemeral Storage (Mi)", "Usage Ephemeral Storage (%)", "Limits Ephemeral Storage (Mi)", "Extended Resources", pricedColumns(costHeader("Requests"), costHeader("Used"), costHeader("Wasted")))
+		fmt.Fprintf(w, formatHeader, "---------", "--------", "----------------", "-----------", "-------------", "--------------------", "---------------", "----------------", "--------------", "-------------------", "--------------------------", "---------", "--------------", "--------", "-------------------------------", "---------------------------", "---------------------------", "-----------------------------", "------------------", pricedColumns("-------------------------", "---------------------", "-------------------------"))
 		for _, pod := range result.Pods {
-			fmt.Fprintf(w, formatValues, pod.Metadata.Namespace, pod.Metadata.Name, pod.GetRequestsMilliCPU(), pod.GetTopMilliCPU(), pod.GetUsageCPU(), pod.GetRequestsMiMemory(), pod.GetTopMiMemory(), pod.GetUsageMemory(), pod.GetLimitsMilliCPU(), pod.GetLimitsMiMemory(), pod.GetStartupDuration())
+			fmt.Fprintf(w, formatValues, pod.Metadata.Namespace, pod.Metadata.Name, pod.GetRequestsMilliCPU(), pod.GetTopMilliCPU(), pod.GetUsageCPU(), pod.GetRequestsMiMemory(), pod.GetTopMiMemory(), pod.GetUsageMemory(), pod.GetLimitsMilliCPU(), pod.GetLimitsMiMemory(), pod.GetStartupDuration(), pod.GetQosClass(), pod.Spec.PriorityClassName, pod.GetPriority(), pod.GetRequestsMiEphemeralStorage(), pod.GetUsedMiEphemeralStorage(), formatEphemeralUsage(pod.GetUsageEphemeralStorage(), pod.HasStats(), true), pod.GetLimitsMiEphemeralStorage(), pod.GetExtendedResources(), pricedColumns(pod.GetRequestsCost(), pod.GetTopCost(), pod.GetWasteCost()))
 		}
-		fmt.Fprintf(w, formatHeader, " ", " ", "----------------", "-----------", "-------------", "--------------------", "---------------", "----------------", "--------------", "-------------------", "--------------------------")
-		fmt.Fprintf(w, formatValues, " ", " ", result.GetRequestsMilliCPU(), result.GetTopMilliCPU(), result.GetUsageCPU(), result.GetRequestsMiMemory(), result.GetTopMiMemory(), result.GetUsageMemory(), result.GetLimitsMilliCPU(), result.GetLimitsMiMemory(), "")
+		fmt.Fprintf(w, formatHeader, " ", " ", "----------------", "-----------", "-------------", "--------------------", "---------------", "----------------", "--------------", "-------------------", "--------------------------", " ", " ", " ", "-------------------------------", "---------------------------", "---------------------------", "-----------------------------", " ", pricedColumns("-------------------------", "---------------------", "-------------------------"))
+		fmt.Fprintf(w, formatValues, " ", " ", result.GetRequestsMilliCPU(), result.GetTopMilliCPU(), result.GetUsageCPU(), result.GetRequestsMiMemory(), result.GetTopMiMemory(), result.GetUsageMemory(), result.GetLimitsMilliCPU(), result.GetLimitsMiMemory(), "", "", "", "", result.GetRequestsMiEphemeralStorage(), result.GetUsedMiEphemeralStorage(), formatEphemeralUsage(result.GetUsageEphemeralStorage(), result.HasStats(), true), result.GetLimitsMiEphemeralStorage(), "", pricedColumns(result.GetRequestsCost(), result.GetTopCost(), result.GetWasteCost()))
 		w.Flush()
 	}
 
@@ -140,13 +347,137 @@ func printPodsTab(podList []Pod, csvFilePrefix string, debug bool) {
 		writer := csv.NewWriter(file)
 		defer writer.Flush()
 
-		header := []string{"Namespace", "Pod Name", "Requests CPU (m)", "TOP CPU (m)", "Usage CPU (%)", "Requests Memory (Mi)", "TOP Memory (Mi)", "Usage Memory (%)", "Limits CPU (m)", "Limitis Memory (Mi)", "Pod Startup Duration (AVG)"}
+		header := append([]string{"Namespace", "Pod Name", "Requests CPU (m)", "TOP CPU (m)", "Usage CPU (%)", "Requests Memory (Mi)", "TOP Memory (Mi)", "Usage Memory (%)", "Limits CPU (m)", "Limitis Memory (Mi)", "Pod Startup Duration (AVG)", "QoS Class", "Priority Class", "Priority", "Requests Ephemeral Storage (Mi)", "Used Ephemeral Storage (Mi)", "Usage Ephemeral Storage (%)", "Limits Ephemeral Storage (Mi)", "Extended Resources"}, pricedCells(costHeader("Requests"), costHeader("Used"), costHeader("Wasted"))...)
 		err = writer.Write(header)
 		if err != nil {
 			log.Fatal(err)
 		}
 		for _, pod := range result.Pods {
-			line := []string{pod.Metadata.Namespace, pod.Metadata.Name, strconv.Itoa(pod.GetRequestsMilliCPU()), strconv.Itoa(pod.GetTopMilliCPU()), fmt.Sprintf("%.2f", pod.GetUsageCPU()), strconv.Itoa(pod.GetRequestsMiMemory()), strconv.Itoa(pod.GetTopMiMemory()), fmt.Sprintf("%.2f", pod.GetUsageMemory()), strconv.Itoa(pod.GetLimitsMilliCPU()), strconv.Itoa(pod.GetLimitsMiMemory()), fmt.Sprintf("%s", pod.GetStartupDuration())}
+			line := append([]string{pod.Metadata.Namespace, pod.Metadata.Name, strconv.Itoa(pod.GetRequestsMilliCPU()), strconv.Itoa(pod.GetTopMilliCPU()), fmt.Sprintf("%.2f", pod.GetUsageCPU()), strconv.Itoa(pod.GetRequestsMiMemory()), strconv.Itoa(pod.GetTopMiMemory()), fmt.Sprintf("%.2f", pod.GetUsageMemory()), strconv.Itoa(pod.GetLimitsMilliCPU()), strconv.Itoa(pod.GetLimitsMiMemory()), fmt.Sprintf("%s", pod.GetStartupDuration()), pod.GetQosClass(), pod.Spec.PriorityClassName, strconv.Itoa(pod.GetPriority()), strconv.Itoa(pod.GetRequestsMiEphemeralStorage()), strconv.Itoa(pod.GetUsedMiEphemeralStorage()), formatEphemeralUsage(pod.GetUsageEphemeralStorage(), pod.HasStats(), false), strconv.Itoa(pod.GetLimitsMiEphemeralStorage()), pod.GetExtendedResources()}, pricedCells(formatCost(pod.GetRequestsCost()), formatCost(pod.GetTopCost()), formatCost(pod.GetWasteCost()))...)
+			err := writer.Write(line)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+}
+
+func printContainersTab(containerList []PodContainer, csvFilePrefix string, debug bool) {
+	if csvFilePrefix == "" || debug {
+		formatHeader := "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n"
+		formatValues := "%v\t%v\t%v\t%v\t%v\t%vm\t%vm\t%0.2f%%\t%vMi\t%vMi\t%0.2f%%\t%vm\t%vMi\t%v\t%v\t%v\n"
+		fmt.Println("\nCONTAINERs SNAPSHOT:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 1, 2, ' ', tabwriter.TabIndent)
+		fmt.Fprintf(w, formatHeader, "Namespace", "Pod Name", "Workload", "Container", "Image", "Requests CPU (m)", "TOP CPU (m)", "Usage CPU (%)", "Requests Memory (Mi)", "TOP Memory (Mi)", "Usage Memory (%)", "Limits CPU (m)", "Limitis Memory (Mi)", "Liveness Probe", "Readiness Probe", "Lifecycle PreStop")
+		fmt.Fprintf(w, formatHeader, "---------", "--------", "--------", "---------", "-----", "----------------", "-----------", "-------------", "--------------------", "---------------", "----------------", "--------------", "-------------------", "--------------", "---------------", "-----------------")
+		for _, c := range containerList {
+			fmt.Fprintf(w, formatValues, c.Pod.Metadata.Namespace, c.Pod.Metadata.Name, c.Pod.GetWorkloadName(), c.Spec.Name, c.Spec.Image, c.GetRequestsMilliCPU(), c.GetTopMilliCPU(), c.GetUsageCPU(), c.GetRequestsMiMemory(), c.GetTopMiMemory(), c.GetUsageMemory(), c.GetLimitsMilliCPU(), c.GetLimitsMiMemory(), c.Spec.LivenessProbe, c.Spec.ReadinessProbe, c.Spec.GetPreStop())
+		}
+		w.Flush()
+	}
+
+	if csvFilePrefix != "" {
+		file, err := os.Create(csvFilePrefix + "-containers.csv")
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+
+		writer := csv.NewWriter(file)
+		defer writer.Flush()
+
+		header := []string{"Namespace", "Pod Name", "Workload Kind", "Workload", "Container", "Image", "Requests CPU (m)", "TOP CPU (m)", "Usage CPU (%)", "Requests Memory (Mi)", "TOP Memory (Mi)", "Usage Memory (%)", "Limits CPU (m)", "Limitis Memory (Mi)", "Liveness Probe", "Readiness Probe", "Lifecycle PreStop"}
+		err = writer.Write(header)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, c := range containerList {
+			line := []string{c.Pod.Metadata.Namespace, c.Pod.Metadata.Name, c.Pod.GetWorkloadKind(), c.Pod.GetWorkloadName(), c.Spec.Name, c.Spec.Image, strconv.Itoa(c.GetRequestsMilliCPU()), strconv.Itoa(c.GetTopMilliCPU()), fmt.Sprintf("%.2f", c.GetUsageCPU()), strconv.Itoa(c.GetRequestsMiMemory()), strconv.Itoa(c.GetTopMiMemory()), fmt.Sprintf("%.2f", c.GetUsageMemory()), strconv.Itoa(c.GetLimitsMilliCPU()), strconv.Itoa(c.GetLimitsMiMemory()), c.Spec.LivenessProbe.String(), c.Spec.ReadinessProbe.String(), c.Spec.GetPreStop()}
+			err := writer.Write(line)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+}
+
+func printWorkloadsTab(workloadList []Workload, nodeList []Node, csvFilePrefix string, debug bool) {
+	nodeMap := make(map[string]Node)
+	for _, node := range nodeList {
+		nodeMap[node.GetName()] = node
+	}
+
+	if csvFilePrefix == "" || debug {
+		formatHeader := "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v%v\n"
+		formatValues := "%v\t%v\t%v\t%v\t%vm\t%vm\t%0.2f%%\t%vMi\t%vMi\t%0.2f%%\t%vm\t%vMi\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%vMi\t%vMi\t%v\t%vMi%v\n"
+		fmt.Println("\nWORKLOADs SNAPSHOT:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 1, 2, ' ', tabwriter.TabIndent)
+		fmt.Fprintf(w, formatHeader, "Namespace", "Kind", "Workload Name", "# Pods ->", "Requests CPU (m)", "TOP CPU (m)", "Usage CPU (%)", "Requests Memory (Mi)", "TOP Memory (Mi)", "Usage Memory (%)", "Limits CPU (m)", "Limitis Memory (Mi)", "Pod Startup Duration (AVG)", "PDB", "Count Liveness Probe", "Count Readiness Probe", "Count Lifecycle PreStop", "Spread (Pods/Nodes/Zones)", "QoS Class", "Priority Class", "Requests Ephemeral Storage (Mi)", "Used Ephemeral Storage (Mi)", "Usage Ephemeral Storage (%)", "Limits Ephemeral Storage (Mi)", pricedColumns(costHeader("Requests"), costHeader("Used"), costHeader("Wasted")))
+		fmt.Fprintf(w, formatHeader, "---------", "----", "-------------", "---------", "----------------", "-----------", "-------------", "--------------------", "---------------", "----------------", "--------------", "-------------------", "--------------------------", "---", "--------------------", "---------------------", "-----------------------", "-------------------------", "---------", "--------------", "-------------------------------", "---------------------------", "---------------------------", "-----------------------------", pricedColumns("-------------------------", "---------------------", "-------------------------"))
+		for _, workload := range workloadList {
+			wp := Wrapper{Pods: workload.Pods}
+			fmt.Fprintf(w, formatValues, workload.Namespace, workload.Kind, workload.Name, len(workload.Pods), wp.GetRequestsMilliCPU(), wp.GetTopMilliCPU(), wp.GetUsageCPU(), wp.GetRequestsMiMemory(), wp.GetTopMiMemory(), wp.GetUsageMemory(), wp.GetLimitsMilliCPU(), wp.GetLimitsMiMemory(), wp.GetAvgStartupDuration(), workload.Pdb.Metadata.Name, workload.CountLivenessProbes(), workload.CountReadinessProbes(), workload.CountLifecyclePreStop(), workload.GetSpread(nodeMap), workload.GetQosClass(), workload.GetPriorityClassName(), wp.GetRequestsMiEphemeralStorage(), wp.GetUsedMiEphemeralStorage(), formatEphemeralUsage(wp.GetUsageEphemeralStorage(), wp.HasStats(), true), wp.GetLimitsMiEphemeralStorage(), pricedColumns(wp.GetRequestsCost(), wp.GetTopCost(), wp.GetWasteCost()))
+		}
+		w.Flush()
+	}
+
+	if csvFilePrefix != "" {
+		file, err := os.Create(csvFilePrefix + "-workloads.csv")
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+
+		writer := csv.NewWriter(file)
+		defer writer.Flush()
+
+		header := append([]string{"Namespace", "Kind", "Workload Name", "# Pods ->", "Requests CPU (m)", "TOP CPU (m)", "Usage CPU (%)", "Requests Memory (Mi)", "TOP Memory (Mi)", "Usage Memory (%)", "Limits CPU (m)", "Limitis Memory (Mi)", "Pod Startup Duration (AVG)", "PDB", "Count Liveness Probe", "Count Readiness Probe", "Count Lifecycle PreStop", "# Nodes", "# Zones", "QoS Class", "Priority Class", "Requests Ephemeral Storage (Mi)", "Used Ephemeral Storage (Mi)", "Usage Ephemeral Storage (%)", "Limits Ephemeral Storage (Mi)"}, pricedCells(costHeader("Requests"), costHeader("Used"), costHeader("Wasted"))...)
+		err = writer.Write(header)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, workload := range workloadList {
+			wp := Wrapper{Pods: workload.Pods}
+			spread := workload.GetSpread(nodeMap)
+			line := append([]string{workload.Namespace, workload.Kind, workload.Name, strconv.Itoa(len(workload.Pods)), strconv.Itoa(wp.GetRequestsMilliCPU()), strconv.Itoa(wp.GetTopMilliCPU()), fmt.Sprintf("%.2f", wp.GetUsageCPU()), strconv.Itoa(wp.GetRequestsMiMemory()), strconv.Itoa(wp.GetTopMiMemory()), fmt.Sprintf("%.2f", wp.GetUsageMemory()), strconv.Itoa(wp.GetLimitsMilliCPU()), strconv.Itoa(wp.GetLimitsMiMemory()), fmt.Sprintf("%s", wp.GetAvgStartupDuration()), workload.Pdb.Metadata.Name, workload.CountLivenessProbes(), workload.CountReadinessProbes(), workload.CountLifecyclePreStop(), strconv.Itoa(spread.Nodes), strconv.Itoa(spread.Zones), workload.GetQosClass(), workload.GetPriorityClassName(), strconv.Itoa(wp.GetRequestsMiEphemeralStorage()), strconv.Itoa(wp.GetUsedMiEphemeralStorage()), formatEphemeralUsage(wp.GetUsageEphemeralStorage(), wp.HasStats(), false), strconv.Itoa(wp.GetLimitsMiEphemeralStorage())}, pricedCells(formatCost(wp.GetRequestsCost()), formatCost(wp.GetTopCost()), formatCost(wp.GetWasteCost()))...)
+			err := writer.Write(line)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+}
+
+func printFindingsTab(title string, findingList []Finding, csvFilePrefix string, csvSuffix string, debug bool) {
+	if csvFilePrefix == "" || debug {
+		formatHeader := "%v\t%v\t%v\t%v\t%v\n"
+		fmt.Printf("\n%s:\n", title)
+		w := tabwriter.NewWriter(os.Stdout, 0, 1, 2, ' ', tabwriter.TabIndent)
+		fmt.Fprintf(w, formatHeader, "Severity", "Rule", "Namespace", "Object", "Message")
+		fmt.Fprintf(w, formatHeader, "--------", "----", "---------", "------", "-------")
+		for _, f := range findingList {
+			fmt.Fprintf(w, formatHeader, f.Severity, f.ID, f.Namespace, f.Object, f.Message)
+		}
+		w.Flush()
+	}
+
+	if csvFilePrefix != "" {
+		file, err := os.Create(csvFilePrefix + "-" + csvSuffix + ".csv")
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+
+		writer := csv.NewWriter(file)
+		defer writer.Flush()
+
+		header := []string{"Severity", "Rule", "Namespace", "Object", "Message"}
+		err = writer.Write(header)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, f := range findingList {
+			line := []string{f.Severity, f.ID, f.Namespace, f.Object, f.Message}
 			err := writer.Write(line)
 			if err != nil {
 				log.Fatal(err)
@@ -193,7 +524,7 @@ func printHpaTab(hpaList []Hpa, csvFilePrefix string, debug bool) {
 			if hpa.UsageCPU != -1 {
 				hpaUse = strconv.Itoa(hpa.UsageCPU)
 			}
-			line := []string{hpa.Namespace, hpa.Name, hpa.GetReference(), hpaUse, strconv.Itoa(hpa.Target), strconv.Itoa(hpa.MinPods), strconv.Itoa(hpa.MaxPods), strconv.Itoa(hpa.Replicas), strconv.Itoa(len(hpa.Pods)), strconv.Itoa(wp.GetRequestsMilliCPU()), strconv.Itoa(wp.GetTopMilliCPU()), fmt.Sprintf("%.2f", wp.GetUsageCPU()), strconv.Itoa(wp.GetRequestsMiMemory()), strconv.Itoa(wp.GetTopMiMemory()), fmt.Sprintf("%.2f", wp.GetUsageMemory()), strconv.Itoa(wp.GetLimitsMilliCPU()), strconv.Itoa(wp.GetLimitsMiMemory()), fmt.Sprintf("%s", wp.GetAvgStartupDuration()), strconv.Itoa(hpa.Pdb.Spec.MinAvailable), strconv.Itoa(hpa.Pdb.Spec.MaxUnavailable), hpa.CountLivenessProbes(), hpa.CountReadinessProbes(), hpa.CountLifecyclePreStop(), hpa.GetLivenessProbes(), hpa.GetReadinessProbes(), hpa.GetLifecyclePreStop()}
+			line := []string{hpa.Namespace, hpa.Name, hpa.GetReference(), hpaUse, strconv.Itoa(hpa.Target), strconv.Itoa(hpa.MinPods), strconv.Itoa(hpa.MaxPods), strconv.Itoa(hpa.Replicas), strconv.Itoa(len(hpa.Pods)), strconv.Itoa(wp.GetRequestsMilliCPU()), strconv.Itoa(wp.GetTopMilliCPU()), fmt.Sprintf("%.2f", wp.GetUsageCPU()), strconv.Itoa(wp.GetRequestsMiMemory()), strconv.Itoa(wp.GetTopMiMemory()), fmt.Sprintf("%.2f", wp.GetUsageMemory()), strconv.Itoa(wp.GetLimitsMilliCPU()), strconv.Itoa(wp.GetLimitsMiMemory()), fmt.Sprintf("%s", wp.GetAvgStartupDuration()), hpa.Pdb.Spec.MinAvailable.String(), hpa.Pdb.Spec.MaxUnavailable.String(), hpa.CountLivenessProbes(), hpa.CountReadinessProbes(), hpa.CountLifecyclePreStop(), hpa.GetLivenessProbes(), hpa.GetReadinessProbes(), hpa.GetLifecyclePreStop()}
 			err := writer.Write(line)
 			if err != nil {
 				log.Fatal(err)
@@ -236,7 +567,7 @@ func printNoHpaTab(deploymentWithoutHpa []Deployment, csvFilePrefix string, debu
 		}
 		for _, deploy := range deploymentWithoutHpa {
 			wp := Wrapper{Pods: deploy.Pods}
-			line := []string{deploy.Namespace, deploy.Name, strconv.Itoa(deploy.Replicas), strconv.Itoa(deploy.ReplicasExpected), strconv.Itoa(deploy.UpToDate), strconv.Itoa(deploy.Avaliable), deploy.Age, strconv.Itoa(len(deploy.Pods)), strconv.Itoa(wp.GetRequestsMilliCPU()), strconv.Itoa(wp.GetTopMilliCPU()), fmt.Sprintf("%.2f", wp.GetUsageCPU()), strconv.Itoa(wp.GetRequestsMiMemory()), strconv.Itoa(wp.GetTopMiMemory()), fmt.Sprintf("%.2f", wp.GetUsageMemory()), strconv.Itoa(wp.GetLimitsMilliCPU()), strconv.Itoa(wp.GetLimitsMiMemory()), fmt.Sprintf("%s", wp.GetAvgStartupDuration()), strconv.Itoa(deploy.Pdb.Spec.MinAvailable), strconv.Itoa(deploy.Pdb.Spec.MaxUnavailable), deploy.CountLivenessProbes(), deploy.CountReadinessProbes(), deploy.CountLifecyclePreStop(), deploy.GetLivenessProbes(), deploy.GetReadinessProbes(), deploy.GetLifecyclePreStop()}
+			line := []string{deploy.Namespace, deploy.Name, strconv.Itoa(deploy.Replicas), strconv.Itoa(deploy.ReplicasExpected), strconv.Itoa(deploy.UpToDate), strconv.Itoa(deploy.Avaliable), deploy.Age, strconv.Itoa(len(deploy.Pods)), strconv.Itoa(wp.GetRequestsMilliCPU()), strconv.Itoa(wp.GetTopMilliCPU()), fmt.Sprintf("%.2f", wp.GetUsageCPU()), strconv.Itoa(wp.GetRequestsMiMemory()), strconv.Itoa(wp.GetTopMiMemory()), fmt.Sprintf("%.2f", wp.GetUsageMemory()), strconv.Itoa(wp.GetLimitsMilliCPU()), strconv.Itoa(wp.GetLimitsMiMemory()), fmt.Sprintf("%s", wp.GetAvgStartupDuration()), deploy.Pdb.Spec.MinAvailable.String(), deploy.Pdb.Spec.MaxUnavailable.String(), deploy.CountLivenessProbes(), deploy.CountReadinessProbes(), deploy.CountLifecyclePreStop(), deploy.GetLivenessProbes(), deploy.GetReadinessProbes(), deploy.GetLifecyclePreStop()}
 			err := writer.Write(line)
 			if err != nil {
 				log.Fatal(err)
@@ -249,16 +580,17 @@ func printNodesTab(nodeList []Node, csvFilePrefix string, debug bool) {
 	allPods := Wrapper{Pods: []Pod{}}
 	if csvFilePrefix == "" || debug {
 		fmt.Println("\n\nNODEs SNAPSHOT:")
-		formatHeader := "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n"
-		formatValues := "%v\t%v\t%v\t%vm\t%vMi\t%v\t%vm\t%vm\t%0.2f%%\t%vMi\t%vMi\t%0.2f%%\t%vm\t%vMi\t%v\n"
+		formatHeader := "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v%v\n"
+		formatValues := "%v\t%v\t%v\t%vm\t%vMi\t%v\t%vm\t%vm\t%0.2f%%\t%vMi\t%vMi\t%0.2f%%\t%vm\t%vMi\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%vm\t%vMi\t%vm\t%vMi\t%vMi\t%vMi\t%vMi\t%v\t%vMi\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v%v\n"
 		tw := tabwriter.NewWriter(os.Stdout, 0, 1, 2, ' ', tabwriter.TabIndent)
-		fmt.Fprintf(tw, formatHeader, "Node", "Node Pool", "Allocatable Pods", "Allocatable CPU (m)", "Allocatable Memory (Mi)", "Actual Num Pods", "Requests CPU (m)", "TOP CPU (m)", "Usage Requests CPU (%)", "Requests Memory (Mi)", "TOP Memory (Mi)", "Usage Requests Memory (%)", "Limits CPU (m)", "Limitis Memory (Mi)", "Pod Startup Duration (AVG)")
-		fmt.Fprintf(tw, formatHeader, "----", "---------", "----------------", "-------------------", "-----------------------", "---------------", "----------------", "-----------", "----------------------", "--------------------", "---------------", "-------------------------", "--------------", "-------------------", "--------------------------")
+		fmt.Fprintf(tw, formatHeader, "Node", "Node Pool", "Allocatable Pods", "Allocatable CPU (m)", "Allocatable Memory (Mi)", "Actual Num Pods", "Requests CPU (m)", "TOP CPU (m)", "Usage Requests CPU (%)", "Requests Memory (Mi)", "TOP Memory (Mi)", "Usage Requests Memory (%)", "Limits CPU (m)", "Limitis Memory (Mi)", "Pod Startup Duration (AVG)", "Node TOP CPU (m)", "Node Usage CPU (%)", "Unexplained CPU (m)", "Node TOP Memory (Mi)", "Node Usage Memory (%)", "Unexplained Memory (Mi)", "Capacity CPU (m)", "Capacity Memory (Mi)", "Reserved CPU (m)", "Reserved Memory (Mi)", "Allocatable Ephemeral Storage (Mi)", "Requests Ephemeral Storage (Mi)", "Used Ephemeral Storage (Mi)", "Usage Requests Ephemeral Storage (%)", "Node Fs Used (Mi)", "GPU (Requests/Allocatable)", "Extended Resources", "Instance Type", "Zone", "Arch/OS", "Kubelet Version", "Age", "Unschedulable", "Taints", "Conditions", pricedColumns(costHeader("Node"), costHeader("Requests"), costHeader("Used"), costHeader("Wasted")))
+		fmt.Fprintf(tw, formatHeader, "----", "---------", "----------------", "-------------------", "-----------------------", "---------------", "----------------", "-----------", "----------------------", "--------------------", "---------------", "-------------------------", "--------------", "-------------------", "--------------------------", "----------------", "------------------", "-------------------", "--------------------", "---------------------", "-----------------------", "----------------", "--------------------", "----------------", "-------------------", "----------------------------------", "-------------------------------", "---------------------------", "------------------------------------", "-----------------", "--------------------------", "------------------", "-------------", "----", "-------", "---------------", "---", "-------------", "------", "----------", pricedColumns("---------------------", "-------------------------", "---------------------", "-------------------------"))
 		min := 999
 		max := 0
 		total := 0
 		allocatableMilliCPU := 0
 		allocatableMiMemory := 0
+		nodes := Nodepool{Nodes: nodeList}
 		for _, node := range nodeList {
 			nodeName := node.GetName()
 			pods := node.Pods
@@ -274,7 +606,7 @@ func printNodesTab(nodeList []Node, csvFilePrefix string, debug bool) {
 			allocatableMilliCPU += node.GetAllocatableMilliCPU()
 			allocatableMiMemory += node.GetAllocatableMiMemory()
 			w := Wrapper{Pods: pods}
-			fmt.Fprintf(tw, formatValues, nodeName, node.GetNodepool(), node.GetAllocatablePods(), node.GetAllocatableMilliCPU(), node.GetAllocatableMiMemory(), nPods, w.GetRequestsMilliCPU(), w.GetTopMilliCPU(), w.GetUsageCPU(), w.GetRequestsMiMemory(), w.GetTopMiMemory(), w.GetUsageMemory(), w.GetLimitsMilliCPU(), w.GetLimitsMiMemory(), w.GetAvgStartupDuration())
+			fmt.Fprintf(tw, formatValues, nodeName, node.GetNodepool(), node.GetAllocatablePods(), node.GetAllocatableMilliCPU(), node.GetAllocatableMiMemory(), nPods, w.GetRequestsMilliCPU(), w.GetTopMilliCPU(), w.GetUsageCPU(), w.GetRequestsMiMemory(), w.GetTopMiMemory(), w.GetUsageMemory(), w.GetLimitsMilliCPU(), w.GetLimitsMiMemory(), w.GetAvgStartupDuration(), formatNodeTop(node.GetTopMilliCPU(), "m", node.HasTop(), true), formatNodeTop(node.GetUsageCPU(), "%", node.HasTop(), true), formatNodeTop(node.GetUnexplainedMilliCPU(), "m", node.HasTop(), true), formatNodeTop(node.GetTopMiMemory(), "Mi", node.HasTop(), true), formatNodeTop(node.GetUsageMemory(), "%", node.HasTop(), true), formatNodeTop(node.GetUnexplainedMiMemory(), "Mi", node.HasTop(), true), node.GetCapacityMilliCPU(), node.GetCapacityMiMemory(), node.GetReservedMilliCPU(), node.GetReservedMiMemory(), node.GetAllocatableMiEphemeralStorage(), w.GetRequestsMiEphemeralStorage(), w.GetUsedMiEphemeralStorage(), formatEphemeralUsage(w.GetUsageEphemeralStorage(), w.HasStats(), true), node.GetUsedMiFs(), fmt.Sprintf("%d/%d", node.GetRequestsGpus(), node.GetAllocatableGpus()), node.GetExtendedResources(), node.GetInstanceType(), node.GetZone(), node.GetArch()+"/"+node.GetOS(), node.GetKubeletVersion(), node.GetAge(), node.IsUnschedulable(), node.GetTaints(), node.GetConditions(), pricedColumns(node.GetCost(), w.GetRequestsCost(), w.GetTopCost(), w.GetWasteCost()))
 		}
 		avg := 0
 		if len(nodeList) > 0 {
@@ -282,9 +614,9 @@ func printNodesTab(nodeList []Node, csvFilePrefix string, debug bool) {
 		} else {
 			min = 0
 		}
-		fmt.Fprintf(tw, formatHeader, " ", " ", " ", "-------------------", "-----------------------", "----------------", "----------------", "-----------", "----------------------", "--------------------", "---------------", "-------------------------", "--------------", "-------------------", "--------------------------")
+		fmt.Fprintf(tw, formatHeader, " ", " ", " ", "-------------------", "-----------------------", "----------------", "----------------", "-----------", "----------------------", "--------------------", "---------------", "-------------------------", "--------------", "-------------------", "--------------------------", "----------------", "------------------", "-------------------", "--------------------", "---------------------", "-----------------------", "----------------", "--------------------", "----------------", "-------------------", "----------------------------------", "-------------------------------", "---------------------------", "------------------------------------", "-----------------", "--------------------------", " ", " ", " ", " ", " ", " ", " ", " ", " ", pricedColumns("---------------------", "-------------------------", "---------------------", "-------------------------"))
 		summaryPods := fmt.Sprintf("Min:%d/Max:%d/Avg:%d", min, max, avg)
-		fmt.Fprintf(tw, formatValues, " ", " ", " ", allocatableMilliCPU, allocatableMiMemory, summaryPods, allPods.GetRequestsMilliCPU(), allPods.GetTopMilliCPU(), allPods.GetUsageCPU(), allPods.GetRequestsMiMemory(), allPods.GetTopMiMemory(), allPods.GetUsageMemory(), allPods.GetLimitsMilliCPU(), allPods.GetLimitsMiMemory(), "")
+		fmt.Fprintf(tw, formatValues, " ", " ", " ", allocatableMilliCPU, allocatableMiMemory, summaryPods, allPods.GetRequestsMilliCPU(), allPods.GetTopMilliCPU(), allPods.GetUsageCPU(), allPods.GetRequestsMiMemory(), allPods.GetTopMiMemory(), allPods.GetUsageMemory(), allPods.GetLimitsMilliCPU(), allPods.GetLimitsMiMemory(), "", formatNodeTop(nodes.GetTopMilliCPU(), "m", nodes.HasTop(), true), formatNodeTop(nodes.GetUsageCPU(), "%", nodes.HasTop(), true), formatNodeTop(nodes.GetUnexplainedMilliCPU(), "m", nodes.HasTop(), true), formatNodeTop(nodes.GetTopMiMemory(), "Mi", nodes.HasTop(), true), formatNodeTop(nodes.GetUsageMemory(), "%", nodes.HasTop(), true), formatNodeTop(nodes.GetUnexplainedMiMemory(), "Mi", nodes.HasTop(), true), nodes.GetCapacityMilliCPU(), nodes.GetCapacityMiMemory(), nodes.GetCapacityMilliCPU()-allocatableMilliCPU, nodes.GetCapacityMiMemory()-allocatableMiMemory, nodes.GetAllocatableMiEphemeralStorage(), allPods.GetRequestsMiEphemeralStorage(), allPods.GetUsedMiEphemeralStorage(), formatEphemeralUsage(allPods.GetUsageEphemeralStorage(), allPods.HasStats(), true), nodes.GetUsedMiFs(), fmt.Sprintf("%d/%d", nodes.GetRequestsGpus(), nodes.GetAllocatableGpus()), "", "", "", "", "", "", "", "", "", pricedColumns(nodes.GetCost(), allPods.GetRequestsCost(), allPods.GetTopCost(), allPods.GetWasteCost()))
 		tw.Flush()
 
 		if debug {
@@ -314,7 +646,7 @@ func printNodesTab(nodeList []Node, csvFilePrefix string, debug bool) {
 		writer := csv.NewWriter(file)
 		defer writer.Flush()
 
-		header := []string{"Node", "Node Pool", "Allocatable Pods", "Allocatable CPU (m)", "Allocatable Memory (Mi)", "Actual Num Pods", "Requests CPU (m)", "TOP CPU (m)", "Usage Requests CPU (%)", "Requests Memory (Mi)", "TOP Memory (Mi)", "Usage Requests Memory (%)", "Limits CPU (m)", "Limitis Memory (Mi)", "Pod Startup Duration (AVG)"}
+		header := append([]string{"Node", "Node Pool", "Allocatable Pods", "Allocatable CPU (m)", "Allocatable Memory (Mi)", "Actual Num Pods", "Requests CPU (m)", "TOP CPU (m)", "Usage Requests CPU (%)", "Requests Memory (Mi)", "TOP Memory (Mi)", "Usage Requests Memory (%)", "Limits CPU (m)", "Limitis Memory (Mi)", "Pod Startup Duration (AVG)", "Node TOP CPU (m)", "Node Usage CPU (%)", "Unexplained CPU (m)", "Node TOP Memory (Mi)", "Node Usage Memory (%)", "Unexplained Memory (Mi)", "Capacity CPU (m)", "Capacity Memory (Mi)", "Reserved CPU (m)", "Reserved Memory (Mi)", "Allocatable Ephemeral Storage (Mi)", "Requests Ephemeral Storage (Mi)", "Used Ephemeral Storage (Mi)", "Usage Requests Ephemeral Storage (%)", "Node Fs Used (Mi)", "GPU (Requests/Allocatable)", "Extended Resources", "Instance Type", "Zone", "Arch", "OS", "Kubelet Version", "Age", "Unschedulable", "Taints", "Ready", "MemoryPressure", "DiskPressure", "PIDPressure"}, pricedCells(costHeader("Node"), costHeader("Requests"), costHeader("Used"), costHeader("Wasted"))...)
 		err = writer.Write(header)
 		if err != nil {
 			log.Fatal(err)
@@ -324,7 +656,7 @@ func printNodesTab(nodeList []Node, csvFilePrefix string, debug bool) {
 			pods := node.Pods
 			nPods := len(pods)
 			w := Wrapper{Pods: pods}
-			line := []string{nodeName, node.GetNodepool(), strconv.Itoa(node.GetAllocatablePods()), strconv.Itoa(node.GetAllocatableMilliCPU()), strconv.Itoa(node.GetAllocatableMiMemory()), strconv.Itoa(nPods), strconv.Itoa(w.GetRequestsMilliCPU()), strconv.Itoa(w.GetTopMilliCPU()), fmt.Sprintf("%.2f", w.GetUsageCPU()), strconv.Itoa(w.GetRequestsMiMemory()), strconv.Itoa(w.GetTopMiMemory()), fmt.Sprintf("%.2f", w.GetUsageMemory()), strconv.Itoa(w.GetLimitsMilliCPU()), strconv.Itoa(w.GetLimitsMiMemory()), fmt.Sprintf("%s", w.GetAvgStartupDuration())}
+			line := append([]string{nodeName, node.GetNodepool(), strconv.Itoa(node.GetAllocatablePods()), strconv.Itoa(node.GetAllocatableMilliCPU()), strconv.Itoa(node.GetAllocatableMiMemory()), strconv.Itoa(nPods), strconv.Itoa(w.GetRequestsMilliCPU()), strconv.Itoa(w.GetTopMilliCPU()), fmt.Sprintf("%.2f", w.GetUsageCPU()), strconv.Itoa(w.GetRequestsMiMemory()), strconv.Itoa(w.GetTopMiMemory()), fmt.Sprintf("%.2f", w.GetUsageMemory()), strconv.Itoa(w.GetLimitsMilliCPU()), strconv.Itoa(w.GetLimitsMiMemory()), fmt.Sprintf("%s", w.GetAvgStartupDuration()), formatNodeTop(node.GetTopMilliCPU(), "", node.HasTop(), false), formatNodeTop(node.GetUsageCPU(), "", node.HasTop(), false), formatNodeTop(node.GetUnexplainedMilliCPU(), "", node.HasTop(), false), formatNodeTop(node.GetTopMiMemory(), "", node.HasTop(), false), formatNodeTop(node.GetUsageMemory(), "", node.HasTop(), false), formatNodeTop(node.GetUnexplainedMiMemory(), "", node.HasTop(), false), strconv.Itoa(node.GetCapacityMilliCPU()), strconv.Itoa(node.GetCapacityMiMemory()), strconv.Itoa(node.GetReservedMilliCPU()), strconv.Itoa(node.GetReservedMiMemory()), strconv.Itoa(node.GetAllocatableMiEphemeralStorage()), strconv.Itoa(w.GetRequestsMiEphemeralStorage()), strconv.Itoa(w.GetUsedMiEphemeralStorage()), formatEphemeralUsage(w.GetUsageEphemeralStorage(), w.HasStats(), false), strconv.Itoa(node.GetUsedMiFs()), strconv.Itoa(node.GetRequestsGpus()), strconv.Itoa(node.GetAllocatableGpus()), node.GetExtendedResources(), node.GetInstanceType(), node.GetZone(), node.GetArch(), node.GetOS(), node.GetKubeletVersion(), node.GetAge(), strconv.FormatBool(node.IsUnschedulable()), node.GetTaints(), node.GetCondition("Ready"), node.GetCondition("MemoryPressure"), node.GetCondition("DiskPressure"), node.GetCondition("PIDPressure")}, pricedCells(formatCost(node.GetCost()), formatCost(w.GetRequestsCost()), formatCost(w.GetTopCost()), formatCost(w.GetWasteCost()))...)
 			err := writer.Write(line)
 			if err != nil {
 				log.Fatal(err)
@@ -333,114 +665,965 @@ func printNodesTab(nodeList []Node, csvFilePrefix string, debug bool) {
 	}
 }
 
-// Wrapper contains a list of pods
-type Wrapper struct {
-	Pods []Pod
-}
-
-// GetRequestsMilliCPU total
-func (d Wrapper) GetRequestsMilliCPU() int {
-	total := 0
-	for _, p := range d.Pods {
-		total += p.GetRequestsMilliCPU()
+func printNodepoolsTab(nodepoolList []Nodepool, csvFilePrefix string, debug bool) {
+	if csvFilePrefix == "" || debug {
+		formatHeader := "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n"
+		formatValues := "%v\t%v\t%v\t%vm\t%vMi\t%v\t%v\t%0.1f\t%0.2f%%\t%vm\t%0.2f%%\t%vm\t%0.2f%%\t%vMi\t%0.2f%%\t%vMi\t%0.2f%%\t%v\t%0.2f\t%0.2f\t%0.2f\t%0.2f\n"
+		fmt.Println("\nNODE POOLs SNAPSHOT:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 1, 2, ' ', tabwriter.TabIndent)
+		fmt.Fprintf(w, formatHeader, "Node Pool", "Instance Types", "# Nodes", "Allocatable CPU (m)", "Allocatable Memory (Mi)", "Allocatable Pods", "# Pods", "Pods per Node (AVG)", "Pod Density (%)", "Requests CPU (m)", "Allocated CPU (%)", "TOP CPU (m)", "Usage Requests CPU (%)", "Requests Memory (Mi)", "Allocated Memory (%)", "TOP Memory (Mi)", "Usage Requests Memory (%)", "Pod Startup Duration (AVG)")
+		fmt.Fprintf(w, formatHeader, "---------", "--------------", "-------", "-------------------", "-----------------------", "----------------", "------", "-------------------", "---------------", "----------------", "-----------------", "-----------", "----------------------", "--------------------", "--------------------", "---------------", "-------------------------", "--------------------------")
+		allNodes := []Node{}
+		for _, np := range nodepoolList {
+			allNodes = append(allNodes, np.Nodes...)
+			wp := Wrapper{Pods: np.GetPods()}
+			fmt.Fprintf(w, formatValues, np.Name, np.GetInstanceTypes(), len(np.Nodes), np.GetAllocatableMilliCPU(), np.GetAllocatableMiMemory(), np.GetAllocatablePods(), len(wp.Pods), np.GetAvgPodsPerNode(), np.GetPodDensity(), wp.GetRequestsMilliCPU(), np.GetAllocatedCPU(), wp.GetTopMilliCPU(), wp.GetUsageCPU(), wp.GetRequestsMiMemory(), np.GetAllocatedMemory(), wp.GetTopMiMemory(), wp.GetUsageMemory(), wp.GetAvgStartupDuration())
+		}
+		all := Nodepool{Nodes: allNodes}
+		wp := Wrapper{Pods: all.GetPods()}
+		fmt.Fprintf(w, formatHeader, " ", " ", "-------", "-------------------", "-----------------------", "----------------", "------", "-------------------", "---------------", "----------------", "-----------------", "-----------", "----------------------", "--------------------", "--------------------", "---------------", "-------------------------", "--------------------------")
+		fmt.Fprintf(w, formatValues, " ", " ", len(all.Nodes), all.GetAllocatableMilliCPU(), all.GetAllocatableMiMemory(), all.GetAllocatablePods(), len(wp.Pods), all.GetAvgPodsPerNode(), all.GetPodDensity(), wp.GetRequestsMilliCPU(), all.GetAllocatedCPU(), wp.GetTopMilliCPU(), wp.GetUsageCPU(), wp.GetRequestsMiMemory(), all.GetAllocatedMemory(), wp.GetTopMiMemory(), wp.GetUsageMemory(), wp.GetAvgStartupDuration())
+		w.Flush()
 	}
-	return total
-}
 
-// GetTopMilliCPU total
-func (d Wrapper) GetTopMilliCPU() int {
-	total := 0
-	for _, p := range d.Pods {
-		total += p.Top.GetMilliCPU()
-	}
-	return total
-}
+	if csvFilePrefix != "" {
+		file, err := os.Create(csvFilePrefix + "-nodepools.csv")
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
 
-// GetUsageCPU % usage
-func (d Wrapper) GetUsageCPU() float32 {
-	requests, top := 0, 0
-	for _, p := range d.Pods {
-		requests += p.GetRequestsMilliCPU()
-		top += p.Top.GetMilliCPU()
-	}
-	if top == 0 && requests != 0 {
-		return float32(0)
-	} else if requests == 0 {
-		return float32(100)
-	}
-	return float32(top) / float32(requests) * 100
-}
+		writer := csv.NewWriter(file)
+		defer writer.Flush()
 
-// GetRequestsMiMemory total
-func (d Wrapper) GetRequestsMiMemory() int {
-	total := 0
-	for _, p := range d.Pods {
-		total += p.GetRequestsMiMemory()
+		header := []string{"Node Pool", "Instance Types", "# Nodes", "Allocatable CPU (m)", "Allocatable Memory (Mi)", "Allocatable Pods", "# Pods", "Pods per Node (AVG)", "Pod Density (%)", "Requests CPU (m)", "Allocated CPU (%)", "TOP CPU (m)", "Usage Requests CPU (%)", "Requests Memory (Mi)", "Allocated Memory (%)", "TOP Memory (Mi)", "Usage Requests Memory (%)", "Pod Startup Duration (AVG)"}
+		err = writer.Write(header)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, np := range nodepoolList {
+			wp := Wrapper{Pods: np.GetPods()}
+			line := []string{np.Name, np.GetInstanceTypes(), strconv.Itoa(len(np.Nodes)), strconv.Itoa(np.GetAllocatableMilliCPU()), strconv.Itoa(np.GetAllocatableMiMemory()), strconv.Itoa(np.GetAllocatablePods()), strconv.Itoa(len(wp.Pods)), fmt.Sprintf("%.1f", np.GetAvgPodsPerNode()), fmt.Sprintf("%.2f", np.GetPodDensity()), strconv.Itoa(wp.GetRequestsMilliCPU()), fmt.Sprintf("%.2f", np.GetAllocatedCPU()), strconv.Itoa(wp.GetTopMilliCPU()), fmt.Sprintf("%.2f", wp.GetUsageCPU()), strconv.Itoa(wp.GetRequestsMiMemory()), fmt.Sprintf("%.2f", np.GetAllocatedMemory()), strconv.Itoa(wp.GetTopMiMemory()), fmt.Sprintf("%.2f", wp.GetUsageMemory()), fmt.Sprintf("%s", wp.GetAvgStartupDuration())}
+			err := writer.Write(line)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
 	}
-	return total
 }
 
-// GetTopMiMemory total
-func (d Wrapper) GetTopMiMemory() int {
-	total := 0
-	for _, p := range d.Pods {
-		total += p.Top.GetMiMemory()
+func printEvictionTab(riskList []NodeEvictionRisk, csvFilePrefix string, debug bool) {
+	if csvFilePrefix == "" || debug {
+		formatHeader := "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n"
+		formatValues := "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%vMi\t%vMi\t%vMi\t%vMi\t%0.2f\t%0.2f\t%0.2f\t%0.2f\n"
+		fmt.Println("\nEVICTION RISK SNAPSHOT:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 1, 2, ' ', tabwriter.TabIndent)
+		fmt.Fprintf(w, formatHeader, "Node", "Node Memory Usage (%)", "Rank", "Namespace", "Pod Name", "QoS Class", "Priority Class", "Priority", "Requests Memory (Mi)", "Limitis Memory (Mi)", "TOP Memory (Mi)", "Above Requests Memory (Mi)")
+		fmt.Fprintf(w, formatHeader, "----", "---------------------", "----", "---------", "--------", "---------", "--------------", "--------", "--------------------", "-------------------", "---------------", "--------------------------")
+		for _, risk := range riskList {
+			node := fmt.Sprintf("%s (%d/%d pods above requests)", risk.Node.GetName(), risk.CountPodsAboveRequests(), len(risk.Pods))
+			usage := fmt.Sprintf("%0.2f%%", risk.GetUsageMemory())
+			for i, pod := range risk.Pods {
+				fmt.Fprintf(w, formatValues, node, usage, i+1, pod.Metadata.Namespace, pod.Metadata.Name, pod.GetQosClass(), pod.Spec.PriorityClassName, pod.GetPriority(), pod.GetRequestsMiMemory(), pod.GetLimitsMiMemory(), pod.GetTopMiMemory(), pod.GetMemoryAboveRequests())
+				node, usage = " ", " "
+			}
+		}
+		w.Flush()
 	}
-	return total
-}
 
-// GetUsageMemory % usage
-func (d Wrapper) GetUsageMemory() float32 {
-	requests, top := 0, 0
-	for _, p := range d.Pods {
-		requests += p.GetRequestsMiMemory()
-		top += p.Top.GetMiMemory()
-	}
-	if top == 0 && requests != 0 {
-		return float32(0)
-	} else if requests == 0 {
-		return float32(100)
-	}
-	return float32(top) / float32(requests) * 100
-}
+	if csvFilePrefix != "" {
+		file, err := os.Create(csvFilePrefix + "-eviction.csv")
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
 
-// GetLimitsMilliCPU total
-func (d Wrapper) GetLimitsMilliCPU() int {
-	total := 0
-	for _, p := range d.Pods {
-		total += p.GetLimitsMilliCPU()
-	}
-	return total
-}
+		writer := csv.NewWriter(file)
+		defer writer.Flush()
 
-// GetLimitsMiMemory total
-func (d Wrapper) GetLimitsMiMemory() int {
-	total := 0
-	for _, p := range d.Pods {
-		total += p.GetLimitsMiMemory()
+		header := []string{"Node", "Node Memory Usage (%)", "Node Pods Above Requests", "Rank", "Namespace", "Pod Name", "QoS Class", "Priority Class", "Priority", "Requests Memory (Mi)", "Limitis Memory (Mi)", "TOP Memory (Mi)", "Above Requests Memory (Mi)"}
+		err = writer.Write(header)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, risk := range riskList {
+			for i, pod := range risk.Pods {
+				line := []string{risk.Node.GetName(), fmt.Sprintf("%.2f", risk.GetUsageMemory()), strconv.Itoa(risk.CountPodsAboveRequests()), strconv.Itoa(i + 1), pod.Metadata.Namespace, pod.Metadata.Name, pod.GetQosClass(), pod.Spec.PriorityClassName, strconv.Itoa(pod.GetPriority()), strconv.Itoa(pod.GetRequestsMiMemory()), strconv.Itoa(pod.GetLimitsMiMemory()), strconv.Itoa(pod.GetTopMiMemory()), strconv.Itoa(pod.GetMemoryAboveRequests())}
+				err := writer.Write(line)
+				if err != nil {
+					log.Fatal(err)
+				}
+			}
+		}
 	}
-	return total
 }
 
-// GetAvgStartupDuration avg
-func (d Wrapper) GetAvgStartupDuration() time.Duration {
-	total := time.Duration(0)
-	count := 0
-	for _, p := range d.Pods {
-		d := p.GetStartupDuration()
-		if d != time.Duration(0) {
-			total = total + d
-			count = count + 1
+func printStartupTab(title string, name string, groups []StartupGroup, csvFilePrefix string, csvSuffix string, debug bool) {
+	if csvFilePrefix == "" || debug {
+		formatHeader := "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n"
+		fmt.Printf("\n%s:\n", title)
+		w := tabwriter.NewWriter(os.Stdout, 0, 1, 2, ' ', tabwriter.TabIndent)
+		fmt.Fprintf(w, formatHeader, name, "# Pods", "# Samples", "# Restarted", "Startup (P50)", "Startup (P90)", "Startup (MAX)", "Scheduled->Initialized (P50/P90)", "Initialized->ContainersReady (P50/P90)")
+		fmt.Fprintf(w, formatHeader, "----", "------", "---------", "-----------", "-------------", "-------------", "-------------", "--------------------------------", "--------------------------------------")
+		for _, g := range groups {
+			fmt.Fprintf(w, formatHeader, g.Name, len(g.Pods), g.CountSamples(), g.CountRestarted(), g.GetStartupPercentile(50), g.GetStartupPercentile(90), g.GetStartupPercentile(100), g.GetInitialization(), g.GetContainersReady())
 		}
+		w.Flush()
 	}
-	if count == 0 {
-		return time.Duration(0)
-	}
-	return time.Duration(int64(total) / int64(count))
-}
 
-func filterPod(podList []Pod, test func(Pod) bool) (ret []Pod) {
-	for _, pod := range podList {
+	if csvFilePrefix != "" {
+		file, err := os.Create(csvFilePrefix + "-" + csvSuffix + ".csv")
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+
+		writer := csv.NewWriter(file)
+		defer writer.Flush()
+
+		header := []string{name, "# Pods", "# Samples", "# Restarted", "Startup P50 (s)", "Startup P90 (s)", "Startup MAX (s)", "Scheduled->Initialized P50 (s)", "Scheduled->Initialized P90 (s)", "Initialized->ContainersReady P50 (s)", "Initialized->ContainersReady P90 (s)"}
+		err = writer.Write(header)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, g := range groups {
+			i50, c50 := g.GetPhasesPercentile(50)
+			i90, c90 := g.GetPhasesPercentile(90)
+			line := []string{g.Name, strconv.Itoa(len(g.Pods)), strconv.Itoa(g.CountSamples()), strconv.Itoa(g.CountRestarted()), fmt.Sprintf("%.1f", g.GetStartupPercentile(50).Seconds()), fmt.Sprintf("%.1f", g.GetStartupPercentile(90).Seconds()), fmt.Sprintf("%.1f", g.GetStartupPercentile(100).Seconds()), fmt.Sprintf("%.1f", i50.Seconds()), fmt.Sprintf("%.1f", i90.Seconds()), fmt.Sprintf("%.1f", c50.Seconds()), fmt.Sprintf("%.1f", c90.Seconds())}
+			err := writer.Write(line)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+}
+
+func printPullGroupsTab(title string, name string, groups []PullGroup, csvFilePrefix string, csvSuffix string, debug bool) {
+	if csvFilePrefix == "" || debug {
+		formatHeader := "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n"
+		formatValues := "%v\t%v\t%v\t%0.2f%%\t%v\t%v\t%v\t%v\t%vMi\n"
+		fmt.Printf("\n%s:\n", title)
+		w := tabwriter.NewWriter(os.Stdout, 0, 1, 2, ' ', tabwriter.TabIndent)
+		fmt.Fprintf(w, formatHeader, name, "# Pulls", "# Cache Hits", "Cache Hit (%)", "Pull (P50)", "Pull (P90)", "Pull (MAX)", "Total Pull Time", "Pulled (Mi)")
+		fmt.Fprintf(w, formatHeader, "----", "-------", "------------", "-------------", "----------", "----------", "----------", "---------------", "-----------")
+		for _, g := range groups {
+			fmt.Fprintf(w, formatValues, g.Name, g.CountPulls(), g.CountCacheHits(), g.GetCacheHitRatio(), g.GetPullPercentile(50), g.GetPullPercentile(90), g.GetPullPercentile(100), g.GetTotalPullTime(), g.GetPulledMiB())
+		}
+		w.Flush()
+	}
+
+	if csvFilePrefix != "" {
+		file, err := os.Create(csvFilePrefix + "-" + csvSuffix + ".csv")
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+
+		writer := csv.NewWriter(file)
+		defer writer.Flush()
+
+		header := []string{name, "# Pulls", "# Cache Hits", "Cache Hit (%)", "Pull P50 (s)", "Pull P90 (s)", "Pull MAX (s)", "Total Pull Time (s)", "Pulled (Mi)"}
+		err = writer.Write(header)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, g := range groups {
+			line := []string{g.Name, strconv.Itoa(g.CountPulls()), strconv.Itoa(g.CountCacheHits()), fmt.Sprintf("%.2f", g.GetCacheHitRatio()), fmt.Sprintf("%.1f", g.GetPullPercentile(50).Seconds()), fmt.Sprintf("%.1f", g.GetPullPercentile(90).Seconds()), fmt.Sprintf("%.1f", g.GetPullPercentile(100).Seconds()), fmt.Sprintf("%.1f", g.GetTotalPullTime().Seconds()), strconv.Itoa(g.GetPulledMiB())}
+			err := writer.Write(line)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+}
+
+func printProbesTab(workloadList []Workload, csvFilePrefix string, debug bool) {
+	if csvFilePrefix == "" || debug {
+		formatHeader := "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n"
+		fmt.Println("\nPROBEs SNAPSHOT:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 1, 2, ' ', tabwriter.TabIndent)
+		fmt.Fprintf(w, formatHeader, "Namespace", "Workload", "Container", "Liveness Probe", "Liveness (Delay/Period/Threshold/Timeout)", "Liveness Fires After", "Readiness Probe", "Readiness (Delay/Period/Threshold/Timeout)", "Startup Probe", "Startup (Delay/Period/Threshold/Timeout)", "Container Startup (P90)", "Container Startup (MAX)")
+		fmt.Fprintf(w, formatHeader, "---------", "--------", "---------", "--------------", "----------------------------------------", "--------------------", "---------------", "-----------------------------------------", "-------------", "---------------------------------------", "-----------------------", "-----------------------")
+		for _, workload := range workloadList {
+			if workload.Kind == "Node" {
+				continue
+			}
+			group := StartupGroup{Pods: workload.Pods}
+			_, p90 := group.GetPhasesPercentile(probeStartupPercentile)
+			_, max := group.GetPhasesPercentile(100)
+			for _, c := range workload.Pods[0].Spec.Containers {
+				fires := ""
+				if c.LivenessProbe.IsSet() {
+					fires = fmt.Sprintf("%ds", c.LivenessProbe.GetFailureSeconds())
+				}
+				fmt.Fprintf(w, formatHeader, workload.Namespace, workload.GetReference(), c.Name, c.LivenessProbe, c.LivenessProbe.GetTimings(), fires, c.ReadinessProbe, c.ReadinessProbe.GetTimings(), c.StartupProbe, c.StartupProbe.GetTimings(), p90, max)
+			}
+		}
+		w.Flush()
+	}
+
+	if csvFilePrefix != "" {
+		file, err := os.Create(csvFilePrefix + "-probes.csv")
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+
+		writer := csv.NewWriter(file)
+		defer writer.Flush()
+
+		header := []string{"Namespace", "Workload", "Container", "Liveness Probe", "Liveness (Delay/Period/Threshold/Timeout)", "Liveness Fires After (s)", "Readiness Probe", "Readiness (Delay/Period/Threshold/Timeout)", "Startup Probe", "Startup (Delay/Period/Threshold/Timeout)", "Container Startup (P90)", "Container Startup (MAX)"}
+		err = writer.Write(header)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, workload := range workloadList {
+			if workload.Kind == "Node" {
+				continue
+			}
+			group := StartupGroup{Pods: workload.Pods}
+			_, p90 := group.GetPhasesPercentile(probeStartupPercentile)
+			_, max := group.GetPhasesPercentile(100)
+			for _, c := range workload.Pods[0].Spec.Containers {
+				fires := ""
+				if c.LivenessProbe.IsSet() {
+					fires = strconv.Itoa(c.LivenessProbe.GetFailureSeconds())
+				}
+				line := []string{workload.Namespace, workload.GetReference(), c.Name, c.LivenessProbe.String(), c.LivenessProbe.GetTimings(), fires, c.ReadinessProbe.String(), c.ReadinessProbe.GetTimings(), c.StartupProbe.String(), c.StartupProbe.GetTimings(), fmt.Sprintf("%s", p90), fmt.Sprintf("%s", max)}
+				err := writer.Write(line)
+				if err != nil {
+					log.Fatal(err)
+				}
+			}
+		}
+	}
+}
+
+func printPdbsTab(reportList []PdbReport, csvFilePrefix string, debug bool) {
+	if csvFilePrefix == "" || debug {
+		formatHeader := "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n"
+		fmt.Println("\nPDBs SNAPSHOT:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 1, 2, ' ', tabwriter.TabIndent)
+		fmt.Fprintf(w, formatHeader, "Namespace", "PDB Name", "Selector", "Min Available", "Max Unavailable", "# Pods", "Workloads", "Expected Pods", "Current Healthy", "Desired Healthy", "Disruptions Allowed", "Other PDBs")
+		fmt.Fprintf(w, formatHeader, "---------", "--------", "--------", "-------------", "---------------", "------", "---------", "-------------", "---------------", "---------------", "-------------------", "----------")
+		for _, r := range reportList {
+			pdb := r.Pdb
+			fmt.Fprintf(w, formatHeader, pdb.Metadata.Namespace, pdb.Metadata.Name, pdb.GetSelector(), pdb.Spec.MinAvailable, pdb.Spec.MaxUnavailable, len(r.Pods), strings.Join(r.Workloads, ","), pdb.Status.ExpectedPods, pdb.Status.CurrentHealthy, pdb.Status.DesiredHealthy, pdb.Status.DisruptionsAllowed, strings.Join(r.Overlaps, ","))
+		}
+		w.Flush()
+	}
+
+	if csvFilePrefix != "" {
+		file, err := os.Create(csvFilePrefix + "-pdbs.csv")
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+
+		writer := csv.NewWriter(file)
+		defer writer.Flush()
+
+		header := []string{"Namespace", "PDB Name", "Selector", "Min Available", "Max Unavailable", "# Pods", "Workloads", "Expected Pods", "Current Healthy", "Desired Healthy", "Disruptions Allowed", "Other PDBs"}
+		err = writer.Write(header)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, r := range reportList {
+			pdb := r.Pdb
+			line := []string{pdb.Metadata.Namespace, pdb.Metadata.Name, pdb.GetSelector(), pdb.Spec.MinAvailable.String(), pdb.Spec.MaxUnavailable.String(), strconv.Itoa(len(r.Pods)), strings.Join(r.Workloads, ","), strconv.Itoa(pdb.Status.ExpectedPods), strconv.Itoa(pdb.Status.CurrentHealthy), strconv.Itoa(pdb.Status.DesiredHealthy), strconv.Itoa(pdb.Status.DisruptionsAllowed), strings.Join(r.Overlaps, ",")}
+			err := writer.Write(line)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+}
+
+func printNamespacesTab(namespaceList []NamespaceSummary, csvFilePrefix string, debug bool) {
+	if csvFilePrefix == "" || debug {
+		formatHeader := "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v%v\n"
+		formatValues := "%v\t%v\t%v\t%vm\t%vm\t%0.2f%%\t%vm\t%vMi\t%vMi\t%0.2f%%\t%vMi\t%vm\t%vMi\t%v\t%v\t%v\t%v%v\n"
+		fmt.Println("\nNAMESPACEs SNAPSHOT:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 1, 2, ' ', tabwriter.TabIndent)
+		fmt.Fprintf(w, formatHeader, "Namespace", "# Pods", "# Workloads", "Requests CPU (m)", "TOP CPU (m)", "Usage CPU (%)", "Waste CPU (m)", "Requests Memory (Mi)", "TOP Memory (Mi)", "Usage Memory (%)", "Waste Memory (Mi)", "Limits CPU (m)", "Limitis Memory (Mi)", "Workloads Without PDB", "Workloads Without Probes", "Pods Without Requests", "Pod Startup Duration (AVG)", pricedColumns(costHeader("Requests"), costHeader("Used"), costHeader("Wasted")))
+		fmt.Fprintf(w, formatHeader, "---------", "------", "-----------", "----------------", "-----------", "-------------", "-------------", "--------------------", "---------------", "----------------", "-----------------", "--------------", "-------------------", "---------------------", "------------------------", "---------------------", "--------------------------", pricedColumns("-------------------------", "---------------------", "-------------------------"))
+		allPods := Wrapper{Pods: []Pod{}}
+		workloads, withoutPdb, withoutProbes, withoutRequests := 0, 0, 0, 0
+		for _, ns := range namespaceList {
+			wp := Wrapper{Pods: ns.Pods}
+			allPods.Pods = append(allPods.Pods, ns.Pods...)
+			workloads += len(ns.Workloads)
+			withoutPdb += ns.CountWorkloadsWithoutPdb()
+			withoutProbes += ns.CountWorkloadsWithoutProbes()
+			withoutRequests += ns.CountPodsWithoutRequests()
+			fmt.Fprintf(w, formatValues, ns.Name, len(ns.Pods), len(ns.Workloads), wp.GetRequestsMilliCPU(), wp.GetTopMilliCPU(), wp.GetUsageCPU(), ns.GetWasteMilliCPU(), wp.GetRequestsMiMemory(), wp.GetTopMiMemory(), wp.GetUsageMemory(), ns.GetWasteMiMemory(), wp.GetLimitsMilliCPU(), wp.GetLimitsMiMemory(), ns.CountWorkloadsWithoutPdb(), ns.CountWorkloadsWithoutProbes(), ns.CountPodsWithoutRequests(), wp.GetAvgStartupDuration(), pricedColumns(wp.GetRequestsCost(), wp.GetTopCost(), wp.GetWasteCost()))
+		}
+		fmt.Fprintf(w, formatHeader, " ", "------", "-----------", "----------------", "-----------", "-------------", "-------------", "--------------------", "---------------", "----------------", "-----------------", "--------------", "-------------------", "---------------------", "------------------------", "---------------------", "--------------------------", pricedColumns("-------------------------", "---------------------", "-------------------------"))
+		fmt.Fprintf(w, formatValues, " ", len(allPods.Pods), workloads, allPods.GetRequestsMilliCPU(), allPods.GetTopMilliCPU(), allPods.GetUsageCPU(), allPods.GetRequestsMilliCPU()-allPods.GetTopMilliCPU(), allPods.GetRequestsMiMemory(), allPods.GetTopMiMemory(), allPods.GetUsageMemory(), allPods.GetRequestsMiMemory()-allPods.GetTopMiMemory(), allPods.GetLimitsMilliCPU(), allPods.GetLimitsMiMemory(), withoutPdb, withoutProbes, withoutRequests, allPods.GetAvgStartupDuration(), pricedColumns(allPods.GetRequestsCost(), allPods.GetTopCost(), allPods.GetWasteCost()))
+		w.Flush()
+	}
+
+	if csvFilePrefix != "" {
+		file, err := os.Create(csvFilePrefix + "-namespaces.csv")
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+
+		writer := csv.NewWriter(file)
+		defer writer.Flush()
+
+		header := append([]string{"Namespace", "# Pods", "# Workloads", "Requests CPU (m)", "TOP CPU (m)", "Usage CPU (%)", "Waste CPU (m)", "Requests Memory (Mi)", "TOP Memory (Mi)", "Usage Memory (%)", "Waste Memory (Mi)", "Limits CPU (m)", "Limitis Memory (Mi)", "Workloads Without PDB", "Workloads Without Probes", "Pods Without Requests", "Pod Startup Duration (AVG)"}, pricedCells(costHeader("Requests"), costHeader("Used"), costHeader("Wasted"))...)
+		err = writer.Write(header)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, ns := range namespaceList {
+			wp := Wrapper{Pods: ns.Pods}
+			line := append([]string{ns.Name, strconv.Itoa(len(ns.Pods)), strconv.Itoa(len(ns.Workloads)), strconv.Itoa(wp.GetRequestsMilliCPU()), strconv.Itoa(wp.GetTopMilliCPU()), fmt.Sprintf("%.2f", wp.GetUsageCPU()), strconv.Itoa(ns.GetWasteMilliCPU()), strconv.Itoa(wp.GetRequestsMiMemory()), strconv.Itoa(wp.GetTopMiMemory()), fmt.Sprintf("%.2f", wp.GetUsageMemory()), strconv.Itoa(ns.GetWasteMiMemory()), strconv.Itoa(wp.GetLimitsMilliCPU()), strconv.Itoa(wp.GetLimitsMiMemory()), strconv.Itoa(ns.CountWorkloadsWithoutPdb()), strconv.Itoa(ns.CountWorkloadsWithoutProbes()), strconv.Itoa(ns.CountPodsWithoutRequests()), fmt.Sprintf("%s", wp.GetAvgStartupDuration())}, pricedCells(formatCost(wp.GetRequestsCost()), formatCost(wp.GetTopCost()), formatCost(wp.GetWasteCost()))...)
+			err := writer.Write(line)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+}
+
+func printLabelGroupsTab(labels []string, groups []LabelGroup, csvFilePrefix string, debug bool) {
+	header := append(append(append([]string{}, labels...), "# Namespaces", "# Pods", "Requests CPU (m)", "TOP CPU (m)", "Usage CPU (%)", "Waste CPU (m)", "Requests Memory (Mi)", "TOP Memory (Mi)", "Usage Memory (%)", "Waste Memory (Mi)"), pricedCells(costHeader("Requests"), costHeader("Used"), costHeader("Wasted"))...)
+	if csvFilePrefix == "" || debug {
+		fmt.Printf("\nSHOWBACK BY %s:\n", strings.ToUpper(strings.Join(labels, ", ")))
+		w := tabwriter.NewWriter(os.Stdout, 0, 1, 2, ' ', tabwriter.TabIndent)
+		separator := []string{}
+		for _, h := range header {
+			separator = append(separator, strings.Repeat("-", len(h)))
+		}
+		fmt.Fprintln(w, strings.Join(header, "\t"))
+		fmt.Fprintln(w, strings.Join(separator, "\t"))
+		allPods := Wrapper{Pods: []Pod{}}
+		for _, g := range groups {
+			wp := Wrapper{Pods: g.Pods}
+			allPods.Pods = append(allPods.Pods, g.Pods...)
+			values := append(append(append([]string{}, g.Values...), strconv.Itoa(g.CountNamespaces()), strconv.Itoa(len(g.Pods)), fmt.Sprintf("%vm", wp.GetRequestsMilliCPU()), fmt.Sprintf("%vm", wp.GetTopMilliCPU()), fmt.Sprintf("%0.2f%%", wp.GetUsageCPU()), fmt.Sprintf("%vm", g.GetWasteMilliCPU()), fmt.Sprintf("%vMi", wp.GetRequestsMiMemory()), fmt.Sprintf("%vMi", wp.GetTopMiMemory()), fmt.Sprintf("%0.2f%%", wp.GetUsageMemory()), fmt.Sprintf("%vMi", g.GetWasteMiMemory())), pricedCells(formatCost(wp.GetRequestsCost()), formatCost(wp.GetTopCost()), formatCost(wp.GetWasteCost()))...)
+			fmt.Fprintln(w, strings.Join(values, "\t"))
+		}
+		totals := []string{}
+		for range labels {
+			totals = append(totals, " ")
+		}
+		fmt.Fprintln(w, strings.Join(append(append([]string{}, totals...), separator[len(labels):]...), "\t"))
+		totals = append(append(totals, " ", strconv.Itoa(len(allPods.Pods)), fmt.Sprintf("%vm", allPods.GetRequestsMilliCPU()), fmt.Sprintf("%vm", allPods.GetTopMilliCPU()), fmt.Sprintf("%0.2f%%", allPods.GetUsageCPU()), fmt.Sprintf("%vm", allPods.GetRequestsMilliCPU()-allPods.GetTopMilliCPU()), fmt.Sprintf("%vMi", allPods.GetRequestsMiMemory()), fmt.Sprintf("%vMi", allPods.GetTopMiMemory()), fmt.Sprintf("%0.2f%%", allPods.GetUsageMemory()), fmt.Sprintf("%vMi", allPods.GetRequestsMiMemory()-allPods.GetTopMiMemory())), pricedCells(formatCost(allPods.GetRequestsCost()), formatCost(allPods.GetTopCost()), formatCost(allPods.GetWasteCost()))...)
+		fmt.Fprintln(w, strings.Join(totals, "\t"))
+		w.Flush()
+	}
+
+	if csvFilePrefix != "" {
+		file, err := os.Create(csvFilePrefix + "-showback.csv")
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+
+		writer := csv.NewWriter(file)
+		defer writer.Flush()
+
+		err = writer.Write(header)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, g := range groups {
+			wp := Wrapper{Pods: g.Pods}
+			line := append(append(append([]string{}, g.Values...), strconv.Itoa(g.CountNamespaces()), strconv.Itoa(len(g.Pods)), strconv.Itoa(wp.GetRequestsMilliCPU()), strconv.Itoa(wp.GetTopMilliCPU()), fmt.Sprintf("%.2f", wp.GetUsageCPU()), strconv.Itoa(g.GetWasteMilliCPU()), strconv.Itoa(wp.GetRequestsMiMemory()), strconv.Itoa(wp.GetTopMiMemory()), fmt.Sprintf("%.2f", wp.GetUsageMemory()), strconv.Itoa(g.GetWasteMiMemory())), pricedCells(formatCost(wp.GetRequestsCost()), formatCost(wp.GetTopCost()), formatCost(wp.GetWasteCost()))...)
+			err := writer.Write(line)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+}
+
+func printGpusTab(nodeList []Node, workloadList []Workload, csvFilePrefix string, debug bool) {
+	if csvFilePrefix == "" || debug {
+		formatHeader := "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n"
+		formatValues := "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n"
+		fmt.Println("\nGPU NODEs SNAPSHOT:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 1, 2, ' ', tabwriter.TabIndent)
+		fmt.Fprintf(w, formatHeader, "Node", "Node Pool", "Instance Type", "Allocatable GPU", "Requests GPU", "Idle GPU", "Extended Resources", "Pods Holding GPU")
+		fmt.Fprintf(w, formatHeader, "----", "---------", "-------------", "---------------", "------------", "--------", "------------------", "----------------")
+		for _, node := range nodeList {
+			pods := []string{}
+			for _, pod := range node.Pods {
+				if pod.GetRequestsGpus() > 0 {
+					pods = append(pods, pod.Metadata.Namespace+"/"+pod.Metadata.Name)
+				}
+			}
+			fmt.Fprintf(w, formatValues, node.GetName(), node.GetNodepool(), node.GetInstanceType(), node.GetAllocatableGpus(), node.GetRequestsGpus(), node.GetIdleGpus(), node.GetExtendedResources(), strings.Join(pods, ","))
+		}
+		w.Flush()
+
+		formatHeader = "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n"
+		formatValues = "%v\t%v\t%v\t%v\t%v\t%vm\t%vm\t%0.2f%%\t%v\n"
+		fmt.Println("\nGPU WORKLOADs SNAPSHOT:")
+		w = tabwriter.NewWriter(os.Stdout, 0, 1, 2, ' ', tabwriter.TabIndent)
+		fmt.Fprintf(w, formatHeader, "Namespace", "Workload", "# Pods", "Requests GPU", "Extended Resources (per pod)", "Requests CPU (m)", "TOP CPU (m)", "Usage CPU (%)", "Nodes")
+		fmt.Fprintf(w, formatHeader, "---------", "--------", "------", "------------", "----------------------------", "----------------", "-----------", "-------------", "-----")
+		for _, workload := range workloadList {
+			wp := Wrapper{Pods: workload.Pods}
+			fmt.Fprintf(w, formatValues, workload.Namespace, workload.GetReference(), len(workload.Pods), workload.GetRequestsGpus(), workload.GetExtendedResources(), wp.GetRequestsMilliCPU(), wp.GetTopMilliCPU(), wp.GetUsageCPU(), strings.Join(workload.GetNodeNames(), ","))
+		}
+		w.Flush()
+	}
+
+	if csvFilePrefix != "" {
+		file, err := os.Create(csvFilePrefix + "-gpu-nodes.csv")
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+
+		writer := csv.NewWriter(file)
+		defer writer.Flush()
+
+		header := []string{"Node", "Node Pool", "Instance Type", "Allocatable GPU", "Requests GPU", "Idle GPU", "Extended Resources"}
+		err = writer.Write(header)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, node := range nodeList {
+			line := []string{node.GetName(), node.GetNodepool(), node.GetInstanceType(), strconv.Itoa(node.GetAllocatableGpus()), strconv.Itoa(node.GetRequestsGpus()), strconv.Itoa(node.GetIdleGpus()), node.GetExtendedResources()}
+			err := writer.Write(line)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		wfile, err := os.Create(csvFilePrefix + "-gpu-workloads.csv")
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer wfile.Close()
+
+		wwriter := csv.NewWriter(wfile)
+		defer wwriter.Flush()
+
+		header = []string{"Namespace", "Kind", "Name", "# Pods", "Requests GPU", "Extended Resources (per pod)", "Requests CPU (m)", "TOP CPU (m)", "Usage CPU (%)", "Nodes"}
+		err = wwriter.Write(header)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, workload := range workloadList {
+			wp := Wrapper{Pods: workload.Pods}
+			line := []string{workload.Namespace, workload.Kind, workload.Name, strconv.Itoa(len(workload.Pods)), strconv.Itoa(workload.GetRequestsGpus()), workload.GetExtendedResources(), strconv.Itoa(wp.GetRequestsMilliCPU()), strconv.Itoa(wp.GetTopMilliCPU()), fmt.Sprintf("%.2f", wp.GetUsageCPU()), strings.Join(workload.GetNodeNames(), ",")}
+			err := wwriter.Write(line)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+}
+
+func printConsolidationTab(consolidations []NodeConsolidation, nodepools []NodepoolConsolidation, csvFilePrefix string, debug bool) {
+	if csvFilePrefix == "" || debug {
+		formatHeader := "%v\t%v\t%v\t%v\t%v\t%v\t%v\n"
+		formatValues := "%v\t%v\t%0.2f%%\t%0.2f%%\t%v\t%v\t%v\n"
+		fmt.Println("\nCONSOLIDATION SIMULATION (NODEs):")
+		w := tabwriter.NewWriter(os.Stdout, 0, 1, 2, ' ', tabwriter.TabIndent)
+		fmt.Fprintf(w, formatHeader, "Node", "Node Pool", "Allocated CPU (%)", "Allocated Memory (%)", "Pods to Move", "Removable", "Targets / Reason")
+		fmt.Fprintf(w, formatHeader, "----", "---------", "-----------------", "--------------------", "------------", "---------", "----------------")
+		for _, c := range consolidations {
+			detail := c.Reason
+			if c.Removable {
+				detail = c.GetTargets()
+			}
+			fmt.Fprintf(w, formatValues, c.Node.GetName(), c.Node.GetNodepool(), c.GetAllocatedCPU(), c.GetAllocatedMemory(), len(c.Moves), c.Removable, detail)
+		}
+		w.Flush()
+
+		formatHeader = "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n"
+		formatValues = "%v\t%v\t%v\t%v\t%vm\t%vMi\t%0.2f%%\t%0.2f%%\t%v\n"
+		fmt.Println("\nCONSOLIDATION SIMULATION (NODE POOLs):")
+		w = tabwriter.NewWriter(os.Stdout, 0, 1, 2, ' ', tabwriter.TabIndent)
+		fmt.Fprintf(w, formatHeader, "Node Pool", "# Nodes", "Removable Nodes", "Nodes After", "Freed Allocatable CPU (m)", "Freed Allocatable Memory (Mi)", "Allocated CPU After (%)", "Allocated Memory After (%)", "Instance Types")
+		fmt.Fprintf(w, formatHeader, "---------", "-------", "---------------", "-----------", "-------------------------", "-----------------------------", "-----------------------", "--------------------------", "--------------")
+		for _, np := range nodepools {
+			fmt.Fprintf(w, formatValues, np.Name, len(np.Nodes), np.CountRemovable(), len(np.Nodes)-np.CountRemovable(), np.GetFreedAllocatableMilliCPU(), np.GetFreedAllocatableMiMemory(), np.GetAllocatedCPUAfter(), np.GetAllocatedMemoryAfter(), np.GetInstanceTypes())
+		}
+		w.Flush()
+	}
+
+	if csvFilePrefix != "" {
+		file, err := os.Create(csvFilePrefix + "-consolidation-nodes.csv")
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+
+		writer := csv.NewWriter(file)
+		defer writer.Flush()
+
+		header := []string{"Node", "Node Pool", "Allocated CPU (%)", "Allocated Memory (%)", "Pods to Move", "Removable", "Targets", "Reason"}
+		err = writer.Write(header)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, c := range consolidations {
+			line := []string{c.Node.GetName(), c.Node.GetNodepool(), fmt.Sprintf("%.2f", c.GetAllocatedCPU()), fmt.Sprintf("%.2f", c.GetAllocatedMemory()), strconv.Itoa(len(c.Moves)), strconv.FormatBool(c.Removable), c.GetTargets(), c.Reason}
+			err := writer.Write(line)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		npfile, err := os.Create(csvFilePrefix + "-consolidation-nodepools.csv")
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer npfile.Close()
+
+		npwriter := csv.NewWriter(npfile)
+		defer npwriter.Flush()
+
+		header = []string{"Node Pool", "# Nodes", "Removable Nodes", "Nodes After", "Freed Allocatable CPU (m)", "Freed Allocatable Memory (Mi)", "Allocated CPU After (%)", "Allocated Memory After (%)", "Instance Types"}
+		err = npwriter.Write(header)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, np := range nodepools {
+			line := []string{np.Name, strconv.Itoa(len(np.Nodes)), strconv.Itoa(np.CountRemovable()), strconv.Itoa(len(np.Nodes) - np.CountRemovable()), strconv.Itoa(np.GetFreedAllocatableMilliCPU()), strconv.Itoa(np.GetFreedAllocatableMiMemory()), fmt.Sprintf("%.2f", np.GetAllocatedCPUAfter()), fmt.Sprintf("%.2f", np.GetAllocatedMemoryAfter()), np.GetInstanceTypes()}
+			err := npwriter.Write(line)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+}
+
+func printDrainPlanTab(plan []NodeDrain, pdbList []Pdb, csvFilePrefix string, debug bool) {
+	if csvFilePrefix == "" || debug {
+		formatHeader := "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n"
+		fmt.Println("\nDRAIN PLAN:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 1, 2, ' ', tabwriter.TabIndent)
+		fmt.Fprintf(w, formatHeader, "Wave", "Node", "Node Pool", "Status", "Pods to Evict", "PDBs (Evictions/Allowed)", "Pods Lost (no controller)", "Local Storage Pods", "Blockers")
+		fmt.Fprintf(w, formatHeader, "----", "----", "---------", "------", "-------------", "------------------------", "-------------------------", "------------------", "--------")
+		for _, d := range plan {
+			fmt.Fprintf(w, formatHeader, d.GetWave(), d.Node.GetName(), d.Node.GetNodepool(), d.GetStatus(), len(d.Evicted), d.GetPdbs(pdbList), getPodNames(d.Unmanaged), getPodNames(d.LocalStorage), strings.Join(d.Blockers, ","))
+		}
+		w.Flush()
+	}
+
+	if csvFilePrefix != "" {
+		file, err := os.Create(csvFilePrefix + "-drain-plan.csv")
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+
+		writer := csv.NewWriter(file)
+		defer writer.Flush()
+
+		header := []string{"Wave", "Node", "Node Pool", "Status", "Pods to Evict", "Evicted Pods", "PDBs (Evictions/Allowed)", "Pods Lost (no controller)", "Local Storage Pods", "Blockers"}
+		err = writer.Write(header)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, d := range plan {
+			line := []string{strconv.Itoa(d.Wave), d.Node.GetName(), d.Node.GetNodepool(), d.GetStatus(), strconv.Itoa(len(d.Evicted)), getPodNames(d.Evicted), d.GetPdbs(pdbList), getPodNames(d.Unmanaged), getPodNames(d.LocalStorage), strings.Join(d.Blockers, ",")}
+			err := writer.Write(line)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+}
+
+func printRecommendTab(recommendations []WorkloadRecommendation, opts RecommendOptions, csvFilePrefix string, debug bool) {
+	observed := fmt.Sprintf("P%d", opts.Percentile)
+	if csvFilePrefix == "" || debug {
+		formatHeader := "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n"
+		formatValues := "%v\t%v\t%v\t%vm\t%vm\t%vm\t%vMi\t%vMi\t%vMi\t%v\n"
+		fmt.Printf("\nRECOMMENDATIONs (WORKLOADs, %s + %d%% headroom):\n", observed, opts.Headroom)
+		w := tabwriter.NewWriter(os.Stdout, 0, 1, 2, ' ', tabwriter.TabIndent)
+		fmt.Fprintf(w, formatHeader, "Namespace", "Workload", "# Pods", "Requests CPU (m)", "Recommended CPU (m)", "Delta CPU (m)", "Requests Memory (Mi)", "Recommended Memory (Mi)", "Delta Memory (Mi)", "Confidence")
+		fmt.Fprintf(w, formatHeader, "---------", "--------", "------", "----------------", "-------------------", "-------------", "--------------------", "-----------------------", "-----------------", "----------")
+		deltaMilliCPU, deltaMiMemory := 0, 0
+		for _, r := range recommendations {
+			deltaMilliCPU += r.GetDeltaMilliCPU()
+			deltaMiMemory += r.GetDeltaMiMemory()
+			fmt.Fprintf(w, formatValues, r.Workload.Namespace, r.Workload.GetReference(), len(r.Workload.Pods), r.GetRequestsMilliCPU(), r.GetRecommendedRequestsMilliCPU(), r.GetDeltaMilliCPU(), r.GetRequestsMiMemory(), r.GetRecommendedRequestsMiMemory(), r.GetDeltaMiMemory(), r.GetConfidence())
+		}
+		fmt.Fprintf(w, formatHeader, " ", " ", " ", " ", " ", "-------------", " ", " ", "-----------------", " ")
+		fmt.Fprintf(w, formatHeader, " ", " ", " ", " ", " ", fmt.Sprintf("%dm", deltaMilliCPU), " ", " ", fmt.Sprintf("%dMi", deltaMiMemory), " ")
+		w.Flush()
+
+		formatHeader = "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n"
+		formatValues = "%v\t%v\t%v\t%v\t%v\t%vm\t%vm\t%vm\t%vm\t%vm\t%vMi\t%vMi\t%vMi\t%vMi\t%vMi\n"
+		fmt.Println("\nRECOMMENDATIONs (CONTAINERs):")
+		w = tabwriter.NewWriter(os.Stdout, 0, 1, 2, ' ', tabwriter.TabIndent)
+		fmt.Fprintf(w, formatHeader, "Namespace", "Workload", "Container", "Source (# Samples)", "Confidence", "Requests CPU (m)", observed+" CPU (m)", "Recommended Requests CPU (m)", "Limits CPU (m)", "Recommended Limits CPU (m)", "Requests Memory (Mi)", observed+" Memory (Mi)", "Recommended Requests Memory (Mi)", "Limitis Memory (Mi)", "Recommended Limits Memory (Mi)")
+		fmt.Fprintf(w, formatHeader, "---------", "--------", "---------", "------------------", "----------", "----------------", "-------------", "----------------------------", "--------------", "--------------------------", "--------------------", "----------------", "--------------------------------", "-------------------", "------------------------------")
+		for _, r := range recommendations {
+			for _, c := range r.Containers {
+				current := c.Spec.Resources
+				source := fmt.Sprintf("%s (%d)", c.Usage.Source, len(c.Usage.MilliCPU))
+				fmt.Fprintf(w, formatValues, r.Workload.Namespace, r.Workload.GetReference(), c.Spec.Name, source, c.GetConfidence(), current.Requests.GetMilliCPU(), c.ObservedMilliCPU, c.RequestsMilliCPU, current.Limits.GetMilliCPU(), c.LimitsMilliCPU, current.Requests.GetMiMemory(), c.ObservedMiMemory, c.RequestsMiMemory, current.Limits.GetMiMemory(), c.LimitsMiMemory)
+			}
+		}
+		w.Flush()
+	}
+
+	if csvFilePrefix != "" {
+		file, err := os.Create(csvFilePrefix + "-recommend-workloads.csv")
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+
+		writer := csv.NewWriter(file)
+		defer writer.Flush()
+
+		header := []string{"Namespace", "Kind", "Name", "# Pods", "Requests CPU (m)", "Recommended CPU (m)", "Delta CPU (m)", "Requests Memory (Mi)", "Recommended Memory (Mi)", "Delta Memory (Mi)", "Confidence"}
+		err = writer.Write(header)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, r := range recommendations {
+			line := []string{r.Workload.Namespace, r.Workload.Kind, r.Workload.Name, strconv.Itoa(len(r.Workload.Pods)), strconv.Itoa(r.GetRequestsMilliCPU()), strconv.Itoa(r.GetRecommendedRequestsMilliCPU()), strconv.Itoa(r.GetDeltaMilliCPU()), strconv.Itoa(r.GetRequestsMiMemory()), strconv.Itoa(r.GetRecommendedRequestsMiMemory()), strconv.Itoa(r.GetDeltaMiMemory()), r.GetConfidence()}
+			err := writer.Write(line)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		cfile, err := os.Create(csvFilePrefix + "-recommend-containers.csv")
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer cfile.Close()
+
+		cwriter := csv.NewWriter(cfile)
+		defer cwriter.Flush()
+
+		header = []string{"Namespace", "Kind", "Name", "Container", "Source", "# Samples", "Confidence", "Requests CPU (m)", observed + " CPU (m)", "Recommended Requests CPU (m)", "Limits CPU (m)", "Recommended Limits CPU (m)", "Requests Memory (Mi)", observed + " Memory (Mi)", "Recommended Requests Memory (Mi)", "Limitis Memory (Mi)", "Recommended Limits Memory (Mi)"}
+		err = cwriter.Write(header)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, r := range recommendations {
+			for _, c := range r.Containers {
+				current := c.Spec.Resources
+				line := []string{r.Workload.Namespace, r.Workload.Kind, r.Workload.Name, c.Spec.Name, c.Usage.Source, strconv.Itoa(len(c.Usage.MilliCPU)), c.GetConfidence(), strconv.Itoa(current.Requests.GetMilliCPU()), strconv.Itoa(c.ObservedMilliCPU), strconv.Itoa(c.RequestsMilliCPU), strconv.Itoa(current.Limits.GetMilliCPU()), strconv.Itoa(c.LimitsMilliCPU), strconv.Itoa(current.Requests.GetMiMemory()), strconv.Itoa(c.ObservedMiMemory), strconv.Itoa(c.RequestsMiMemory), strconv.Itoa(current.Limits.GetMiMemory()), strconv.Itoa(c.LimitsMiMemory)}
+				err := cwriter.Write(line)
+				if err != nil {
+					log.Fatal(err)
+				}
+			}
+		}
+	}
+}
+
+func printPatches(patches []ResourcePatch, format string, dir string) {
+	content, file := "", ""
+	switch format {
+	case PatchFormatYaml:
+		content, file = BuildPatchYaml(patches), "patches.yaml"
+	case PatchFormatScript:
+		content, file = BuildPatchScript(patches), "patch.sh"
+	case PatchFormatKustomize:
+		if dir == "" {
+			log.Fatalf("-patch-dir is required by -patch-format %s", format)
+		}
+		for _, p := range patches {
+			fmt.Println(p.GetDiff())
+		}
+		for _, f := range WriteKustomizePatches(patches, dir) {
+			fmt.Println("Written", f)
+		}
+		return
+	default:
+		log.Fatalf("Invalid -patch-format %s. Valid values yaml|script|kustomize", format)
+	}
+
+	if dir == "" {
+		fmt.Print(content)
+		return
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Fatal(err)
+	}
+	writeFile(filepath.Join(dir, file), content)
+	fmt.Println("Written", filepath.Join(dir, file))
+}
+
+func printWhatIfTab(results []HpaWhatIf, cluster WhatIfCluster, csvFilePrefix string, debug bool) {
+	if csvFilePrefix == "" || debug {
+		formatHeader := "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n"
+		formatValues := "%v\t%v\t%v\t%v\t%v\t%v\t%vm\t%vm\t%vMi\t%vMi\t%v\n"
+		fmt.Println("\nWHAT-IF (HPAs):")
+		w := tabwriter.NewWriter(os.Stdout, 0, 1, 2, ' ', tabwriter.TabIndent)
+		fmt.Fprintf(w, formatHeader, "Namespace", "Hpa Name", "Reference", "Target", "Simulated Target", "Replicas (Min/Max/Actual -> Simulated)", "Requests CPU (m)", "Simulated CPU (m)", "Requests Memory (Mi)", "Simulated Memory (Mi)", "Not Simulated")
+		fmt.Fprintf(w, formatHeader, "---------", "--------", "---------", "------", "----------------", "--------------------------------------", "----------------", "-----------------", "--------------------", "---------------------", "-------------")
+		for _, r := range results {
+			h := r.Hpa
+			replicas := fmt.Sprintf("%d/%d/%d -> %d", h.MinPods, h.MaxPods, h.Replicas, r.Replicas)
+			simulated := fmt.Sprintf("%d%%/%d%%", r.Utilization, r.Target)
+			if r.Reason != "" {
+				simulated = "-"
+			}
+			fmt.Fprintf(w, formatValues, h.Namespace, h.Name, h.GetReference(), h.GetUsageAndTarget(), simulated, replicas, r.GetRequestsMilliCPU(), r.RequestsMilliCPU, r.GetRequestsMiMemory(), r.RequestsMiMemory, r.Reason)
+		}
+		w.Flush()
+
+		fmt.Println("\nWHAT-IF (CLUSTER):")
+		w = tabwriter.NewWriter(os.Stdout, 0, 1, 2, ' ', tabwriter.TabIndent)
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", "Resource", "Allocatable", "Requests", "Simulated Requests", "Fits")
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", "--------", "-----------", "--------", "------------------", "----")
+		fmt.Fprintf(w, "%v\t%vm\t%vm\t%vm\t%v\n", "CPU", cluster.AllocatableMilliCPU, cluster.RequestsMilliCPU, cluster.RequestsMilliCPUAfter, cluster.FitsCPU())
+		fmt.Fprintf(w, "%v\t%vMi\t%vMi\t%vMi\t%v\n", "Memory", cluster.AllocatableMiMemory, cluster.RequestsMiMemory, cluster.RequestsMiMemoryAfter, cluster.FitsMemory())
+		w.Flush()
+	}
+
+	if csvFilePrefix != "" {
+		file, err := os.Create(csvFilePrefix + "-what-if.csv")
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+
+		writer := csv.NewWriter(file)
+		defer writer.Flush()
+
+		header := []string{"Namespace", "Hpa Name", "Reference", "Hpa Use(%)", "Hpa Target(%)", "Simulated Use(%)", "Simulated Target(%)", "Min Replicas", "Max Replicas", "Actual Replicas", "Simulated Replicas", "Requests CPU (m)", "Simulated CPU (m)", "Requests Memory (Mi)", "Simulated Memory (Mi)", "Not Simulated"}
+		err = writer.Write(header)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, r := range results {
+			h := r.Hpa
+			line := []string{h.Namespace, h.Name, h.GetReference(), strconv.Itoa(h.UsageCPU), strconv.Itoa(h.Target), strconv.Itoa(r.Utilization), strconv.Itoa(r.Target), strconv.Itoa(h.MinPods), strconv.Itoa(h.MaxPods), strconv.Itoa(h.Replicas), strconv.Itoa(r.Replicas), strconv.Itoa(r.GetRequestsMilliCPU()), strconv.Itoa(r.RequestsMilliCPU), strconv.Itoa(r.GetRequestsMiMemory()), strconv.Itoa(r.RequestsMiMemory), r.Reason}
+			err := writer.Write(line)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+		line := []string{"<cluster>", "", "", "", "", "", "", "", "", "", "", strconv.Itoa(cluster.RequestsMilliCPU), strconv.Itoa(cluster.RequestsMilliCPUAfter), strconv.Itoa(cluster.RequestsMiMemory), strconv.Itoa(cluster.RequestsMiMemoryAfter), ""}
+		if err := writer.Write(line); err != nil {
+			log.Fatal(err)
+		}
+		line = []string{"<allocatable>", "", "", "", "", "", "", "", "", "", "", strconv.Itoa(cluster.AllocatableMilliCPU), strconv.Itoa(cluster.AllocatableMilliCPU), strconv.Itoa(cluster.AllocatableMiMemory), strconv.Itoa(cluster.AllocatableMiMemory), ""}
+		if err := writer.Write(line); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func printManifestsTab(files []ManifestFile, csvFilePrefix string, debug bool) {
+	if csvFilePrefix == "" || debug {
+		formatHeader := "%v\t%v\t%v\t%v\n"
+		fmt.Println("\nMANIFESTs:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 1, 2, ' ', tabwriter.TabIndent)
+		fmt.Fprintf(w, formatHeader, "File", "Object", "Container", "Changes")
+		fmt.Fprintf(w, formatHeader, "----", "------", "---------", "-------")
+		for _, f := range files {
+			for _, c := range f.Changes {
+				fmt.Fprintf(w, formatHeader, c.File, c.Object, c.Container, strings.Join(c.Changes, ","))
+			}
+			for _, reason := range f.Skipped {
+				fmt.Fprintf(w, formatHeader, f.File, "-", "-", "skipped: "+reason)
+			}
+		}
+		w.Flush()
+	}
+
+	if csvFilePrefix != "" {
+		file, err := os.Create(csvFilePrefix + "-manifests.csv")
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+
+		writer := csv.NewWriter(file)
+		defer writer.Flush()
+
+		err = writer.Write([]string{"File", "Object", "Container", "Changes", "Skipped"})
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, f := range files {
+			for _, c := range f.Changes {
+				if err := writer.Write([]string{c.File, c.Object, c.Container, strings.Join(c.Changes, ","), ""}); err != nil {
+					log.Fatal(err)
+				}
+			}
+			for _, reason := range f.Skipped {
+				if err := writer.Write([]string{f.File, "", "", "", reason}); err != nil {
+					log.Fatal(err)
+				}
+			}
+		}
+	}
+}
+
+// Wrapper contains a list of pods
+type Wrapper struct {
+	Pods []Pod
+}
+
+// GetRequestsMilliCPU total
+func (d Wrapper) GetRequestsMilliCPU() int {
+	total := 0
+	for _, p := range d.Pods {
+		total += p.GetRequestsMilliCPU()
+	}
+	return total
+}
+
+// GetTopMilliCPU total
+func (d Wrapper) GetTopMilliCPU() int {
+	total := 0
+	for _, p := range d.Pods {
+		total += p.Top.GetMilliCPU()
+	}
+	return total
+}
+
+// GetUsageCPU % usage
+func (d Wrapper) GetUsageCPU() float32 {
+	requests, top := 0, 0
+	for _, p := range d.Pods {
+		requests += p.GetRequestsMilliCPU()
+		top += p.Top.GetMilliCPU()
+	}
+	if top == 0 && requests != 0 {
+		return float32(0)
+	} else if requests == 0 {
+		return float32(100)
+	}
+	return float32(top) / float32(requests) * 100
+}
+
+// GetRequestsMiMemory total
+func (d Wrapper) GetRequestsMiMemory() int {
+	total := 0
+	for _, p := range d.Pods {
+		total += p.GetRequestsMiMemory()
+	}
+	return total
+}
+
+// GetTopMiMemory total
+func (d Wrapper) GetTopMiMemory() int {
+	total := 0
+	for _, p := range d.Pods {
+		total += p.Top.GetMiMemory()
+	}
+	return total
+}
+
+// GetUsageMemory % usage
+func (d Wrapper) GetUsageMemory() float32 {
+	requests, top := 0, 0
+	for _, p := range d.Pods {
+		requests += p.GetRequestsMiMemory()
+		top += p.Top.GetMiMemory()
+	}
+	if top == 0 && requests != 0 {
+		return float32(0)
+	} else if requests == 0 {
+		return float32(100)
+	}
+	return float32(top) / float32(requests) * 100
+}
+
+// GetLimitsMilliCPU total
+func (d Wrapper) GetLimitsMilliCPU() int {
+	total := 0
+	for _, p := range d.Pods {
+		total += p.GetLimitsMilliCPU()
+	}
+	return total
+}
+
+// GetRequestsMiEphemeralStorage total
+func (d Wrapper) GetRequestsMiEphemeralStorage() int {
+	total := 0
+	for _, p := range d.Pods {
+		total += p.GetRequestsMiEphemeralStorage()
+	}
+	return total
+}
+
+// GetUsedMiEphemeralStorage total
+func (d Wrapper) GetUsedMiEphemeralStorage() int {
+	total := 0
+	for _, p := range d.Pods {
+		total += p.GetUsedMiEphemeralStorage()
+	}
+	return total
+}
+
+// GetUsageEphemeralStorage % usage
+func (d Wrapper) GetUsageEphemeralStorage() float32 {
+	requests, used := 0, 0
+	for _, p := range d.Pods {
+		requests += p.GetRequestsMiEphemeralStorage()
+		used += p.GetUsedMiEphemeralStorage()
+	}
+	if used == 0 && requests != 0 {
+		return float32(0)
+	} else if requests == 0 {
+		return float32(100)
+	}
+	return float32(used) / float32(requests) * 100
+}
+
+// GetLimitsMiEphemeralStorage total
+func (d Wrapper) GetLimitsMiEphemeralStorage() int {
+	total := 0
+	for _, p := range d.Pods {
+		total += p.GetLimitsMiEphemeralStorage()
+	}
+	return total
+}
+
+// GetLimitsMiMemory total
+func (d Wrapper) GetLimitsMiMemory() int {
+	total := 0
+	for _, p := range d.Pods {
+		total += p.GetLimitsMiMemory()
+	}
+	return total
+}
+
+// GetAvgStartupDuration avg
+func (d Wrapper) GetAvgStartupDuration() time.Duration {
+	total := time.Duration(0)
+	count := 0
+	for _, p := range d.Pods {
+		d := p.GetStartupDuration()
+		if d != time.Duration(0) {
+			total = total + d
+			count = count + 1
+		}
+	}
+	if count == 0 {
+		return time.Duration(0)
+	}
+	return time.Duration(int64(total) / int64(count))
+}
+
+// GetStartupPercentile returns the nearest-rank percentile (0-100) of the pods startup duration, pods without startup info are ignored
+func (d Wrapper) GetStartupPercentile(p int) time.Duration {
+	durations := []int{}
+	for _, pod := range d.Pods {
+		if d := pod.GetStartupDuration(); d != time.Duration(0) {
+			durations = append(durations, int(d/time.Millisecond))
+		}
+	}
+	return time.Duration(percentile(durations, p)) * time.Millisecond
+}
+
+func filterPod(podList []Pod, test func(Pod) bool) (ret []Pod) {
+	for _, pod := range podList {
 		if test(pod) {
 			ret = append(ret, pod)
 		}
@@ -457,6 +1640,15 @@ func filterHpa(hpaList []Hpa, test func(Hpa) bool) (ret []Hpa) {
 	return
 }
 
+func filterPdb(pdbList []Pdb, test func(Pdb) bool) (ret []Pdb) {
+	for _, pdb := range pdbList {
+		if test(pdb) {
+			ret = append(ret, pdb)
+		}
+	}
+	return
+}
+
 func filterDeployment(deploymentList []Deployment, test func(Deployment) bool) (ret []Deployment) {
 	for _, deploy := range deploymentList {
 		if test(deploy) {
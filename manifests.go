@@ -0,0 +1,375 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// rewritableKinds workloads whose manifests are rewritten, with the path to the pod spec
+var rewritableKinds = map[string][]string{
+	"Deployment":  {"spec", "template", "spec"},
+	"StatefulSet": {"spec", "template", "spec"},
+	"DaemonSet":   {"spec", "template", "spec"},
+	"CronJob":     {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// ManifestChange a container of a manifest object rewritten with the recommended resources
+type ManifestChange struct {
+	File      string
+	Object    string
+	Container string
+	Changes   []string
+}
+
+// ManifestFile result of the rewrite of one file
+type ManifestFile struct {
+	File    string
+	Changes []ManifestChange
+	Skipped []string
+}
+
+// manifestEdits text edits of a file, applied from the bottom so the positions of yaml.v3 stay valid
+type manifestEdits struct {
+	replacements []manifestReplacement
+	insertions   map[int][]string
+}
+
+// manifestReplacement replaces length characters of a line (1-based) starting at column (1-based)
+type manifestReplacement struct {
+	line   int
+	column int
+	length int
+	text   string
+}
+
+func (e *manifestEdits) replace(node *yaml.Node, value string) {
+	length := len(node.Value)
+	switch node.Style {
+	case yaml.DoubleQuotedStyle:
+		value, length = `"`+value+`"`, length+2
+	case yaml.SingleQuotedStyle:
+		value, length = "'"+value+"'", length+2
+	}
+	e.replacements = append(e.replacements, manifestReplacement{line: node.Line, column: node.Column, length: length, text: value})
+}
+
+func (e *manifestEdits) insert(afterLine int, lines ...string) {
+	e.insertions[afterLine] = append(e.insertions[afterLine], lines...)
+}
+
+// apply returns the text with all edits
+func (e *manifestEdits) apply(text string) string {
+	lines := strings.Split(text, "\n")
+	sort.SliceStable(e.replacements, func(i, j int) bool {
+		if e.replacements[i].line != e.replacements[j].line {
+			return e.replacements[i].line > e.replacements[j].line
+		}
+		return e.replacements[i].column > e.replacements[j].column
+	})
+	for _, r := range e.replacements {
+		line := []rune(lines[r.line-1])
+		lines[r.line-1] = string(line[:r.column-1]) + r.text + string(line[r.column-1+r.length:])
+		if r.text == "" {
+			lines[r.line-1] = strings.TrimRight(lines[r.line-1], " ")
+		}
+	}
+	afterLines := []int{}
+	for line := range e.insertions {
+		afterLines = append(afterLines, line)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(afterLines)))
+	for _, line := range afterLines {
+		rest := append([]string{}, lines[line:]...)
+		lines = append(append(lines[:line], e.insertions[line]...), rest...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// mappingValue returns the value of a key in a mapping node, nil if absent
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// mappingKey returns the key node of a key in a mapping node, nil if absent
+func mappingKey(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i]
+		}
+	}
+	return nil
+}
+
+// isEmpty returns true for a null value or an empty flow mapping, eg. "resources:", "resources: null" or "resources: {}"
+func isEmpty(node *yaml.Node) bool {
+	return (node.Kind == yaml.ScalarNode && node.Tag == "!!null") || (node.Kind == yaml.MappingNode && len(node.Content) == 0)
+}
+
+// resourceValue a recommended value, eg. requests cpu 24m
+type resourceValue struct {
+	section string
+	name    string
+	value   string
+}
+
+// indentOf returns the spaces before a node
+func indentOf(node *yaml.Node) string {
+	return strings.Repeat(" ", node.Column-1)
+}
+
+// setResources sets the values in the resources of a container, missing keys are added below their parent key
+// returns a description of each change, values already set to the recommended quantity are not touched
+func setResources(edits *manifestEdits, container *yaml.Node, values []resourceValue) []string {
+	descriptions := []string{}
+	block := func(indent string, values []resourceValue) []string {
+		lines := []string{}
+		section := ""
+		for _, v := range values {
+			if v.section != section {
+				section = v.section
+				lines = append(lines, indent+section+":")
+			}
+			lines = append(lines, indent+"  "+v.name+": "+v.value)
+			descriptions = append(descriptions, fmt.Sprintf("%s.%s %s", v.section, v.name, v.value))
+		}
+		return lines
+	}
+
+	indent := indentOf(container.Content[0])
+	resourcesKey, resources := mappingKey(container, "resources"), mappingValue(container, "resources")
+	if resources == nil {
+		edits.insert(mappingKey(container, "name").Line, append([]string{indent + "resources:"}, block(indent+"  ", values)...)...)
+		return descriptions
+	}
+	if isEmpty(resources) {
+		clearEmpty(edits, resources)
+		edits.insert(resourcesKey.Line, block(indent+"  ", values)...)
+		return descriptions
+	}
+	if resources.Kind != yaml.MappingNode || resources.Style == yaml.FlowStyle {
+		return descriptions
+	}
+
+	childIndent := indentOf(resources.Content[0])
+	for _, section := range []string{"requests", "limits"} {
+		sectionValues := []resourceValue{}
+		for _, v := range values {
+			if v.section == section {
+				sectionValues = append(sectionValues, v)
+			}
+		}
+		if len(sectionValues) == 0 {
+			continue
+		}
+		sectionKey, current := mappingKey(resources, section), mappingValue(resources, section)
+		if current == nil {
+			edits.insert(resourcesKey.Line, block(childIndent, sectionValues)...)
+			continue
+		}
+		if isEmpty(current) {
+			clearEmpty(edits, current)
+			edits.insert(sectionKey.Line, block(childIndent, sectionValues)[1:]...)
+			continue
+		}
+		if current.Kind != yaml.MappingNode || current.Style == yaml.FlowStyle {
+			continue
+		}
+		for _, v := range sectionValues {
+			node := mappingValue(current, v.name)
+			if node == nil {
+				edits.insert(sectionKey.Line, indentOf(current.Content[0])+v.name+": "+v.value)
+				descriptions = append(descriptions, fmt.Sprintf("%s.%s %s", v.section, v.name, v.value))
+			} else if !sameQuantity(v.name, node.Value, v.value) {
+				edits.replace(node, v.value)
+				descriptions = append(descriptions, fmt.Sprintf("%s.%s %s -> %s", v.section, v.name, node.Value, v.value))
+			}
+		}
+	}
+	return descriptions
+}
+
+// clearEmpty removes the "{}" of an empty flow mapping or the "null" / "~" of a null scalar, so children can be added below the key
+func clearEmpty(edits *manifestEdits, node *yaml.Node) {
+	switch {
+	case node.Kind == yaml.MappingNode:
+		edits.replacements = append(edits.replacements, manifestReplacement{line: node.Line, column: node.Column, length: 2, text: ""})
+	case node.Kind == yaml.ScalarNode && node.Value != "":
+		edits.replace(node, "")
+	}
+}
+
+func sameQuantity(name string, a string, b string) bool {
+	if name == "cpu" {
+		return String2MilliCPU(a) == String2MilliCPU(b)
+	}
+	return String2MiMemory(a) == String2MiMemory(b)
+}
+
+// findRecommendation matches the object by kind, namespace and name
+// objects without namespace match when the kind and name are unique in the snapshot
+func findRecommendation(recommendations []WorkloadRecommendation, kind string, namespace string, name string) (WorkloadRecommendation, string) {
+	matches := []WorkloadRecommendation{}
+	for _, r := range recommendations {
+		if r.Workload.Kind == kind && r.Workload.Name == name && (namespace == "" || r.Workload.Namespace == namespace) {
+			matches = append(matches, r)
+		}
+	}
+	switch {
+	case len(matches) == 1:
+		return matches[0], ""
+	case len(matches) == 0:
+		return WorkloadRecommendation{}, fmt.Sprintf("%s/%s not found in the snapshot", kind, name)
+	default:
+		return WorkloadRecommendation{}, fmt.Sprintf("%s/%s without namespace matches %d workloads", kind, name, len(matches))
+	}
+}
+
+// rewriteDocument adds the edits of one yaml document
+func rewriteDocument(edits *manifestEdits, doc *yaml.Node, recommendations []WorkloadRecommendation, file string) ([]ManifestChange, []string) {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil, nil
+	}
+	root := doc.Content[0]
+	kindNode := mappingValue(root, "kind")
+	if kindNode == nil {
+		return nil, nil
+	}
+	path, ok := rewritableKinds[kindNode.Value]
+	if !ok {
+		return nil, nil
+	}
+	metadata := mappingValue(root, "metadata")
+	name, namespace := mappingValue(metadata, "name"), mappingValue(metadata, "namespace")
+	if name == nil {
+		return nil, nil
+	}
+	ns := ""
+	if namespace != nil {
+		ns = namespace.Value
+	}
+	r, reason := findRecommendation(recommendations, kindNode.Value, ns, name.Value)
+	if reason != "" {
+		return nil, []string{reason}
+	}
+
+	spec := root
+	for _, key := range path {
+		spec = mappingValue(spec, key)
+	}
+	containers := mappingValue(spec, "containers")
+	if containers == nil || containers.Kind != yaml.SequenceNode {
+		return nil, []string{fmt.Sprintf("%s/%s has no containers", kindNode.Value, name.Value)}
+	}
+
+	changes := []ManifestChange{}
+	for _, container := range containers.Content {
+		containerName := mappingValue(container, "name")
+		if containerName == nil {
+			continue
+		}
+		for _, c := range r.Containers {
+			if c.Spec.Name != containerName.Value || c.GetConfidence() == ConfidenceNone {
+				continue
+			}
+			change := ManifestChange{File: file, Object: r.Workload.Namespace + "/" + r.Workload.GetReference(), Container: c.Spec.Name}
+			values := []resourceValue{
+				{"requests", "cpu", fmt.Sprintf("%dm", c.RequestsMilliCPU)},
+				{"requests", "memory", fmt.Sprintf("%dMi", c.RequestsMiMemory)},
+			}
+			if c.LimitsMilliCPU > 0 {
+				values = append(values, resourceValue{"limits", "cpu", fmt.Sprintf("%dm", c.LimitsMilliCPU)})
+			}
+			if c.LimitsMiMemory > 0 {
+				values = append(values, resourceValue{"limits", "memory", fmt.Sprintf("%dMi", c.LimitsMiMemory)})
+			}
+			change.Changes = setResources(edits, container, values)
+			if len(change.Changes) > 0 {
+				changes = append(changes, change)
+			}
+		}
+	}
+	return changes, nil
+}
+
+// rewriteManifest returns the rewritten text of a file with the recommended resources
+func rewriteManifest(text string, recommendations []WorkloadRecommendation, file string) (string, ManifestFile) {
+	result := ManifestFile{File: file}
+	edits := &manifestEdits{insertions: make(map[int][]string)}
+	decoder := yaml.NewDecoder(bytes.NewBufferString(text))
+	for {
+		doc := &yaml.Node{}
+		err := decoder.Decode(doc)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("invalid yaml: %s", err))
+			return text, result
+		}
+		changes, skipped := rewriteDocument(edits, doc, recommendations, file)
+		result.Changes = append(result.Changes, changes...)
+		result.Skipped = append(result.Skipped, skipped...)
+	}
+	if len(result.Changes) == 0 {
+		return text, result
+	}
+	return edits.apply(text), result
+}
+
+// RewriteManifests edits in place the resources of the Deployments, StatefulSets, DaemonSets and CronJobs
+// found in the yaml files of dir. Only the changed values are touched, so comments and formatting are preserved
+func RewriteManifests(dir string, recommendations []WorkloadRecommendation) []ManifestFile {
+	results := []ManifestFile{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		text, result := rewriteManifest(string(b), recommendations, path)
+		if len(result.Changes) > 0 {
+			if err := ioutil.WriteFile(path, []byte(text), info.Mode()); err != nil {
+				return err
+			}
+		}
+		if len(result.Changes) > 0 || len(result.Skipped) > 0 {
+			results = append(results, result)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	return results
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func manifestRecommendation(namespace string, kind string, name string, containers ...ContainerRecommendation) WorkloadRecommendation {
+	return WorkloadRecommendation{Workload: Workload{Namespace: namespace, Kind: kind, Name: name}, Containers: containers}
+}
+
+func containerRecommendation(name string, cpu int, memory int, limitsMemory int) ContainerRecommendation {
+	return ContainerRecommendation{
+		Spec:             ContainerSpec{Name: name},
+		Usage:            UsageSamples{Source: "top", Snapshots: 1, MilliCPU: []int{cpu}, MiMemory: []int{memory}},
+		RequestsMilliCPU: cpu,
+		RequestsMiMemory: memory,
+		LimitsMiMemory:   limitsMemory,
+	}
+}
+
+func TestRewriteManifests(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manifests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	b, err := ioutil.ReadFile("test-data/manifests/shop/api.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "api.yaml")
+	if err := ioutil.WriteFile(file, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "other.yaml"), []byte("kind: Deployment\nmetadata:\n  name: unknown\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	recommendations := []WorkloadRecommendation{
+		manifestRecommendation("shop", "Deployment", "api",
+			containerRecommendation("server", 120, 300, 600),
+			containerRecommendation("cache", 10, 32, 0),
+			containerRecommendation("metrics", 20, 64, 0)),
+		manifestRecommendation("batch", "CronJob", "report", containerRecommendation("report", 200, 256, 0)),
+	}
+	files := RewriteManifests(dir, recommendations)
+	if len(files) != 2 || len(files[0].Changes) != 4 || len(files[1].Skipped) != 1 {
+		t.Fatalf("Test failed! %+v", files)
+	}
+	if files[1].Skipped[0] != "Deployment/unknown not found in the snapshot" {
+		t.Fatalf("Test failed! %s", files[1].Skipped[0])
+	}
+	server := files[0].Changes[0]
+	if server.Object != "shop/Deployment/api" || server.Container != "server" || len(server.Changes) != 3 || server.Changes[0] != "requests.cpu 1 -> 120m" {
+		t.Fatalf("Test failed! %+v", server)
+	}
+	report := files[0].Changes[3]
+	if report.Object != "batch/CronJob/report" || len(report.Changes) != 2 || report.Changes[1] != "requests.memory 256Mi" {
+		t.Fatalf("Test failed! %+v", report)
+	}
+
+	actual, _ := ioutil.ReadFile(file)
+	expected, _ := ioutil.ReadFile("test-data/manifests-rewritten-api.yaml")
+	if string(actual) != string(expected) {
+		t.Fatalf("Test failed! %s", string(actual))
+	}
+
+	// a second run finds nothing to change
+	files = RewriteManifests(dir, recommendations)
+	if len(files) != 1 || len(files[0].Changes) != 0 {
+		t.Fatalf("Test failed! %+v", files)
+	}
+}
+
+func TestRewriteManifestsNullResources(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manifests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	manifest := `kind: Deployment
+metadata:
+  name: api
+  namespace: shop
+spec:
+  template:
+    spec:
+      containers:
+        - name: server
+          resources: null
+        - name: cache
+          resources: ~
+        - name: metrics
+          resources:
+            requests: null
+`
+	file := filepath.Join(dir, "api.yaml")
+	if err := ioutil.WriteFile(file, []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	recommendations := []WorkloadRecommendation{
+		manifestRecommendation("shop", "Deployment", "api",
+			containerRecommendation("server", 120, 300, 0),
+			containerRecommendation("cache", 10, 32, 0),
+			containerRecommendation("metrics", 20, 64, 0)),
+	}
+	files := RewriteManifests(dir, recommendations)
+	if len(files) != 1 || len(files[0].Changes) != 3 {
+		t.Fatalf("Test failed! %+v", files)
+	}
+
+	expected := `kind: Deployment
+metadata:
+  name: api
+  namespace: shop
+spec:
+  template:
+    spec:
+      containers:
+        - name: server
+          resources:
+            requests:
+              cpu: 120m
+              memory: 300Mi
+        - name: cache
+          resources:
+            requests:
+              cpu: 10m
+              memory: 32Mi
+        - name: metrics
+          resources:
+            requests:
+              cpu: 20m
+              memory: 64Mi
+`
+	actual, _ := ioutil.ReadFile(file)
+	if string(actual) != expected {
+		t.Fatalf("Test failed! %s", string(actual))
+	}
+}
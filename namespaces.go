@@ -0,0 +1,96 @@
+package main
+
+import (
+	"sort"
+)
+
+// NamespaceSummary rollup of all pods and workloads in a namespace
+type NamespaceSummary struct {
+	Name      string
+	Pods      []Pod
+	Workloads []Workload
+}
+
+// GetWasteMilliCPU returns requests - top
+func (n NamespaceSummary) GetWasteMilliCPU() int {
+	w := Wrapper{Pods: n.Pods}
+	return w.GetRequestsMilliCPU() - w.GetTopMilliCPU()
+}
+
+// GetWasteMiMemory returns requests - top
+func (n NamespaceSummary) GetWasteMiMemory() int {
+	w := Wrapper{Pods: n.Pods}
+	return w.GetRequestsMiMemory() - w.GetTopMiMemory()
+}
+
+// CountWorkloadsWithoutPdb ..
+func (n NamespaceSummary) CountWorkloadsWithoutPdb() int {
+	count := 0
+	for _, w := range n.Workloads {
+		if !w.HasPdb() {
+			count++
+		}
+	}
+	return count
+}
+
+// CountWorkloadsWithoutProbes ..
+func (n NamespaceSummary) CountWorkloadsWithoutProbes() int {
+	count := 0
+	for _, w := range n.Workloads {
+		if !w.HasProbes() {
+			count++
+		}
+	}
+	return count
+}
+
+// CountPodsWithoutRequests ..
+func (n NamespaceSummary) CountPodsWithoutRequests() int {
+	count := 0
+	for _, p := range n.Pods {
+		if !p.HasRequests() {
+			count++
+		}
+	}
+	return count
+}
+
+// BuildNamespaceSummaries groups pods and workloads by namespace
+// the result is sorted by absolute cpu waste (requests - top) descending, then by memory waste descending
+func BuildNamespaceSummaries(podList []Pod, workloads []Workload) []NamespaceSummary {
+	nsMap := make(map[string]*NamespaceSummary)
+	get := func(name string) *NamespaceSummary {
+		ns, ok := nsMap[name]
+		if !ok {
+			ns = &NamespaceSummary{Name: name}
+			nsMap[name] = ns
+		}
+		return ns
+	}
+	for _, pod := range podList {
+		ns := get(pod.Metadata.Namespace)
+		ns.Pods = append(ns.Pods, pod)
+	}
+	for _, workload := range workloads {
+		ns := get(workload.Namespace)
+		ns.Workloads = append(ns.Workloads, workload)
+	}
+
+	summaries := []NamespaceSummary{}
+	for _, ns := range nsMap {
+		summaries = append(summaries, *ns)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		wi, wj := summaries[i].GetWasteMilliCPU(), summaries[j].GetWasteMilliCPU()
+		if wi != wj {
+			return wi > wj
+		}
+		mi, mj := summaries[i].GetWasteMiMemory(), summaries[j].GetWasteMiMemory()
+		if mi != mj {
+			return mi > mj
+		}
+		return summaries[i].Name < summaries[j].Name
+	})
+	return summaries
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestBuildNamespaceSummaries(t *testing.T) {
+	pods := loadPodsWithTop(t, "test-data/many-pods.json", "test-data/top-many-pods.txt")
+	b, err := ioutil.ReadFile("test-data/pdb.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pdbs := buildPdbItems(string(b)).Items
+
+	summaries := BuildNamespaceSummaries(pods, BuildWorkloads(pods, pdbs))
+	if l := len(summaries); l != 1 {
+		t.Fatalf("Test failed! found %d expected %d", l, 1)
+	}
+	ns := summaries[0]
+	if ns.Name != "default" || len(ns.Pods) != 23 || len(ns.Workloads) != 12 || ns.CountWorkloadsWithoutPdb() != 11 {
+		t.Fatalf("Test failed! %s %d %d %d", ns.Name, len(ns.Pods), len(ns.Workloads), ns.CountWorkloadsWithoutPdb())
+	}
+	w := Wrapper{Pods: ns.Pods}
+	if waste := ns.GetWasteMilliCPU(); waste != w.GetRequestsMilliCPU()-w.GetTopMilliCPU() {
+		t.Fatalf("Test failed! waste %d", waste)
+	}
+}
+
+func TestNamespaceSummariesSortedByWaste(t *testing.T) {
+	pods := buildPodList(`{"items": [
+		{"metadata": {"name": "b-1", "namespace": "frugal"}, "spec": {"containers": [{"name": "b", "resources": {"requests": {"cpu": "100m", "memory": "128Mi"}}}]}},
+		{"metadata": {"name": "a-1", "namespace": "wasteful"}, "spec": {"containers": [{"name": "a", "resources": {"requests": {"cpu": "1"}}}]}}
+	]}`).Items
+	pods[0].Top = Top{Containers: []Container{{Name: "b", CPU: "90m", Memory: "120Mi"}}}
+	pods[1].Top = Top{Containers: []Container{{Name: "a", CPU: "10m", Memory: "100Mi"}}}
+
+	summaries := BuildNamespaceSummaries(pods, []Workload{})
+	if summaries[0].Name != "wasteful" || summaries[0].GetWasteMilliCPU() != 990 || summaries[1].GetWasteMilliCPU() != 10 {
+		t.Fatalf("Test failed! %+v", summaries)
+	}
+	if summaries[0].CountPodsWithoutRequests() != 1 || summaries[1].CountPodsWithoutRequests() != 0 {
+		t.Fatalf("Test failed! only the wasteful pod misses memory requests")
+	}
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+const noNodepool = "<none>"
+
+// Nodepool groups the nodes with the same node pool label
+type Nodepool struct {
+	Name  string
+	Nodes []Node
+}
+
+// GetPods returns the pods of all nodes in the pool
+func (np Nodepool) GetPods() []Pod {
+	pods := []Pod{}
+	for _, n := range np.Nodes {
+		pods = append(pods, n.Pods...)
+	}
+	return pods
+}
+
+// GetInstanceTypes returns the distinct instance types separated by comma
+func (np Nodepool) GetInstanceTypes() string {
+	set := make(map[string]bool)
+	var types []string
+	for _, n := range np.Nodes {
+		if t := n.GetInstanceType(); t != "" && !set[t] {
+			set[t] = true
+			types = append(types, t)
+		}
+	}
+	sort.Strings(types)
+	return strings.Join(types, ",")
+}
+
+// GetAllocatableMilliCPU total
+func (np Nodepool) GetAllocatableMilliCPU() int {
+	total := 0
+	for _, n := range np.Nodes {
+		total += n.GetAllocatableMilliCPU()
+	}
+	return total
+}
+
+// GetAllocatableMiMemory total
+func (np Nodepool) GetAllocatableMiMemory() int {
+	total := 0
+	for _, n := range np.Nodes {
+		total += n.GetAllocatableMiMemory()
+	}
+	return total
+}
+
+// GetAllocatablePods total
+func (np Nodepool) GetAllocatablePods() int {
+	total := 0
+	for _, n := range np.Nodes {
+		total += n.GetAllocatablePods()
+	}
+	return total
+}
+
+// GetCapacityMilliCPU total
+func (np Nodepool) GetCapacityMilliCPU() int {
+	total := 0
+	for _, n := range np.Nodes {
+		total += n.GetCapacityMilliCPU()
+	}
+	return total
+}
+
+// GetCapacityMiMemory total
+func (np Nodepool) GetCapacityMiMemory() int {
+	total := 0
+	for _, n := range np.Nodes {
+		total += n.GetCapacityMiMemory()
+	}
+	return total
+}
+
+// HasTop returns true if at least one node has metrics
+func (np Nodepool) HasTop() bool {
+	for _, n := range np.Nodes {
+		if n.HasTop() {
+			return true
+		}
+	}
+	return false
+}
+
+// GetUsageCPU % of the allocatable actually used, only the nodes with metrics are accounted
+func (np Nodepool) GetUsageCPU() float32 {
+	top, allocatable := 0, 0
+	for _, n := range np.Nodes {
+		if n.HasTop() {
+			top += n.GetTopMilliCPU()
+			allocatable += n.GetAllocatableMilliCPU()
+		}
+	}
+	return percentage(top, allocatable)
+}
+
+// GetUsageMemory % of the allocatable actually used, only the nodes with metrics are accounted
+func (np Nodepool) GetUsageMemory() float32 {
+	top, allocatable := 0, 0
+	for _, n := range np.Nodes {
+		if n.HasTop() {
+			top += n.GetTopMiMemory()
+			allocatable += n.GetAllocatableMiMemory()
+		}
+	}
+	return percentage(top, allocatable)
+}
+
+// GetUnexplainedMilliCPU total, only the nodes with metrics are accounted
+func (np Nodepool) GetUnexplainedMilliCPU() int {
+	total := 0
+	for _, n := range np.Nodes {
+		if n.HasTop() {
+			total += n.GetUnexplainedMilliCPU()
+		}
+	}
+	return total
+}
+
+// GetUnexplainedMiMemory total, only the nodes with metrics are accounted
+func (np Nodepool) GetUnexplainedMiMemory() int {
+	total := 0
+	for _, n := range np.Nodes {
+		if n.HasTop() {
+			total += n.GetUnexplainedMiMemory()
+		}
+	}
+	return total
+}
+
+// GetTopMilliCPU total actual node usage (kubectl top nodes)
+func (np Nodepool) GetTopMilliCPU() int {
+	total := 0
+	for _, n := range np.Nodes {
+		total += n.GetTopMilliCPU()
+	}
+	return total
+}
+
+// GetTopMiMemory total actual node usage (kubectl top nodes)
+func (np Nodepool) GetTopMiMemory() int {
+	total := 0
+	for _, n := range np.Nodes {
+		total += n.GetTopMiMemory()
+	}
+	return total
+}
+
+// GetAllocatableMiEphemeralStorage total
+func (np Nodepool) GetAllocatableMiEphemeralStorage() int {
+	total := 0
+	for _, n := range np.Nodes {
+		total += n.GetAllocatableMiEphemeralStorage()
+	}
+	return total
+}
+
+// GetUsedMiFs total node root filesystem usage
+func (np Nodepool) GetUsedMiFs() int {
+	total := 0
+	for _, n := range np.Nodes {
+		total += n.GetUsedMiFs()
+	}
+	return total
+}
+
+// GetAllocatableGpus total
+func (np Nodepool) GetAllocatableGpus() int {
+	total := 0
+	for _, n := range np.Nodes {
+		total += n.GetAllocatableGpus()
+	}
+	return total
+}
+
+// GetRequestsGpus total
+func (np Nodepool) GetRequestsGpus() int {
+	total := 0
+	for _, n := range np.Nodes {
+		total += n.GetRequestsGpus()
+	}
+	return total
+}
+
+// GetAvgPodsPerNode ..
+func (np Nodepool) GetAvgPodsPerNode() float32 {
+	if len(np.Nodes) == 0 {
+		return 0
+	}
+	return float32(len(np.GetPods())) / float32(len(np.Nodes))
+}
+
+// GetPodDensity % of allocatable pods in use
+func (np Nodepool) GetPodDensity() float32 {
+	return percentage(len(np.GetPods()), np.GetAllocatablePods())
+}
+
+// GetAllocatedCPU % of allocatable cpu requested by pods
+func (np Nodepool) GetAllocatedCPU() float32 {
+	w := Wrapper{Pods: np.GetPods()}
+	return percentage(w.GetRequestsMilliCPU(), np.GetAllocatableMilliCPU())
+}
+
+// GetAllocatedMemory % of allocatable memory requested by pods
+func (np Nodepool) GetAllocatedMemory() float32 {
+	w := Wrapper{Pods: np.GetPods()}
+	return percentage(w.GetRequestsMiMemory(), np.GetAllocatableMiMemory())
+}
+
+// BuildNodepools groups nodes by node pool, nodes without pool label are grouped in <none>
+// the result is sorted by node pool name
+func BuildNodepools(nodeList []Node) []Nodepool {
+	poolMap := make(map[string]*Nodepool)
+	var names []string
+	for _, node := range nodeList {
+		name := node.GetNodepool()
+		if name == "" {
+			name = noNodepool
+		}
+		pool, ok := poolMap[name]
+		if !ok {
+			pool = &Nodepool{Name: name}
+			poolMap[name] = pool
+			names = append(names, name)
+		}
+		pool.Nodes = append(pool.Nodes, node)
+	}
+	sort.Strings(names)
+
+	pools := []Nodepool{}
+	for _, name := range names {
+		pools = append(pools, *poolMap[name])
+	}
+	return pools
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestGetNodepoolCloudAgnostic(t *testing.T) {
+	b, err := ioutil.ReadFile("test-data/node-ga-labels.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := buildNodeList(string(b)).Items[0]
+	if pool := node.GetNodepool(); pool != "workers-arm" {
+		t.Fatalf("Test failed! %s but expected %s", pool, "workers-arm")
+	}
+
+	tests := map[string]string{
+		"agentpool":                 "nodepool1",
+		"karpenter.sh/nodepool":     "default",
+		"kops.k8s.io/instancegroup": "nodes-us-east-1a",
+	}
+	for label, expected := range tests {
+		n := Node{}
+		n.Metadata.Labels = map[string]string{label: expected}
+		if pool := n.GetNodepool(); pool != expected {
+			t.Fatalf("Test failed! %s but expected %s", pool, expected)
+		}
+	}
+}
+
+func TestAddNodepoolLabels(t *testing.T) {
+	builtin := nodepoolLabels
+	defer func() { nodepoolLabels = builtin }()
+
+	n := Node{}
+	n.Metadata.Labels = map[string]string{"cloud.google.com/gke-nodepool": "pool-1", "acme.io/pool": "custom"}
+	if pool := n.GetNodepool(); pool != "pool-1" {
+		t.Fatalf("Test failed! %s but expected %s", pool, "pool-1")
+	}
+	AddNodepoolLabels([]string{"acme.io/pool"})
+	if pool := n.GetNodepool(); pool != "custom" {
+		t.Fatalf("Test failed! %s but expected %s", pool, "custom")
+	}
+}
+
+func TestBuildNodepools(t *testing.T) {
+	b, err := ioutil.ReadFile("test-data/nodes.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := buildNodeList(string(b)).Items
+	b, err = ioutil.ReadFile("test-data/node-ga-labels.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes = append(nodes, buildNodeList(string(b)).Items...)
+	pods := loadPodsWithTop(t, "test-data/many-pods.json", "test-data/top-many-pods.txt")
+	for i := range nodes {
+		for _, pod := range pods {
+			if pod.Spec.NodeName == nodes[i].GetName() {
+				nodes[i].Pods = append(nodes[i].Pods, pod)
+			}
+		}
+	}
+
+	pools := BuildNodepools(nodes)
+	if l := len(pools); l != 2 {
+		t.Fatalf("Test failed! found %d expected %d", l, 2)
+	}
+	pool := pools[0]
+	if pool.Name != "pool-1" || len(pool.Nodes) != 4 || pool.GetInstanceTypes() != "n1-highmem-8" ||
+		pool.GetAllocatableMilliCPU() != 4*7910 || pool.GetAllocatablePods() != 440 ||
+		len(pool.GetPods()) != 16 || pool.GetAvgPodsPerNode() != 4 {
+		t.Fatalf("Test failed! %s %d %d", pool.Name, len(pool.Nodes), len(pool.GetPods()))
+	}
+	if pools[1].Name != "workers-arm" || pools[1].GetPodDensity() != 0 {
+		t.Fatalf("Test failed! %+v", pools[1])
+	}
+}
@@ -2,9 +2,12 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"os/exec"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // NodeItems struct ..
@@ -15,21 +18,68 @@ type NodeItems struct {
 // Node struct ..
 type Node struct {
 	Metadata struct {
-		Labels struct {
-			InstanceType string `json:"beta.kubernetes.io/instance-type"`
-			Nodepool     string `json:"cloud.google.com/gke-nodepool"`
-			Zone         string `json:"failure-domain.beta.kubernetes.io/zone"`
-		} `json:"labels"`
-		Name string `json:"name"`
+		Labels            map[string]string `json:"labels"`
+		Name              string            `json:"name"`
+		CreationTimestamp time.Time         `json:"creationTimestamp"`
 	} `json:"metadata"`
+	Spec struct {
+		Unschedulable bool    `json:"unschedulable"`
+		Taints        []Taint `json:"taints"`
+	} `json:"spec"`
 	Status struct {
-		Allocatable struct {
-			CPU    string `json:"cpu"`
-			Memory string `json:"memory"`
-			Pods   string `json:"pods"`
-		} `json:"allocatable"`
+		Capacity    NodeResources `json:"capacity"`
+		Allocatable NodeResources `json:"allocatable"`
+		Conditions  []Condition   `json:"conditions"`
+		NodeInfo    struct {
+			Architecture    string `json:"architecture"`
+			OperatingSystem string `json:"operatingSystem"`
+			KubeletVersion  string `json:"kubeletVersion"`
+		} `json:"nodeInfo"`
 	} `json:"status"`
-	Pods []Pod
+	Pods  []Pod
+	Top   NodeTop
+	Stats NodeStats
+}
+
+// NodeResources struct ..
+type NodeResources struct {
+	CPU              string `json:"cpu"`
+	Memory           string `json:"memory"`
+	Pods             string `json:"pods"`
+	EphemeralStorage string `json:"ephemeral-storage"`
+	// Extended keeps every other resource, eg. nvidia.com/gpu or hugepages-2Mi
+	Extended map[string]string
+}
+
+// UnmarshalJSON reads the resource list as a generic map
+func (r *NodeResources) UnmarshalJSON(data []byte) error {
+	values := make(map[string]string)
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*r = NodeResources{
+		CPU:              values["cpu"],
+		Memory:           values["memory"],
+		Pods:             values["pods"],
+		EphemeralStorage: values["ephemeral-storage"],
+		Extended:         extendedResources(values),
+	}
+	return nil
+}
+
+// Taint struct ..
+type Taint struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Effect string `json:"effect"`
+}
+
+// String returns <key>=<value>:<effect> or <key>:<effect> if value is empty
+func (t Taint) String() string {
+	if t.Value == "" {
+		return t.Key + ":" + t.Effect
+	}
+	return t.Key + "=" + t.Value + ":" + t.Effect
 }
 
 // GetName ..
@@ -37,19 +87,118 @@ func (n Node) GetName() string {
 	return n.Metadata.Name
 }
 
-// GetInstanceType ..
+// getLabel returns the value of the first label found
+func (n Node) getLabel(keys ...string) string {
+	for _, key := range keys {
+		if value, ok := n.Metadata.Labels[key]; ok {
+			return value
+		}
+	}
+	return ""
+}
+
+// GetInstanceType uses the GA label and falls back to the beta one
 func (n Node) GetInstanceType() string {
-	return n.Metadata.Labels.InstanceType
+	return n.getLabel("node.kubernetes.io/instance-type", "beta.kubernetes.io/instance-type")
+}
+
+// nodepoolLabels labels used to detect the node pool in GKE, EKS, AKS, Karpenter and kOps, the first one found wins
+// labels passed with -nodepool-label are checked before these ones
+var nodepoolLabels = []string{
+	"cloud.google.com/gke-nodepool",
+	"eks.amazonaws.com/nodegroup",
+	"agentpool",
+	"kubernetes.azure.com/agentpool",
+	"karpenter.sh/nodepool",
+	"karpenter.sh/provisioner-name",
+	"kops.k8s.io/instancegroup",
+}
+
+// AddNodepoolLabels gives precedence to custom node pool labels over the built-in ones
+func AddNodepoolLabels(labels []string) {
+	nodepoolLabels = append(labels, nodepoolLabels...)
 }
 
 // GetNodepool ..
 func (n Node) GetNodepool() string {
-	return n.Metadata.Labels.Nodepool
+	return n.getLabel(nodepoolLabels...)
 }
 
-// GetZone ..
+// GetZone uses the GA label and falls back to the beta one
 func (n Node) GetZone() string {
-	return n.Metadata.Labels.Zone
+	return n.getLabel("topology.kubernetes.io/zone", "failure-domain.beta.kubernetes.io/zone")
+}
+
+// GetArch ..
+func (n Node) GetArch() string {
+	if n.Status.NodeInfo.Architecture != "" {
+		return n.Status.NodeInfo.Architecture
+	}
+	return n.getLabel("kubernetes.io/arch", "beta.kubernetes.io/arch")
+}
+
+// GetOS ..
+func (n Node) GetOS() string {
+	if n.Status.NodeInfo.OperatingSystem != "" {
+		return n.Status.NodeInfo.OperatingSystem
+	}
+	return n.getLabel("kubernetes.io/os", "beta.kubernetes.io/os")
+}
+
+// GetKubeletVersion ..
+func (n Node) GetKubeletVersion() string {
+	return n.Status.NodeInfo.KubeletVersion
+}
+
+// GetAge returns the node age in the same format as kubectl, eg. 133d
+func (n Node) GetAge() string {
+	if n.Metadata.CreationTimestamp.IsZero() {
+		return ""
+	}
+	return Duration2Age(time.Since(n.Metadata.CreationTimestamp))
+}
+
+// IsUnschedulable returns true if the node is cordoned
+func (n Node) IsUnschedulable() bool {
+	return n.Spec.Unschedulable
+}
+
+// GetTaints returns all taints separated by comma
+func (n Node) GetTaints() string {
+	var taints []string
+	for _, t := range n.Spec.Taints {
+		taints = append(taints, t.String())
+	}
+	return strings.Join(taints, ",")
+}
+
+// GetCondition returns the status (True|False|Unknown) of the condition type, empty if not found
+func (n Node) GetCondition(conditionType string) string {
+	for _, c := range n.Status.Conditions {
+		if c.Type == conditionType {
+			return c.Status
+		}
+	}
+	return ""
+}
+
+// IsReady ..
+func (n Node) IsReady() bool {
+	return n.GetCondition("Ready") == "True"
+}
+
+// GetConditions summarizes the Ready condition and the pressure conditions which are True, eg. NotReady,MemoryPressure
+func (n Node) GetConditions() string {
+	conditions := []string{"Ready"}
+	if !n.IsReady() {
+		conditions[0] = "NotReady"
+	}
+	for _, pressure := range []string{"MemoryPressure", "DiskPressure", "PIDPressure"} {
+		if n.GetCondition(pressure) == "True" {
+			conditions = append(conditions, pressure)
+		}
+	}
+	return strings.Join(conditions, ",")
 }
 
 // GetAllocatableMilliCPU ..
@@ -68,6 +217,137 @@ func (n Node) GetAllocatablePods() int {
 	return numPods
 }
 
+// GetAllocatableMiEphemeralStorage ..
+func (n Node) GetAllocatableMiEphemeralStorage() int {
+	return String2MiMemory(n.Status.Allocatable.EphemeralStorage)
+}
+
+// GetAllocatableExtended returns the allocatable quantity of an extended resource, hugepages in Mi
+func (n Node) GetAllocatableExtended(name string) int {
+	return String2Quantity(name, n.Status.Allocatable.Extended[name])
+}
+
+// GetAllocatableGpus ..
+func (n Node) GetAllocatableGpus() int {
+	total := 0
+	for name := range n.Status.Allocatable.Extended {
+		if isGpuResource(name) {
+			total += n.GetAllocatableExtended(name)
+		}
+	}
+	return total
+}
+
+// GetRequestsGpus returns the gpus requested by the pods running in the node
+func (n Node) GetRequestsGpus() int {
+	total := 0
+	for _, pod := range n.Pods {
+		total += pod.GetRequestsGpus()
+	}
+	return total
+}
+
+// GetExtendedResources returns requested/allocatable of each extended resource the node provides, eg. "nvidia.com/gpu=1/4"
+func (n Node) GetExtendedResources() string {
+	parts := []string{}
+	for _, name := range sortedNames(n.Status.Allocatable.Extended) {
+		allocatable := n.GetAllocatableExtended(name)
+		if allocatable == 0 {
+			continue
+		}
+		requests := 0
+		for _, pod := range n.Pods {
+			requests += pod.GetRequestsExtended(name)
+		}
+		if strings.HasPrefix(name, "hugepages-") {
+			parts = append(parts, fmt.Sprintf("%s=%dMi/%dMi", name, requests, allocatable))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s=%d/%d", name, requests, allocatable))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// GetUsedMiFs node root filesystem usage reported by the kubelet stats summary, includes images and logs
+func (n Node) GetUsedMiFs() int {
+	return n.Stats.Fs.GetUsedMi()
+}
+
+// GetCapacityMilliCPU ..
+func (n Node) GetCapacityMilliCPU() int {
+	return String2MilliCPU(n.Status.Capacity.CPU)
+}
+
+// GetCapacityMiMemory ..
+func (n Node) GetCapacityMiMemory() int {
+	return String2MiMemory(n.Status.Capacity.Memory)
+}
+
+// GetReservedMilliCPU returns capacity - allocatable, ie. system-reserved, kube-reserved and eviction threshold
+func (n Node) GetReservedMilliCPU() int {
+	return n.GetCapacityMilliCPU() - n.GetAllocatableMilliCPU()
+}
+
+// GetReservedMiMemory returns capacity - allocatable, ie. system-reserved, kube-reserved and eviction threshold
+func (n Node) GetReservedMiMemory() int {
+	return n.GetCapacityMiMemory() - n.GetAllocatableMiMemory()
+}
+
+// HasTop returns false when the node is missing from kubectl top nodes (the command failed or the node is NotReady)
+func (n Node) HasTop() bool {
+	return n.Top.Name != ""
+}
+
+// formatNodeTop returns the node top value followed by the unit in the table and without unit in the csv,
+// N/A in the table and empty in the csv when the node has no metrics. Usages (float32) are printed with 2 decimals
+func formatNodeTop(value interface{}, unit string, hasTop bool, table bool) string {
+	if !hasTop {
+		if table {
+			return "N/A"
+		}
+		return ""
+	}
+	if usage, ok := value.(float32); ok {
+		value = fmt.Sprintf("%.2f", usage)
+	}
+	if table {
+		return fmt.Sprintf("%v%s", value, unit)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// GetTopMilliCPU actual node usage (kubectl top nodes)
+func (n Node) GetTopMilliCPU() int {
+	return n.Top.GetMilliCPU()
+}
+
+// GetTopMiMemory actual node usage (kubectl top nodes)
+func (n Node) GetTopMiMemory() int {
+	return n.Top.GetMiMemory()
+}
+
+// GetUsageCPU % of allocatable actually used
+func (n Node) GetUsageCPU() float32 {
+	return percentage(n.GetTopMilliCPU(), n.GetAllocatableMilliCPU())
+}
+
+// GetUsageMemory % of allocatable actually used
+func (n Node) GetUsageMemory() float32 {
+	return percentage(n.GetTopMiMemory(), n.GetAllocatableMiMemory())
+}
+
+// GetUnexplainedMilliCPU returns node top - sum of pods top, ie. kubelet, OS, container runtime and pods without metrics
+func (n Node) GetUnexplainedMilliCPU() int {
+	w := Wrapper{Pods: n.Pods}
+	return n.GetTopMilliCPU() - w.GetTopMilliCPU()
+}
+
+// GetUnexplainedMiMemory returns node top - sum of pods top, ie. kubelet, OS, container runtime and pods without metrics
+func (n Node) GetUnexplainedMiMemory() int {
+	w := Wrapper{Pods: n.Pods}
+	return n.GetTopMiMemory() - w.GetTopMiMemory()
+}
+
 // RetrieveNodes executes kubectl get pods command
 // if ns is empty, then all namespaces are used
 func RetrieveNodes(podList []Pod) (ret []Node) {
@@ -87,8 +367,10 @@ func RetrieveNodes(podList []Pod) (ret []Node) {
 			podMap[nodeName] = []Pod{pod}
 		}
 	}
+	topMap := RetrieveNodeTopMap()
 	for _, node := range nodes {
 		node.Pods = podMap[node.GetName()]
+		node.Top = topMap[node.GetName()]
 		ret = append(ret, node)
 	}
 	return
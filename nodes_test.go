@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"io/ioutil"
 	"testing"
 )
@@ -35,3 +36,85 @@ func TestBuildNodeListSize(t *testing.T) {
 		t.Fatalf("Test failed! %+v", nodes)
 	}
 }
+
+func TestBuildNodeGALabels(t *testing.T) {
+	b, err := ioutil.ReadFile("test-data/node-ga-labels.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	str := string(b)
+	node := buildNodeList(str).Items[0]
+
+	if node.GetInstanceType() != "m6g.xlarge" ||
+		node.GetZone() != "us-east-1a" ||
+		node.GetArch() != "arm64" ||
+		node.GetOS() != "linux" ||
+		node.GetKubeletVersion() != "v1.23.9-eks-ba74326" ||
+		node.IsUnschedulable() ||
+		node.GetTaints() != "dedicated=batch:NoSchedule" ||
+		node.GetConditions() != "Ready,MemoryPressure" ||
+		node.GetCondition("DiskPressure") != "False" {
+		t.Fatalf("Test failed! %+v", node)
+	}
+}
+
+func TestBuildNodeListUnschedulable(t *testing.T) {
+	b, err := ioutil.ReadFile("test-data/nodes.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	str := string(b)
+	nodes := buildNodeList(str).Items
+
+	node := nodes[3]
+	if node.GetName() != "gke-central-pool-1-47d730e3-sh01" ||
+		!node.IsUnschedulable() ||
+		node.GetTaints() != "node.kubernetes.io/unschedulable:NoSchedule" ||
+		node.GetConditions() != "Ready" {
+		t.Fatalf("Test failed! %+v", node)
+	}
+}
+
+func TestNodeUsageAndReserved(t *testing.T) {
+	b, err := ioutil.ReadFile("test-data/nodes.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := buildNodeList(string(b)).Items
+	b, err = ioutil.ReadFile("test-data/top-nodes.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tops := buildNodeTopMap(string(b))
+	pods := loadPodsWithTop(t, "test-data/many-pods.json", "test-data/top-many-pods.txt")
+
+	node := nodes[3]
+	node.Top = tops[node.GetName()]
+	for _, pod := range pods {
+		if pod.Spec.NodeName == node.GetName() {
+			node.Pods = append(node.Pods, pod)
+		}
+	}
+	w := Wrapper{Pods: node.Pods}
+	if node.GetTopMilliCPU() != 530 || node.GetUnexplainedMilliCPU() != 530-w.GetTopMilliCPU() ||
+		node.GetTopMiMemory() != 4421 || node.GetUnexplainedMiMemory() != 4421-w.GetTopMiMemory() {
+		t.Fatalf("Test failed! %+v", node.Top)
+	}
+	if node.GetCapacityMilliCPU() != 8000 || node.GetReservedMilliCPU() != 90 ||
+		node.GetReservedMiMemory() != node.GetCapacityMiMemory()-node.GetAllocatableMiMemory() {
+		t.Fatalf("Test failed! %+v", node.Status.Capacity)
+	}
+
+	// a node missing from kubectl top nodes has no usage nor unexplained usage, the totals only account the nodes with metrics
+	missing := nodes[2]
+	if missing.HasTop() || formatNodeTop(missing.GetUnexplainedMilliCPU(), "m", missing.HasTop(), true) != "N/A" || formatNodeTop(missing.GetUsageCPU(), "", missing.HasTop(), false) != "" {
+		t.Fatalf("Test failed! %+v", missing.Top)
+	}
+	if formatNodeTop(node.GetTopMilliCPU(), "m", node.HasTop(), true) != "530m" || formatNodeTop(node.GetUsageCPU(), "%", node.HasTop(), true) != fmt.Sprintf("%.2f%%", node.GetUsageCPU()) {
+		t.Fatalf("Test failed! %s", formatNodeTop(node.GetUsageCPU(), "%", node.HasTop(), true))
+	}
+	np := Nodepool{Nodes: []Node{node, missing}}
+	if !np.HasTop() || np.GetUnexplainedMilliCPU() != node.GetUnexplainedMilliCPU() || np.GetUsageMemory() != node.GetUsageMemory() {
+		t.Fatalf("Test failed! %d %.2f", np.GetUnexplainedMilliCPU(), np.GetUsageMemory())
+	}
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// jsonSchemaVersion version of the -o json document
+// fields can be added within a version, renaming or removing a field or changing its meaning bumps the version
+const jsonSchemaVersion = "v1"
+
+// JSONDocument the -o json document, see the README for the schema
+type JSONDocument struct {
+	SchemaVersion string           `json:"schemaVersion"`
+	Metadata      JSONMetadata     `json:"metadata"`
+	Pods          []JSONPod        `json:"pods"`
+	Hpas          []JSONHpa        `json:"hpas"`
+	Deployments   []JSONDeployment `json:"deployments"`
+	Nodes         []JSONNode       `json:"nodes"`
+	Pdbs          []JSONPdb        `json:"pdbs"`
+	Findings      []Finding        `json:"findings"`
+}
+
+// JSONMetadata Namespace is empty when all namespaces are retrieved
+type JSONMetadata struct {
+	Time          time.Time `json:"time"`
+	Context       string    `json:"context"`
+	PluginVersion string    `json:"pluginVersion"`
+	Namespace     string    `json:"namespace"`
+	Currency      string    `json:"currency"`
+}
+
+// JSONResources cpu in m, memory in Mi, usage in % of the requests
+type JSONResources struct {
+	RequestsMilliCPU   int     `json:"requestsMilliCPU"`
+	TopMilliCPU        int     `json:"topMilliCPU"`
+	UsageCPUPercent    float32 `json:"usageCPUPercent"`
+	LimitsMilliCPU     int     `json:"limitsMilliCPU"`
+	RequestsMiMemory   int     `json:"requestsMiMemory"`
+	TopMiMemory        int     `json:"topMiMemory"`
+	UsageMemoryPercent float32 `json:"usageMemoryPercent"`
+	LimitsMiMemory     int     `json:"limitsMiMemory"`
+}
+
+// JSONCost monthly cost in the metadata currency
+type JSONCost struct {
+	Requests float64 `json:"requests"`
+	Used     float64 `json:"used"`
+	Wasted   float64 `json:"wasted"`
+}
+
+// JSONProbes number of containers of the first pod with each probe
+type JSONProbes struct {
+	Containers int `json:"containers"`
+	Liveness   int `json:"liveness"`
+	Readiness  int `json:"readiness"`
+	PreStop    int `json:"preStop"`
+}
+
+// JSONPdbRef the pdb matching the pods of an hpa or a deployment
+type JSONPdbRef struct {
+	Name           string `json:"name"`
+	MinAvailable   string `json:"minAvailable"`
+	MaxUnavailable string `json:"maxUnavailable"`
+}
+
+// JSONPod StartupDurationSeconds is 0 when unknown, Cost is null without -pricing
+type JSONPod struct {
+	Namespace              string        `json:"namespace"`
+	Name                   string        `json:"name"`
+	NodeName               string        `json:"nodeName"`
+	WorkloadKind           string        `json:"workloadKind"`
+	WorkloadName           string        `json:"workloadName"`
+	QosClass               string        `json:"qosClass"`
+	PriorityClass          string        `json:"priorityClass"`
+	Priority               int           `json:"priority"`
+	Resources              JSONResources `json:"resources"`
+	StartupDurationSeconds float64       `json:"startupDurationSeconds"`
+	Probes                 JSONProbes    `json:"probes"`
+	Cost                   *JSONCost     `json:"cost"`
+}
+
+// JSONHpa CurrentCPUPercent is null when the metric is unknown
+type JSONHpa struct {
+	Namespace                 string        `json:"namespace"`
+	Name                      string        `json:"name"`
+	Reference                 string        `json:"reference"`
+	CurrentCPUPercent         *int          `json:"currentCPUPercent"`
+	TargetCPUPercent          int           `json:"targetCPUPercent"`
+	MinReplicas               int           `json:"minReplicas"`
+	MaxReplicas               int           `json:"maxReplicas"`
+	Replicas                  int           `json:"replicas"`
+	Pods                      int           `json:"pods"`
+	Resources                 JSONResources `json:"resources"`
+	AvgStartupDurationSeconds float64       `json:"avgStartupDurationSeconds"`
+	Probes                    JSONProbes    `json:"probes"`
+	Pdb                       *JSONPdbRef   `json:"pdb"`
+}
+
+// JSONDeployment ..
+type JSONDeployment struct {
+	Namespace                 string        `json:"namespace"`
+	Name                      string        `json:"name"`
+	Replicas                  int           `json:"replicas"`
+	ExpectedReplicas          int           `json:"expectedReplicas"`
+	UpToDate                  int           `json:"upToDate"`
+	Available                 int           `json:"available"`
+	Age                       string        `json:"age"`
+	HasHpa                    bool          `json:"hasHpa"`
+	Pods                      int           `json:"pods"`
+	Resources                 JSONResources `json:"resources"`
+	AvgStartupDurationSeconds float64       `json:"avgStartupDurationSeconds"`
+	Probes                    JSONProbes    `json:"probes"`
+	Pdb                       *JSONPdbRef   `json:"pdb"`
+}
+
+// JSONNode usage in % of the allocatable, Top and Usage are null when the node has no metrics, MonthlyCost is null without -pricing
+type JSONNode struct {
+	Name                string   `json:"name"`
+	Nodepool            string   `json:"nodepool"`
+	InstanceType        string   `json:"instanceType"`
+	Zone                string   `json:"zone"`
+	Ready               bool     `json:"ready"`
+	Unschedulable       bool     `json:"unschedulable"`
+	Spot                bool     `json:"spot"`
+	Pods                int      `json:"pods"`
+	AllocatableMilliCPU int      `json:"allocatableMilliCPU"`
+	AllocatableMiMemory int      `json:"allocatableMiMemory"`
+	AllocatablePods     int      `json:"allocatablePods"`
+	RequestsMilliCPU    int      `json:"requestsMilliCPU"`
+	RequestsMiMemory    int      `json:"requestsMiMemory"`
+	TopMilliCPU         *int     `json:"topMilliCPU"`
+	TopMiMemory         *int     `json:"topMiMemory"`
+	UsageCPUPercent     *float32 `json:"usageCPUPercent"`
+	UsageMemoryPercent  *float32 `json:"usageMemoryPercent"`
+	MonthlyCost         *float64 `json:"monthlyCost"`
+}
+
+// JSONPdb ..
+type JSONPdb struct {
+	Namespace          string   `json:"namespace"`
+	Name               string   `json:"name"`
+	Selector           string   `json:"selector"`
+	MinAvailable       string   `json:"minAvailable"`
+	MaxUnavailable     string   `json:"maxUnavailable"`
+	Pods               int      `json:"pods"`
+	Workloads          []string `json:"workloads"`
+	ExpectedPods       int      `json:"expectedPods"`
+	CurrentHealthy     int      `json:"currentHealthy"`
+	DesiredHealthy     int      `json:"desiredHealthy"`
+	DisruptionsAllowed int      `json:"disruptionsAllowed"`
+	OtherPdbs          []string `json:"otherPdbs"`
+}
+
+// resourceGetter implemented by Pod and Wrapper
+type resourceGetter interface {
+	GetRequestsMilliCPU() int
+	GetTopMilliCPU() int
+	GetUsageCPU() float32
+	GetLimitsMilliCPU() int
+	GetRequestsMiMemory() int
+	GetTopMiMemory() int
+	GetUsageMemory() float32
+	GetLimitsMiMemory() int
+}
+
+func buildJSONResources(r resourceGetter) JSONResources {
+	return JSONResources{
+		RequestsMilliCPU:   r.GetRequestsMilliCPU(),
+		TopMilliCPU:        r.GetTopMilliCPU(),
+		UsageCPUPercent:    r.GetUsageCPU(),
+		LimitsMilliCPU:     r.GetLimitsMilliCPU(),
+		RequestsMiMemory:   r.GetRequestsMiMemory(),
+		TopMiMemory:        r.GetTopMiMemory(),
+		UsageMemoryPercent: r.GetUsageMemory(),
+		LimitsMiMemory:     r.GetLimitsMiMemory(),
+	}
+}
+
+func buildJSONProbes(pods []Pod) JSONProbes {
+	probes := JSONProbes{}
+	if len(pods) == 0 {
+		return probes
+	}
+	for _, c := range pods[0].Spec.Containers {
+		probes.Containers++
+		if c.LivenessProbe.IsSet() {
+			probes.Liveness++
+		}
+		if c.ReadinessProbe.IsSet() {
+			probes.Readiness++
+		}
+		if c.HasPreStop() {
+			probes.PreStop++
+		}
+	}
+	return probes
+}
+
+func buildJSONPdbRef(pdb Pdb) *JSONPdbRef {
+	if pdb.Metadata.Name == "" {
+		return nil
+	}
+	return &JSONPdbRef{Name: pdb.Metadata.Name, MinAvailable: pdb.Spec.MinAvailable.String(), MaxUnavailable: pdb.Spec.MaxUnavailable.String()}
+}
+
+// RetrieveCurrentContext executes kubectl config current-context command, empty if it fails
+func RetrieveCurrentContext() string {
+	out, err := exec.Command("bash", "-c", "kubectl config current-context").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// BuildJSONDocument converts the snapshot, costs are only set when a pricing file is loaded
+func BuildJSONDocument(metadata JSONMetadata, podList []Pod, hpaList []Hpa, deploymentList []Deployment, nodeList []Node, pdbReports []PdbReport, findings []Finding) JSONDocument {
+	priced := len(pricing.Prices) > 0
+	metadata.PluginVersion = version
+	metadata.Currency = pricing.Currency
+	doc := JSONDocument{SchemaVersion: jsonSchemaVersion, Metadata: metadata, Pods: []JSONPod{}, Hpas: []JSONHpa{}, Deployments: []JSONDeployment{}, Nodes: []JSONNode{}, Pdbs: []JSONPdb{}, Findings: []Finding{}}
+	doc.Findings = append(doc.Findings, findings...)
+
+	for _, pod := range podList {
+		p := JSONPod{
+			Namespace:              pod.Metadata.Namespace,
+			Name:                   pod.Metadata.Name,
+			NodeName:               pod.Spec.NodeName,
+			WorkloadKind:           pod.GetWorkloadKind(),
+			WorkloadName:           pod.GetWorkloadName(),
+			QosClass:               pod.GetQosClass(),
+			PriorityClass:          pod.Spec.PriorityClassName,
+			Priority:               pod.GetPriority(),
+			Resources:              buildJSONResources(pod),
+			StartupDurationSeconds: pod.GetStartupDuration().Seconds(),
+			Probes:                 buildJSONProbes([]Pod{pod}),
+		}
+		if priced {
+			p.Cost = &JSONCost{Requests: pod.GetRequestsCost(), Used: pod.GetTopCost(), Wasted: pod.GetWasteCost()}
+		}
+		doc.Pods = append(doc.Pods, p)
+	}
+
+	hpaMap := make(map[string]bool)
+	for _, hpa := range hpaList {
+		hpaMap[hpa.Namespace+"|"+hpa.ReferenceName] = true
+		wp := Wrapper{Pods: hpa.Pods}
+		h := JSONHpa{
+			Namespace:                 hpa.Namespace,
+			Name:                      hpa.Name,
+			Reference:                 hpa.GetReference(),
+			TargetCPUPercent:          hpa.Target,
+			MinReplicas:               hpa.MinPods,
+			MaxReplicas:               hpa.MaxPods,
+			Replicas:                  hpa.Replicas,
+			Pods:                      len(hpa.Pods),
+			Resources:                 buildJSONResources(wp),
+			AvgStartupDurationSeconds: wp.GetAvgStartupDuration().Seconds(),
+			Probes:                    buildJSONProbes(hpa.Pods),
+			Pdb:                       buildJSONPdbRef(hpa.Pdb),
+		}
+		if hpa.UsageCPU != -1 {
+			usage := hpa.UsageCPU
+			h.CurrentCPUPercent = &usage
+		}
+		doc.Hpas = append(doc.Hpas, h)
+	}
+
+	for _, deploy := range deploymentList {
+		wp := Wrapper{Pods: deploy.Pods}
+		doc.Deployments = append(doc.Deployments, JSONDeployment{
+			Namespace:                 deploy.Namespace,
+			Name:                      deploy.Name,
+			Replicas:                  deploy.Replicas,
+			ExpectedReplicas:          deploy.ReplicasExpected,
+			UpToDate:                  deploy.UpToDate,
+			Available:                 deploy.Avaliable,
+			Age:                       deploy.Age,
+			HasHpa:                    hpaMap[deploy.GetDeploymentKey()],
+			Pods:                      len(deploy.Pods),
+			Resources:                 buildJSONResources(wp),
+			AvgStartupDurationSeconds: wp.GetAvgStartupDuration().Seconds(),
+			Probes:                    buildJSONProbes(deploy.Pods),
+			Pdb:                       buildJSONPdbRef(deploy.Pdb),
+		})
+	}
+
+	for _, node := range nodeList {
+		wp := Wrapper{Pods: node.Pods}
+		n := JSONNode{
+			Name:                node.GetName(),
+			Nodepool:            node.GetNodepool(),
+			InstanceType:        node.GetInstanceType(),
+			Zone:                node.GetZone(),
+			Ready:               node.IsReady(),
+			Unschedulable:       node.IsUnschedulable(),
+			Spot:                node.IsSpot(),
+			Pods:                len(node.Pods),
+			AllocatableMilliCPU: node.GetAllocatableMilliCPU(),
+			AllocatableMiMemory: node.GetAllocatableMiMemory(),
+			AllocatablePods:     node.GetAllocatablePods(),
+			RequestsMilliCPU:    wp.GetRequestsMilliCPU(),
+			RequestsMiMemory:    wp.GetRequestsMiMemory(),
+		}
+		if node.HasTop() {
+			topCPU, topMemory, usageCPU, usageMemory := node.GetTopMilliCPU(), node.GetTopMiMemory(), node.GetUsageCPU(), node.GetUsageMemory()
+			n.TopMilliCPU, n.TopMiMemory, n.UsageCPUPercent, n.UsageMemoryPercent = &topCPU, &topMemory, &usageCPU, &usageMemory
+		}
+		if priced {
+			cost := node.GetCost()
+			n.MonthlyCost = &cost
+		}
+		doc.Nodes = append(doc.Nodes, n)
+	}
+
+	for _, r := range pdbReports {
+		pdb := r.Pdb
+		doc.Pdbs = append(doc.Pdbs, JSONPdb{
+			Namespace:          pdb.Metadata.Namespace,
+			Name:               pdb.Metadata.Name,
+			Selector:           pdb.GetSelector(),
+			MinAvailable:       pdb.Spec.MinAvailable.String(),
+			MaxUnavailable:     pdb.Spec.MaxUnavailable.String(),
+			Pods:               len(r.Pods),
+			Workloads:          append([]string{}, r.Workloads...),
+			ExpectedPods:       pdb.Status.ExpectedPods,
+			CurrentHealthy:     pdb.Status.CurrentHealthy,
+			DesiredHealthy:     pdb.Status.DesiredHealthy,
+			DisruptionsAllowed: pdb.Status.DisruptionsAllowed,
+			OtherPdbs:          append([]string{}, r.Overlaps...),
+		})
+	}
+	return doc
+}
+
+// WriteJSONDocument writes the document indented
+func WriteJSONDocument(w io.Writer, doc JSONDocument) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
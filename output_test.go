@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func decodeJSONDocument(t *testing.T, doc JSONDocument) map[string]interface{} {
+	var buf bytes.Buffer
+	if err := WriteJSONDocument(&buf, doc); err != nil {
+		t.Fatal(err)
+	}
+	ret := make(map[string]interface{})
+	if err := json.Unmarshal(buf.Bytes(), &ret); err != nil {
+		t.Fatal(err)
+	}
+	return ret
+}
+
+func jsonKeys(obj interface{}) string {
+	keys := []string{}
+	for key := range obj.(map[string]interface{}) {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+func TestBuildJSONDocument(t *testing.T) {
+	pods := buildPodList(`{"items": [
+		{"metadata": {"name": "api-7d9f-xk2lp", "namespace": "shop", "labels": {"app": "api"}, "ownerReferences": [{"kind": "ReplicaSet", "name": "api-7d9f"}]},
+		 "spec": {"nodeName": "node-1", "containers": [{"name": "api", "livenessProbe": {"httpGet": {"path": "/healthz", "port": 8080}}}, {"name": "proxy"}]}}
+	]}`).Items
+	pdbs := buildPdbItems(`{"items": [
+		{"metadata": {"name": "api", "namespace": "shop"}, "spec": {"minAvailable": 1, "selector": {"matchLabels": {"app": "api"}}}, "status": {"currentHealthy": 1, "desiredHealthy": 1, "disruptionsAllowed": 0, "expectedPods": 1}}
+	]}`).Items
+	hpas := []Hpa{{Namespace: "shop", Name: "api", ReferenceKind: "Deployment", ReferenceName: "api", UsageCPU: -1, Target: 70, MinPods: 1, MaxPods: 3, Replicas: 1, Pods: pods, Pdb: pdbs[0]}}
+	deployments := []Deployment{{Namespace: "shop", Name: "api", Replicas: 1, ReplicasExpected: 1, Pods: pods}, {Namespace: "shop", Name: "worker"}}
+	reports := BuildPdbReports(pdbs, pods)
+	metadata := JSONMetadata{Time: time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC), Context: "prod", Namespace: "shop"}
+
+	doc := decodeJSONDocument(t, BuildJSONDocument(metadata, pods, hpas, deployments, []Node{}, reports, BuildPdbFindings(reports)))
+	if keys := jsonKeys(doc); keys != "deployments,findings,hpas,metadata,nodes,pdbs,pods,schemaVersion" || doc["schemaVersion"] != "v1" {
+		t.Fatalf("Test failed! %s %v", keys, doc["schemaVersion"])
+	}
+	if m := doc["metadata"].(map[string]interface{}); m["time"] != "2021-03-01T10:00:00Z" || m["context"] != "prod" || m["pluginVersion"] != version || m["currency"] != "USD" {
+		t.Fatalf("Test failed! %+v", m)
+	}
+	if nodes := doc["nodes"].([]interface{}); len(nodes) != 0 {
+		t.Fatalf("Test failed! empty list expected %+v", nodes)
+	}
+
+	pod := doc["pods"].([]interface{})[0].(map[string]interface{})
+	if keys := jsonKeys(pod); keys != "cost,name,namespace,nodeName,priority,priorityClass,probes,qosClass,resources,startupDurationSeconds,workloadKind,workloadName" {
+		t.Fatalf("Test failed! %s", keys)
+	}
+	if pod["workloadKind"] != "Deployment" || pod["workloadName"] != "api" || pod["cost"] != nil {
+		t.Fatalf("Test failed! %+v", pod)
+	}
+	if probes := pod["probes"].(map[string]interface{}); probes["containers"] != 2.0 || probes["liveness"] != 1.0 || probes["readiness"] != 0.0 {
+		t.Fatalf("Test failed! %+v", probes)
+	}
+
+	hpa := doc["hpas"].([]interface{})[0].(map[string]interface{})
+	if hpa["reference"] != "Deployment/api" || hpa["currentCPUPercent"] != nil || hpa["pdb"].(map[string]interface{})["minAvailable"] != "1" {
+		t.Fatalf("Test failed! %+v", hpa)
+	}
+	deploys := doc["deployments"].([]interface{})
+	if d := deploys[0].(map[string]interface{}); d["hasHpa"] != true || d["pods"] != 1.0 {
+		t.Fatalf("Test failed! %+v", d)
+	}
+	if d := deploys[1].(map[string]interface{}); d["hasHpa"] != false || d["pdb"] != nil {
+		t.Fatalf("Test failed! %+v", d)
+	}
+
+	pdb := doc["pdbs"].([]interface{})[0].(map[string]interface{})
+	if pdb["selector"] != "app=api" || pdb["disruptionsAllowed"] != 0.0 || len(pdb["otherPdbs"].([]interface{})) != 0 {
+		t.Fatalf("Test failed! %+v", pdb)
+	}
+	finding := doc["findings"].([]interface{})[0].(map[string]interface{})
+	if keys := jsonKeys(finding); keys != "id,message,namespace,object,severity" {
+		t.Fatalf("Test failed! %s", keys)
+	}
+}
+
+func TestBuildJSONDocumentCost(t *testing.T) {
+	defer func() { pricing = Pricing{Currency: "USD"} }()
+	loadTestPricing(t)
+
+	pods := buildPodList(`{"items": [{"metadata": {"name": "standalone", "namespace": "default"}}]}`).Items
+	doc := decodeJSONDocument(t, BuildJSONDocument(JSONMetadata{}, pods, nil, nil, nil, nil, nil))
+	pod := doc["pods"].([]interface{})[0].(map[string]interface{})
+	if keys := jsonKeys(pod["cost"]); keys != "requests,used,wasted" {
+		t.Fatalf("Test failed! %s", keys)
+	}
+	if findings := doc["findings"].([]interface{}); len(findings) != 0 {
+		t.Fatalf("Test failed! empty list expected %+v", findings)
+	}
+}
+
+func TestBuildJSONDocumentNodeTop(t *testing.T) {
+	nodes := buildNodeList(`{"items": [
+		{"metadata": {"name": "node-1"}, "status": {"allocatable": {"cpu": "4", "memory": "8Gi", "pods": "110"}}},
+		{"metadata": {"name": "node-2"}, "status": {"allocatable": {"cpu": "4", "memory": "8Gi", "pods": "110"}}}
+	]}`).Items
+	nodes[0].Top = NodeTop{Name: "node-1", CPU: "1000m", Memory: "2048Mi"}
+	doc := decodeJSONDocument(t, BuildJSONDocument(JSONMetadata{}, nil, nil, nil, nodes, nil, nil))
+	withTop := doc["nodes"].([]interface{})[0].(map[string]interface{})
+	if withTop["topMilliCPU"] != 1000.0 || withTop["usageCPUPercent"] != 25.0 || withTop["usageMemoryPercent"] != 25.0 {
+		t.Fatalf("Test failed! %+v", withTop)
+	}
+	// null, not 0, when the node is missing from kubectl top nodes
+	withoutTop := doc["nodes"].([]interface{})[1].(map[string]interface{})
+	if v, ok := withoutTop["topMilliCPU"]; !ok || v != nil || withoutTop["topMiMemory"] != nil || withoutTop["usageCPUPercent"] != nil || withoutTop["usageMemoryPercent"] != nil {
+		t.Fatalf("Test failed! %+v", withoutTop)
+	}
+}
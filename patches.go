@@ -0,0 +1,239 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Patch formats of the recommend command
+const (
+	PatchFormatYaml      = "yaml"
+	PatchFormatScript    = "script"
+	PatchFormatKustomize = "kustomize"
+)
+
+// patchableKinds workloads whose pod template can be patched
+var patchableKinds = map[string]string{"Deployment": "apps/v1", "StatefulSet": "apps/v1", "DaemonSet": "apps/v1"}
+
+// ResourcePatch recommended resources of the containers of a workload that change
+type ResourcePatch struct {
+	Workload   Workload
+	Containers []ContainerRecommendation
+}
+
+// isInjectedContainer returns true for the sidecars added by the istio injector, they are not in the workload template
+func isInjectedContainer(pod Pod, container string) bool {
+	status := struct {
+		Containers []string `json:"containers"`
+	}{}
+	if err := json.Unmarshal([]byte(pod.Metadata.Annotations["sidecar.istio.io/status"]), &status); err != nil {
+		return false
+	}
+	return contains(status.Containers, container)
+}
+
+// BuildResourcePatches keeps the Deployments, StatefulSets and DaemonSets with at least one container to change
+// containers without usage and injected sidecars are never patched
+// the result is sorted by namespace, kind and name
+func BuildResourcePatches(recommendations []WorkloadRecommendation) []ResourcePatch {
+	patches := []ResourcePatch{}
+	for _, r := range recommendations {
+		if _, ok := patchableKinds[r.Workload.Kind]; !ok {
+			continue
+		}
+		patch := ResourcePatch{Workload: r.Workload}
+		for _, c := range r.Containers {
+			if c.GetConfidence() != ConfidenceNone && len(c.getChanges()) > 0 && !isInjectedContainer(r.Workload.Pods[0], c.Spec.Name) {
+				patch.Containers = append(patch.Containers, c)
+			}
+		}
+		if len(patch.Containers) > 0 {
+			patches = append(patches, patch)
+		}
+	}
+	sort.SliceStable(patches, func(i, j int) bool {
+		return patches[i].Workload.GetWorkloadKey() < patches[j].Workload.GetWorkloadKey()
+	})
+	return patches
+}
+
+// resourceChange a single value of the resources of a container, eg. requests cpu
+type resourceChange struct {
+	Section string
+	Name    string
+	Live    string
+	Value   string
+}
+
+// getChanges returns the requests and limits that differ from the live spec
+// a limit is only set when the container already has one
+func (c ContainerRecommendation) getChanges() []resourceChange {
+	live := c.Spec.Resources
+	candidates := []resourceChange{
+		{"requests", "cpu", live.Requests.CPU, fmt.Sprintf("%dm", c.RequestsMilliCPU)},
+		{"requests", "memory", live.Requests.Memory, fmt.Sprintf("%dMi", c.RequestsMiMemory)},
+	}
+	if c.LimitsMilliCPU > 0 {
+		candidates = append(candidates, resourceChange{"limits", "cpu", live.Limits.CPU, fmt.Sprintf("%dm", c.LimitsMilliCPU)})
+	}
+	if c.LimitsMiMemory > 0 {
+		candidates = append(candidates, resourceChange{"limits", "memory", live.Limits.Memory, fmt.Sprintf("%dMi", c.LimitsMiMemory)})
+	}
+	changes := []resourceChange{}
+	for _, change := range candidates {
+		if change.Name == "cpu" && String2MilliCPU(change.Live) == String2MilliCPU(change.Value) && change.Live != "" {
+			continue
+		}
+		if change.Name == "memory" && String2MiMemory(change.Live) == String2MiMemory(change.Value) && change.Live != "" {
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// getResources returns eg. {"requests": {"cpu": "24m"}, "limits": {"cpu": "36m"}}
+func (c ContainerRecommendation) getResources() map[string]map[string]string {
+	resources := make(map[string]map[string]string)
+	for _, change := range c.getChanges() {
+		if resources[change.Section] == nil {
+			resources[change.Section] = make(map[string]string)
+		}
+		resources[change.Section][change.Name] = change.Value
+	}
+	return resources
+}
+
+// GetFileName returns eg. deployment-adservice.yaml
+func (p ResourcePatch) GetFileName() string {
+	return strings.ToLower(p.Workload.Kind) + "-" + p.Workload.Name + ".yaml"
+}
+
+// GetStrategicMergePatch returns the patch as yaml, containers are merged by name
+func (p ResourcePatch) GetStrategicMergePatch() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "apiVersion: %s\n", patchableKinds[p.Workload.Kind])
+	fmt.Fprintf(&b, "kind: %s\n", p.Workload.Kind)
+	fmt.Fprintf(&b, "metadata:\n  name: %s\n  namespace: %s\n", p.Workload.Name, p.Workload.Namespace)
+	b.WriteString("spec:\n  template:\n    spec:\n      containers:\n")
+	for _, c := range p.Containers {
+		fmt.Fprintf(&b, "      - name: %s\n        resources:\n", c.Spec.Name)
+		resources := c.getResources()
+		for _, section := range []string{"requests", "limits"} {
+			if len(resources[section]) == 0 {
+				continue
+			}
+			fmt.Fprintf(&b, "          %s:\n", section)
+			for _, name := range []string{"cpu", "memory"} {
+				if value, ok := resources[section][name]; ok {
+					fmt.Fprintf(&b, "            %s: %s\n", name, value)
+				}
+			}
+		}
+	}
+	return b.String()
+}
+
+// GetPatchCommand returns the kubectl patch command with the patch as json
+func (p ResourcePatch) GetPatchCommand() string {
+	containers := []map[string]interface{}{}
+	for _, c := range p.Containers {
+		containers = append(containers, map[string]interface{}{"name": c.Spec.Name, "resources": c.getResources()})
+	}
+	patch := map[string]interface{}{"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{"containers": containers}}}}
+	b, err := json.Marshal(patch)
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+	return fmt.Sprintf("kubectl patch %s %s -n %s --type strategic -p '%s'", strings.ToLower(p.Workload.Kind), p.Workload.Name, p.Workload.Namespace, string(b))
+}
+
+// GetDiff returns a unified-like diff of the live pod spec and the patched values
+func (p ResourcePatch) GetDiff() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- live %s/%s\n", p.Workload.Namespace, p.Workload.GetReference())
+	fmt.Fprintf(&b, "+++ recommended %s/%s\n", p.Workload.Namespace, p.Workload.GetReference())
+	for _, c := range p.Containers {
+		fmt.Fprintf(&b, "  - name: %s\n    resources:\n", c.Spec.Name)
+		section := ""
+		for _, change := range c.getChanges() {
+			if change.Section != section {
+				section = change.Section
+				fmt.Fprintf(&b, "      %s:\n", section)
+			}
+			if change.Live != "" {
+				fmt.Fprintf(&b, "-       %s: %s\n", change.Name, change.Live)
+			}
+			fmt.Fprintf(&b, "+       %s: %s\n", change.Name, change.Value)
+		}
+	}
+	return b.String()
+}
+
+// commented prefixes each line with "# "
+func commented(str string) string {
+	lines := strings.Split(strings.TrimSuffix(str, "\n"), "\n")
+	return "# " + strings.Join(lines, "\n# ") + "\n"
+}
+
+// BuildPatchYaml returns all patches as a multi document yaml, each one preceded by its diff as comment
+func BuildPatchYaml(patches []ResourcePatch) string {
+	docs := []string{}
+	for _, p := range patches {
+		docs = append(docs, commented(p.GetDiff())+p.GetStrategicMergePatch())
+	}
+	return strings.Join(docs, "---\n")
+}
+
+// BuildPatchScript returns a shell script with one kubectl patch per workload, each one preceded by its diff as comment
+func BuildPatchScript(patches []ResourcePatch) string {
+	var b strings.Builder
+	b.WriteString("#!/usr/bin/env bash\nset -e\n")
+	for _, p := range patches {
+		b.WriteString("\n" + commented(p.GetDiff()) + p.GetPatchCommand() + "\n")
+	}
+	return b.String()
+}
+
+// WriteKustomizePatches writes <dir>/<namespace>/<kind>-<name>.yaml and a kustomization.yaml per namespace
+// the kustomization only lists the patches, the resources must be added by the overlay using them
+func WriteKustomizePatches(patches []ResourcePatch, dir string) []string {
+	files := []string{}
+	byNamespace := make(map[string][]ResourcePatch)
+	namespaces := []string{}
+	for _, p := range patches {
+		if _, ok := byNamespace[p.Workload.Namespace]; !ok {
+			namespaces = append(namespaces, p.Workload.Namespace)
+		}
+		byNamespace[p.Workload.Namespace] = append(byNamespace[p.Workload.Namespace], p)
+	}
+	for _, ns := range namespaces {
+		nsDir := filepath.Join(dir, ns)
+		if err := os.MkdirAll(nsDir, 0755); err != nil {
+			log.Fatal(err)
+		}
+		kustomization := "apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\npatches:\n"
+		for _, p := range byNamespace[ns] {
+			file := filepath.Join(nsDir, p.GetFileName())
+			writeFile(file, p.GetStrategicMergePatch())
+			files = append(files, file)
+			kustomization += "- path: " + p.GetFileName() + "\n"
+		}
+		file := filepath.Join(nsDir, "kustomization.yaml")
+		writeFile(file, kustomization)
+		files = append(files, file)
+	}
+	return files
+}
+
+func writeFile(file string, content string) {
+	if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func loadResourcePatches(t *testing.T) []ResourcePatch {
+	pods := loadPodsWithTop(t, "test-data/many-pods.json", "test-data/top-many-pods.txt")
+	topMap := make(map[string]Top)
+	for _, pod := range pods {
+		topMap[pod.GetPodKey()] = pod.Top
+	}
+	usage := BuildTopUsageSamples(pods, []map[string]Top{topMap})
+	opts := RecommendOptions{Headroom: 20, Percentile: 95, MinMilliCPU: 10, MinMiMemory: 32}
+	return BuildResourcePatches(BuildRecommendations(BuildWorkloads(pods, []Pdb{}), usage, opts))
+}
+
+func TestBuildResourcePatches(t *testing.T) {
+	patches := loadResourcePatches(t)
+	adservice := patches[0]
+	// istio-proxy is injected, it is not part of the deployment template
+	if len(patches) != 12 || adservice.Workload.GetWorkloadKey() != "default|Deployment/adservice" ||
+		len(adservice.Containers) != 1 || adservice.Containers[0].Spec.Name != "server" {
+		t.Fatalf("Test failed! %+v", adservice)
+	}
+
+	expected := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: adservice
+  namespace: default
+spec:
+  template:
+    spec:
+      containers:
+      - name: server
+        resources:
+          requests:
+            cpu: 24m
+            memory: 222Mi
+          limits:
+            cpu: 36m
+            memory: 370Mi
+`
+	if patch := adservice.GetStrategicMergePatch(); patch != expected {
+		t.Fatalf("Test failed! %s but expected %s", patch, expected)
+	}
+
+	expected = `kubectl patch deployment adservice -n default --type strategic -p '{"spec":{"template":{"spec":{"containers":[{"name":"server","resources":{"limits":{"cpu":"36m","memory":"370Mi"},"requests":{"cpu":"24m","memory":"222Mi"}}}]}}}}'`
+	if command := adservice.GetPatchCommand(); command != expected {
+		t.Fatalf("Test failed! %s but expected %s", command, expected)
+	}
+
+	diff := adservice.GetDiff()
+	if !strings.HasPrefix(diff, "--- live default/Deployment/adservice\n+++ recommended default/Deployment/adservice\n") ||
+		!strings.Contains(diff, "      requests:\n-       cpu: 200m\n+       cpu: 24m\n") ||
+		!strings.Contains(diff, "      limits:\n-       cpu: 300m\n+       cpu: 36m\n") {
+		t.Fatalf("Test failed! %s", diff)
+	}
+
+	yaml := BuildPatchYaml(patches[:2])
+	if strings.Count(yaml, "\n---\n") != 1 || !strings.HasPrefix(yaml, "# --- live default/Deployment/adservice\n") {
+		t.Fatalf("Test failed! %s", yaml)
+	}
+}
+
+func TestWriteKustomizePatches(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubectl-snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	patches := loadResourcePatches(t)[:2]
+	files := WriteKustomizePatches(patches, dir)
+	if len(files) != 3 || files[0] != filepath.Join(dir, "default", "deployment-adservice.yaml") {
+		t.Fatalf("Test failed! %+v", files)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, "default", "kustomization.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(b), "patches:\n- path: deployment-adservice.yaml\n- path: deployment-cartservice.yaml\n") {
+		t.Fatalf("Test failed! %s", string(b))
+	}
+}
@@ -2,8 +2,13 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
+	"math"
 	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 // PdbItems a list of Pod Disruption Budget
@@ -18,10 +23,11 @@ type Pdb struct {
 		Namespace string `json:"namespace"`
 	} `json:"metadata"`
 	Spec struct {
-		MinAvailable   int `json:"minAvailable"`
-		MaxUnavailable int `json:"maxUnavailable"`
+		MinAvailable   IntOrString `json:"minAvailable"`
+		MaxUnavailable IntOrString `json:"maxUnavailable"`
 		Selector       struct {
-			MatchLabels map[string]string `json:"matchLabels"`
+			MatchLabels      map[string]string         `json:"matchLabels"`
+			MatchExpressions []NodeSelectorRequirement `json:"matchExpressions"`
 		} `json:"selector"`
 	} `json:"spec"`
 	Status struct {
@@ -32,15 +38,90 @@ type Pdb struct {
 	} `json:"status"`
 }
 
+// IntOrString a number of pods or a percentage, eg. 1 or "50%"
+type IntOrString struct {
+	Value string
+}
+
+// UnmarshalJSON accepts both numbers and strings
+func (i *IntOrString) UnmarshalJSON(b []byte) error {
+	i.Value = strings.Trim(string(b), `"`)
+	if i.Value == "null" {
+		i.Value = ""
+	}
+	return nil
+}
+
+func (i IntOrString) String() string {
+	return i.Value
+}
+
+// IsSet ..
+func (i IntOrString) IsSet() bool {
+	return i.Value != ""
+}
+
+// GetPods returns the number of pods out of total, percentages are rounded up as the disruption controller does
+func (i IntOrString) GetPods(total int) int {
+	if strings.HasSuffix(i.Value, "%") {
+		percent, _ := strconv.Atoi(strings.TrimSuffix(i.Value, "%"))
+		return int(math.Ceil(float64(percent) * float64(total) / 100))
+	}
+	pods, _ := strconv.Atoi(i.Value)
+	return pods
+}
+
+// GetKey returns <namespace>/<name>
+func (p Pdb) GetKey() string {
+	return p.Metadata.Namespace + "/" + p.Metadata.Name
+}
+
+// GetSelector returns the match labels sorted by key followed by the match expressions, as kubectl prints them,
+// eg. app=api,tier in (web,front),!canary
+func (p Pdb) GetSelector() string {
+	selector := []string{}
+	for k, v := range p.Spec.Selector.MatchLabels {
+		selector = append(selector, k+"="+v)
+	}
+	sort.Strings(selector)
+	for _, r := range p.Spec.Selector.MatchExpressions {
+		switch r.Operator {
+		case "In", "NotIn":
+			selector = append(selector, fmt.Sprintf("%s %s (%s)", r.Key, strings.ToLower(r.Operator), strings.Join(r.Values, ",")))
+		case "Exists":
+			selector = append(selector, r.Key)
+		case "DoesNotExist":
+			selector = append(selector, "!"+r.Key)
+		}
+	}
+	return strings.Join(selector, ",")
+}
+
+// match returns true if the labels match the match labels and all the match expressions of the selector
 func (p Pdb) match(labels map[string]string) bool {
 	for k, v := range p.Spec.Selector.MatchLabels {
 		if labels[k] != v {
 			return false
 		}
 	}
+	for _, r := range p.Spec.Selector.MatchExpressions {
+		if !r.Matches(labels) {
+			return false
+		}
+	}
 	return true
 }
 
+// matchesAny returns true if the pdb covers at least one of the pods
+func (p Pdb) matchesAny(pods []Pod) bool {
+	for _, pod := range pods {
+		if pod.Metadata.Namespace == p.Metadata.Namespace && p.match(pod.Metadata.Labels) {
+			return true
+		}
+	}
+	return false
+}
+
 // RetrievePdbs executes kubectl get pdb command
 func RetrievePdbs() []Pdb {
 	cmd := "kubectl get pdb --all-namespaces -o json"
@@ -59,3 +140,86 @@ func buildPdbItems(str string) (pdbs PdbItems) {
 	}
 	return pdbs
 }
+
+// PdbReport a pdb with the pods and workloads it covers
+type PdbReport struct {
+	Pdb       Pdb
+	Pods      []Pod
+	Workloads []string
+	Overlaps  []string
+}
+
+// GetReplicas returns the pods expected by the disruption controller, the matched pods when the status is empty
+func (r PdbReport) GetReplicas() int {
+	if r.Pdb.Status.ExpectedPods > 0 {
+		return r.Pdb.Status.ExpectedPods
+	}
+	return len(r.Pods)
+}
+
+// BuildPdbReports matches the pdbs with the pods, Overlaps lists the other pdbs covering at least one of the same pods
+// the result is sorted by namespace and name
+func BuildPdbReports(pdbList []Pdb, podList []Pod) []PdbReport {
+	reports := []PdbReport{}
+	podPdbs := make(map[string][]string)
+	for _, pdb := range pdbList {
+		report := PdbReport{Pdb: pdb, Workloads: []string{}, Overlaps: []string{}}
+		workloads := make(map[string]bool)
+		for _, pod := range podList {
+			if pod.Metadata.Namespace == pdb.Metadata.Namespace && pdb.match(pod.Metadata.Labels) {
+				report.Pods = append(report.Pods, pod)
+				podPdbs[pod.GetPodKey()] = append(podPdbs[pod.GetPodKey()], pdb.Metadata.Name)
+				reference := pod.GetWorkloadKind() + "/" + pod.GetWorkloadName()
+				if !workloads[reference] {
+					workloads[reference] = true
+					report.Workloads = append(report.Workloads, reference)
+				}
+			}
+		}
+		sort.Strings(report.Workloads)
+		reports = append(reports, report)
+	}
+
+	for i := range reports {
+		overlaps := make(map[string]bool)
+		for _, pod := range reports[i].Pods {
+			for _, name := range podPdbs[pod.GetPodKey()] {
+				if name != reports[i].Pdb.Metadata.Name && !overlaps[name] {
+					overlaps[name] = true
+					reports[i].Overlaps = append(reports[i].Overlaps, name)
+				}
+			}
+		}
+		sort.Strings(reports[i].Overlaps)
+	}
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].Pdb.GetKey() < reports[j].Pdb.GetKey()
+	})
+	return reports
+}
+
+// BuildPdbFindings flags the pdbs that block node drains or are misconfigured
+func BuildPdbFindings(reports []PdbReport) []Finding {
+	findings := []Finding{}
+	for _, r := range reports {
+		pdb := r.Pdb
+		add := func(id string, severity string, message string) {
+			findings = append(findings, Finding{ID: id, Severity: severity, Namespace: pdb.Metadata.Namespace, Object: "PodDisruptionBudget/" + pdb.Metadata.Name, Message: message})
+		}
+		if len(r.Pods) == 0 {
+			add("PDB-NO-PODS", SeverityWarning, fmt.Sprintf("selector %s matches no pods", pdb.GetSelector()))
+			continue
+		}
+		if pdb.Status.DisruptionsAllowed == 0 {
+			add("PDB-ZERO-DISRUPTIONS", SeverityError, fmt.Sprintf("allows 0 disruptions (%d/%d pods healthy, %d required), node drains are blocked", pdb.Status.CurrentHealthy, pdb.Status.ExpectedPods, pdb.Status.DesiredHealthy))
+		}
+		if len(r.Overlaps) > 0 {
+			add("PDB-OVERLAP", SeverityError, fmt.Sprintf("pods are also covered by %s, the eviction api refuses to evict pods with more than one pdb", strings.Join(r.Overlaps, ", ")))
+		}
+		if pdb.Spec.MinAvailable.IsSet() && pdb.Spec.MinAvailable.GetPods(r.GetReplicas()) >= r.GetReplicas() {
+			add("PDB-MIN-AVAILABLE-EQUALS-REPLICAS", SeverityError, fmt.Sprintf("minAvailable %s is equal to the %d replicas, no pod can ever be evicted", pdb.Spec.MinAvailable, r.GetReplicas()))
+		}
+	}
+	sortFindings(findings)
+	return findings
+}
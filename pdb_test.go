@@ -52,3 +52,93 @@ func TestPdbMatch(t *testing.T) {
 		t.Fatalf("Test failed to match! %+v", pdb)
 	}
 }
+
+func TestPdbMatchExpressions(t *testing.T) {
+	pdb := buildPdbItems(`{"items": [{"metadata": {"name": "api"}, "spec": {"selector": {"matchExpressions": [
+		{"key": "app", "operator": "NotIn", "values": ["db", "cache"]}, {"key": "tier", "operator": "Exists"}]}}}]}`).Items[0]
+	if pdb.GetSelector() != "app notin (db,cache),tier" {
+		t.Fatalf("Test failed! %s", pdb.GetSelector())
+	}
+	if !pdb.match(map[string]string{"app": "api", "tier": "web"}) || !pdb.match(map[string]string{"tier": "web"}) {
+		t.Fatalf("Test failed to match! %+v", pdb)
+	}
+	if pdb.match(map[string]string{"app": "db", "tier": "web"}) || pdb.match(map[string]string{"app": "api"}) {
+		t.Fatalf("Test failed! unexpected match %+v", pdb)
+	}
+}
+
+func TestIntOrString(t *testing.T) {
+	pdbs := buildPdbItems(`{"items": [
+		{"metadata": {"name": "a"}, "spec": {"minAvailable": "50%"}},
+		{"metadata": {"name": "b"}, "spec": {"maxUnavailable": 1}}
+	]}`).Items
+	if a := pdbs[0].Spec.MinAvailable; !a.IsSet() || a.String() != "50%" || a.GetPods(3) != 2 || a.GetPods(4) != 2 {
+		t.Fatalf("Test failed! %+v", a)
+	}
+	if b := pdbs[1].Spec; b.MinAvailable.IsSet() || b.MaxUnavailable.GetPods(10) != 1 {
+		t.Fatalf("Test failed! %+v", b)
+	}
+}
+
+func TestBuildPdbFindings(t *testing.T) {
+	pods := buildPodList(`{"items": [
+		{"metadata": {"name": "api-7d9f-xk2lp", "namespace": "shop", "labels": {"app": "api", "tier": "web"}, "ownerReferences": [{"kind": "ReplicaSet", "name": "api-7d9f"}]}},
+		{"metadata": {"name": "api-7d9f-pq7rt", "namespace": "shop", "labels": {"app": "api", "tier": "web"}, "ownerReferences": [{"kind": "ReplicaSet", "name": "api-7d9f"}]}},
+		{"metadata": {"name": "db-0", "namespace": "shop", "labels": {"app": "db"}, "ownerReferences": [{"kind": "StatefulSet", "name": "db"}]}},
+		{"metadata": {"name": "web-5c8d-ab12c", "namespace": "front", "labels": {"app": "web"}, "ownerReferences": [{"kind": "ReplicaSet", "name": "web-5c8d"}]}},
+		{"metadata": {"name": "web-5c8d-cd34e", "namespace": "front", "labels": {"app": "web"}, "ownerReferences": [{"kind": "ReplicaSet", "name": "web-5c8d"}]}},
+		{"metadata": {"name": "queue-0", "namespace": "jobs", "labels": {"app": "queue"}, "ownerReferences": [{"kind": "StatefulSet", "name": "queue"}]}},
+		{"metadata": {"name": "worker-6b5c-y2y2y", "namespace": "jobs", "labels": {"app": "worker"}, "ownerReferences": [{"kind": "ReplicaSet", "name": "worker-6b5c"}]}},
+		{"metadata": {"name": "worker-6b5c-z3z3z", "namespace": "jobs", "labels": {"app": "worker", "canary": "true"}, "ownerReferences": [{"kind": "ReplicaSet", "name": "worker-6b5c"}]}}
+	]}`).Items
+	pdbs := buildPdbItems(`{"items": [
+		{"metadata": {"name": "api", "namespace": "shop"}, "spec": {"minAvailable": 1, "selector": {"matchLabels": {"app": "api"}}}, "status": {"currentHealthy": 2, "desiredHealthy": 1, "disruptionsAllowed": 1, "expectedPods": 2}},
+		{"metadata": {"name": "web-tier", "namespace": "shop"}, "spec": {"maxUnavailable": 1, "selector": {"matchLabels": {"tier": "web"}}}, "status": {"currentHealthy": 2, "desiredHealthy": 1, "disruptionsAllowed": 1, "expectedPods": 2}},
+		{"metadata": {"name": "db", "namespace": "shop"}, "spec": {"minAvailable": 1, "selector": {"matchLabels": {"app": "db"}}}, "status": {"currentHealthy": 1, "desiredHealthy": 1, "disruptionsAllowed": 0, "expectedPods": 1}},
+		{"metadata": {"name": "web", "namespace": "front"}, "spec": {"minAvailable": "100%", "selector": {"matchLabels": {"app": "web"}}}, "status": {"currentHealthy": 2, "desiredHealthy": 2, "disruptionsAllowed": 0, "expectedPods": 2}},
+		{"metadata": {"name": "old", "namespace": "front"}, "spec": {"minAvailable": 1, "selector": {"matchLabels": {"app": "web", "version": "v1"}}}},
+		{"metadata": {"name": "queue", "namespace": "jobs"}, "spec": {"maxUnavailable": 1, "selector": {"matchExpressions": [{"key": "app", "operator": "In", "values": ["queue"]}]}}, "status": {"currentHealthy": 1, "desiredHealthy": 0, "disruptionsAllowed": 1, "expectedPods": 1}},
+		{"metadata": {"name": "worker", "namespace": "jobs"}, "spec": {"maxUnavailable": 1, "selector": {"matchLabels": {"app": "worker"}, "matchExpressions": [{"key": "canary", "operator": "DoesNotExist"}]}}, "status": {"currentHealthy": 1, "desiredHealthy": 0, "disruptionsAllowed": 1, "expectedPods": 1}}
+	]}`).Items
+
+	reports := BuildPdbReports(pdbs, pods)
+	if r := reports[4]; r.Pdb.GetKey() != "shop/api" || len(r.Pods) != 2 || r.Workloads[0] != "Deployment/api" || len(r.Overlaps) != 1 || r.Overlaps[0] != "web-tier" {
+		t.Fatalf("Test failed! %+v", r)
+	}
+	if r := reports[0]; r.Pdb.GetKey() != "front/old" || r.Pdb.GetSelector() != "app=web,version=v1" || len(r.Pods) != 0 {
+		t.Fatalf("Test failed! %+v", r)
+	}
+	// the match expressions select only their own pods, so the jobs pdbs don't overlap
+	if r := reports[2]; r.Pdb.GetKey() != "jobs/queue" || r.Pdb.GetSelector() != "app in (queue)" || getPodNames(r.Pods) != "jobs/queue-0" || len(r.Overlaps) != 0 {
+		t.Fatalf("Test failed! %+v", r)
+	}
+	if r := reports[3]; r.Pdb.GetKey() != "jobs/worker" || r.Pdb.GetSelector() != "app=worker,!canary" || getPodNames(r.Pods) != "jobs/worker-6b5c-y2y2y" || len(r.Overlaps) != 0 {
+		t.Fatalf("Test failed! %+v", r)
+	}
+
+	// with -d api only the pdbs covering the api pods are reported, the other ones are not PDB-NO-PODS
+	apiPods := filterPod(pods, func(pod Pod) bool { return pod.GetDeploymentName() == "api" })
+	covering := filterPdb(pdbs, func(pdb Pdb) bool { return pdb.matchesAny(apiPods) })
+	if len(covering) != 2 || covering[0].Metadata.Name != "api" || covering[1].Metadata.Name != "web-tier" || len(BuildPdbFindings(BuildPdbReports(covering, apiPods))) != 2 {
+		t.Fatalf("Test failed! %+v", covering)
+	}
+
+	findings := BuildPdbFindings(reports)
+	expected := []string{
+		"front PodDisruptionBudget/old PDB-NO-PODS",
+		"front PodDisruptionBudget/web PDB-MIN-AVAILABLE-EQUALS-REPLICAS",
+		"front PodDisruptionBudget/web PDB-ZERO-DISRUPTIONS",
+		"shop PodDisruptionBudget/api PDB-OVERLAP",
+		"shop PodDisruptionBudget/db PDB-MIN-AVAILABLE-EQUALS-REPLICAS",
+		"shop PodDisruptionBudget/db PDB-ZERO-DISRUPTIONS",
+		"shop PodDisruptionBudget/web-tier PDB-OVERLAP",
+	}
+	if len(findings) != len(expected) {
+		t.Fatalf("Test failed! found %d expected %d: %+v", len(findings), len(expected), findings)
+	}
+	for i, f := range findings {
+		if actual := f.Namespace + " " + f.Object + " " + f.ID; actual != expected[i] {
+			t.Fatalf("Test failed! found %s expected %s", actual, expected[i])
+		}
+	}
+}
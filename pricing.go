@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+
+	"gopkg.in/yaml.v3"
+)
+
+// hoursPerMonth used to turn hourly prices into monthly costs
+const hoursPerMonth = 730
+
+// Price hourly price of one vCPU and one GiB of memory
+// InstanceType, Zone and Spot restrict the nodes the price applies to, empty means any
+type Price struct {
+	InstanceType string  `yaml:"instanceType"`
+	Zone         string  `yaml:"zone"`
+	Spot         *bool   `yaml:"spot"`
+	CPU          float64 `yaml:"cpu"`
+	Memory       float64 `yaml:"memory"`
+}
+
+// Pricing catalog loaded from the -pricing file
+type Pricing struct {
+	Currency string  `yaml:"currency"`
+	Prices   []Price `yaml:"prices"`
+}
+
+// pricing the catalog used by the cost columns, without a -pricing file the cost columns are not printed
+var pricing = Pricing{Currency: "USD"}
+
+// nodePrices price of each node, by node name
+var nodePrices = make(map[string]Price)
+
+// LoadPricing reads the pricing file
+func LoadPricing(file string) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		log.Fatalf("Failed to read pricing file: %s", err)
+	}
+	pricing = buildPricing(string(b))
+}
+
+func buildPricing(str string) Pricing {
+	p := Pricing{}
+	if err := yaml.Unmarshal([]byte(str), &p); err != nil {
+		log.Fatalf("Invalid pricing file: %s", err)
+	}
+	if p.Currency == "" {
+		p.Currency = "USD"
+	}
+	return p
+}
+
+// SetNodePrices finds the price of each node, pods are priced with the price of their node
+func SetNodePrices(nodeList []Node) {
+	nodePrices = make(map[string]Price)
+	for _, node := range nodeList {
+		nodePrices[node.GetName()] = pricing.Find(node.GetInstanceType(), node.GetZone(), node.IsSpot())
+	}
+}
+
+// matches returns the number of restrictions of the price, -1 if one of them doesn't match
+func (p Price) matches(instanceType string, zone string, spot bool) int {
+	count := 0
+	if p.InstanceType != "" {
+		if p.InstanceType != instanceType {
+			return -1
+		}
+		count++
+	}
+	if p.Zone != "" {
+		if p.Zone != zone {
+			return -1
+		}
+		count++
+	}
+	if p.Spot != nil {
+		if *p.Spot != spot {
+			return -1
+		}
+		count++
+	}
+	return count
+}
+
+// Find returns the most specific price matching the node, the first one wins on ties
+func (p Pricing) Find(instanceType string, zone string, spot bool) Price {
+	found, best := Price{}, -1
+	for _, price := range p.Prices {
+		if count := price.matches(instanceType, zone, spot); count > best {
+			found, best = price, count
+		}
+	}
+	return found
+}
+
+// GetMonthlyCost of the cpu and memory
+func (p Price) GetMonthlyCost(milliCPU int, miMemory int) float64 {
+	return (float64(milliCPU)/1000*p.CPU + float64(miMemory)/1024*p.Memory) * hoursPerMonth
+}
+
+// costHeader returns eg. Requests Cost (USD/month)
+func costHeader(name string) string {
+	return name + " Cost (" + pricing.Currency + "/month)"
+}
+
+// formatCost ..
+func formatCost(cost float64) string {
+	return fmt.Sprintf("%.2f", cost)
+}
+
+// hasPricing returns true when a pricing file is loaded, the cost columns are only printed then
+func hasPricing() bool {
+	return len(pricing.Prices) > 0
+}
+
+// pricedColumns returns the cost columns of a table row, each one prefixed by a tab, empty without pricing
+func pricedColumns(values ...interface{}) string {
+	if !hasPricing() {
+		return ""
+	}
+	columns := ""
+	for _, v := range values {
+		if cost, ok := v.(float64); ok {
+			columns += "\t" + formatCost(cost)
+		} else {
+			columns += fmt.Sprintf("\t%v", v)
+		}
+	}
+	return columns
+}
+
+// pricedCells returns the cost cells of a csv line, nothing without pricing
+func pricedCells(cells ...string) []string {
+	if !hasPricing() {
+		return nil
+	}
+	return cells
+}
+
+// getPodPrice returns the price of the pod node, or the default price for pending pods
+func getPodPrice(pod Pod) Price {
+	if price, ok := nodePrices[pod.Spec.NodeName]; ok {
+		return price
+	}
+	return pricing.Find("", "", false)
+}
+
+// spotLabels labels set on spot/preemptible nodes in GKE, EKS, AKS and Karpenter
+var spotLabels = map[string]string{
+	"cloud.google.com/gke-spot":             "true",
+	"cloud.google.com/gke-preemptible":      "true",
+	"eks.amazonaws.com/capacityType":        "SPOT",
+	"karpenter.sh/capacity-type":            "spot",
+	"kubernetes.azure.com/scalesetpriority": "spot",
+}
+
+// IsSpot ..
+func (n Node) IsSpot() bool {
+	for key, value := range spotLabels {
+		if n.Metadata.Labels[key] == value {
+			return true
+		}
+	}
+	return false
+}
+
+// GetCost monthly cost of the node capacity
+func (n Node) GetCost() float64 {
+	return nodePrices[n.GetName()].GetMonthlyCost(n.GetCapacityMilliCPU(), n.GetCapacityMiMemory())
+}
+
+// GetRequestsCost monthly cost of the requests
+func (p Pod) GetRequestsCost() float64 {
+	return getPodPrice(p).GetMonthlyCost(p.GetRequestsMilliCPU(), p.GetRequestsMiMemory())
+}
+
+// GetTopCost monthly cost of the usage
+func (p Pod) GetTopCost() float64 {
+	return getPodPrice(p).GetMonthlyCost(p.GetTopMilliCPU(), p.GetTopMiMemory())
+}
+
+// GetWasteCost returns requests cost - top cost
+func (p Pod) GetWasteCost() float64 {
+	return p.GetRequestsCost() - p.GetTopCost()
+}
+
+// GetRequestsCost total
+func (d Wrapper) GetRequestsCost() float64 {
+	total := 0.0
+	for _, p := range d.Pods {
+		total += p.GetRequestsCost()
+	}
+	return total
+}
+
+// GetTopCost total
+func (d Wrapper) GetTopCost() float64 {
+	total := 0.0
+	for _, p := range d.Pods {
+		total += p.GetTopCost()
+	}
+	return total
+}
+
+// GetWasteCost total
+func (d Wrapper) GetWasteCost() float64 {
+	return d.GetRequestsCost() - d.GetTopCost()
+}
+
+// GetCost monthly cost of all nodes
+func (np Nodepool) GetCost() float64 {
+	total := 0.0
+	for _, n := range np.Nodes {
+		total += n.GetCost()
+	}
+	return total
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"testing"
+)
+
+func loadTestPricing(t *testing.T) {
+	b, err := ioutil.ReadFile("test-data/pricing.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pricing = buildPricing(string(b))
+}
+
+func TestPricingFind(t *testing.T) {
+	defer func() { pricing = Pricing{Currency: "USD"} }()
+	loadTestPricing(t)
+
+	if p := pricing.Find("n2-standard-8", "us-central1-a", false); p.CPU != 0.031611 {
+		t.Fatalf("Test failed! default price expected %+v", p)
+	}
+	if p := pricing.Find("e2-standard-4", "us-central1-a", false); p.CPU != 0.021811 {
+		t.Fatalf("Test failed! instance type price expected %+v", p)
+	}
+	// instance type + spot is more specific than instance type only
+	if p := pricing.Find("e2-standard-4", "us-central1-a", true); p.CPU != 0.006543 {
+		t.Fatalf("Test failed! spot price expected %+v", p)
+	}
+	// instance type and zone have the same specificity, the first one wins
+	if p := pricing.Find("e2-standard-4", "europe-west1-b", false); p.CPU != 0.021811 {
+		t.Fatalf("Test failed! instance type price expected %+v", p)
+	}
+	if c := costHeader("Requests"); c != "Requests Cost (USD/month)" {
+		t.Fatalf("Test failed! %s", c)
+	}
+}
+
+func TestCosts(t *testing.T) {
+	defer func() { pricing, nodePrices = Pricing{Currency: "USD"}, make(map[string]Price) }()
+	loadTestPricing(t)
+
+	nodes := buildNodeList(`{"items": [
+		{"metadata": {"name": "node-a", "labels": {"node.kubernetes.io/instance-type": "e2-standard-4", "cloud.google.com/gke-spot": "true"}},
+		 "status": {"capacity": {"cpu": "4", "memory": "16Gi"}, "allocatable": {"cpu": "3920m", "memory": "13Gi"}}},
+		{"metadata": {"name": "node-b", "labels": {"node.kubernetes.io/instance-type": "e2-standard-4"}},
+		 "status": {"capacity": {"cpu": "4", "memory": "16Gi"}, "allocatable": {"cpu": "3920m", "memory": "13Gi"}}}
+	]}`).Items
+	SetNodePrices(nodes)
+	if !nodes[0].IsSpot() || nodes[1].IsSpot() {
+		t.Fatalf("Test failed! only node-a is spot")
+	}
+	if c := fmt.Sprintf("%.2f", nodes[1].GetCost()); c != "97.83" {
+		t.Fatalf("Test failed! node-b cost %s", c)
+	}
+
+	pods := buildPodList(`{"items": [
+		{"metadata": {"name": "api-a", "namespace": "shop"}, "spec": {"nodeName": "node-a", "containers": [{"name": "c", "resources": {"requests": {"cpu": "1", "memory": "2Gi"}}}]}},
+		{"metadata": {"name": "api-b", "namespace": "shop"}, "spec": {"nodeName": "node-b", "containers": [{"name": "c", "resources": {"requests": {"cpu": "1", "memory": "2Gi"}}}]}},
+		{"metadata": {"name": "api-c", "namespace": "shop"}, "spec": {"containers": [{"name": "c", "resources": {"requests": {"cpu": "1", "memory": "2Gi"}}}]}}
+	]}`).Items
+	pods[1].Top = Top{Containers: []Container{{Name: "c", CPU: "500m", Memory: "1024Mi"}}}
+	expected := []string{"6.06", "20.19", "29.26"}
+	for i, pod := range pods {
+		if c := formatCost(pod.GetRequestsCost()); c != expected[i] {
+			t.Fatalf("Test failed! %s requests cost %s expected %s", pod.Metadata.Name, c, expected[i])
+		}
+	}
+	if c := formatCost(pods[1].GetWasteCost()); c != "10.09" {
+		t.Fatalf("Test failed! waste cost %s", c)
+	}
+	if c := formatCost(Wrapper{Pods: pods}.GetRequestsCost()); c != "55.51" {
+		t.Fatalf("Test failed! total requests cost %s", c)
+	}
+}
+
+func TestPricedColumns(t *testing.T) {
+	if columns, cells := pricedColumns(costHeader("Requests"), 1.5), pricedCells("1.50"); columns != "" || len(cells) != 0 {
+		t.Fatalf("Test failed! no cost columns expected without pricing %q %v", columns, cells)
+	}
+
+	defer func() { pricing = Pricing{Currency: "USD"} }()
+	loadTestPricing(t)
+	if columns := pricedColumns(costHeader("Requests"), 1.5); columns != "\tRequests Cost (USD/month)\t1.50" {
+		t.Fatalf("Test failed! %q", columns)
+	}
+	if cells := pricedCells("1.50", "0.25"); len(cells) != 2 {
+		t.Fatalf("Test failed! %v", cells)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// probeMinTimeoutSeconds liveness probes with a shorter timeout restart containers on a slow response
+const probeMinTimeoutSeconds = 2
+
+// probeStartupPercentile container startup percentile compared with the liveness probe
+const probeStartupPercentile = 90
+
+// probeRules checks of the probe configuration, evaluated by the lint command and printed by -print probes
+// the liveness clock starts with the container, so it is compared with the Initialized -> ContainersReady phase,
+// the scheduling and the init containers are left out
+var probeRules = []LintRule{
+	{"PROBE-LIVENESS-BEFORE-STARTUP", SeverityError, "liveness probe that can fire before the p90 container startup", func(w Workload, ctx lintContext) []string {
+		_, startup := StartupGroup{Pods: w.Pods}.GetPhasesPercentile(probeStartupPercentile)
+		if startup == time.Duration(0) {
+			return nil
+		}
+		return forEachContainer(w, func(c ContainerSpec) string {
+			probe := c.LivenessProbe
+			if probe.IsSet() && !c.StartupProbe.IsSet() && time.Duration(probe.GetFailureSeconds())*time.Second < startup {
+				return fmt.Sprintf("container %s liveness probe can restart it after %ds, the p%d container startup is %s, add a startup probe or a bigger initialDelaySeconds", c.Name, probe.GetFailureSeconds(), probeStartupPercentile, startup)
+			}
+			return ""
+		})
+	}},
+	{"PROBE-SAME-ENDPOINT", SeverityWarning, "liveness and readiness probes hitting the same endpoint", func(w Workload, ctx lintContext) []string {
+		return forEachContainer(w, func(c ContainerSpec) string {
+			endpoint := c.LivenessProbe.GetEndpoint()
+			if endpoint != "" && endpoint == c.ReadinessProbe.GetEndpoint() {
+				return fmt.Sprintf("container %s liveness and readiness probes use %s, a slow dependency restarts the container instead of taking it out of the service", c.Name, c.LivenessProbe)
+			}
+			return ""
+		})
+	}},
+	{"PROBE-SHORT-TIMEOUT", SeverityInfo, "liveness probe with a timeout shorter than 2s", func(w Workload, ctx lintContext) []string {
+		return forEachContainer(w, func(c ContainerSpec) string {
+			if c.LivenessProbe.IsSet() && c.LivenessProbe.GetTimeoutSeconds() < probeMinTimeoutSeconds {
+				return fmt.Sprintf("container %s liveness probe timeout is %ds, a gc pause or a slow response restarts the container", c.Name, c.LivenessProbe.GetTimeoutSeconds())
+			}
+			return ""
+		})
+	}},
+	{"PROBE-READINESS-WITHOUT-LIVENESS", SeverityInfo, "readiness probe without liveness probe", func(w Workload, ctx lintContext) []string {
+		return forEachContainer(w, func(c ContainerSpec) string {
+			if c.ReadinessProbe.IsSet() && !c.LivenessProbe.IsSet() {
+				return fmt.Sprintf("container %s has a readiness probe but no liveness probe, a hung process is never restarted", c.Name)
+			}
+			return ""
+		})
+	}},
+}
+
+// BuildProbeFindings runs the probe rules on each workload, static pods are ignored
+func BuildProbeFindings(workloads []Workload) []Finding {
+	return runLintRules(probeRules, workloads, lintContext{})
+}
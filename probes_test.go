@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildProbeFindings(t *testing.T) {
+	conditions := func(initialized, ready string) string {
+		return `"status": {"conditions": [{"type": "PodScheduled", "status": "True", "lastTransitionTime": "2020-01-01T10:00:00Z"},
+		 {"type": "Initialized", "status": "True", "lastTransitionTime": "2020-01-01T10:00:` + initialized + `Z"},
+		 {"type": "ContainersReady", "status": "True", "lastTransitionTime": "2020-01-01T10:00:` + ready + `Z"},
+		 {"type": "Ready", "status": "True", "lastTransitionTime": "2020-01-01T10:00:` + ready + `Z"}]}`
+	}
+	pods := buildPodList(`{"items": [
+		{"metadata": {"name": "api-7d9f-xk2lp", "namespace": "shop", "ownerReferences": [{"kind": "ReplicaSet", "name": "api-7d9f"}]},
+		 "spec": {"containers": [{"name": "server",
+		  "readinessProbe": {"httpGet": {"path": "/health", "port": 8080}, "timeoutSeconds": 1},
+		  "livenessProbe": {"httpGet": {"path": "/health", "port": 8080}, "periodSeconds": 10, "failureThreshold": 3, "timeoutSeconds": 1}}]}, ` + conditions("05", "20") + `},
+		{"metadata": {"name": "api-7d9f-pq7rt", "namespace": "shop", "ownerReferences": [{"kind": "ReplicaSet", "name": "api-7d9f"}]},
+		 "spec": {"containers": [{"name": "server"}]}, ` + conditions("05", "45") + `},
+		{"metadata": {"name": "web-5c8d-ab12c", "namespace": "shop", "ownerReferences": [{"kind": "ReplicaSet", "name": "web-5c8d"}]},
+		 "spec": {"containers": [{"name": "nginx",
+		  "readinessProbe": {"httpGet": {"path": "/ready", "port": "http"}},
+		  "livenessProbe": {"httpGet": {"path": "/live", "port": "http"}, "timeoutSeconds": 5},
+		  "startupProbe": {"httpGet": {"path": "/live", "port": "http"}, "failureThreshold": 30}}]}, ` + conditions("05", "50") + `},
+		{"metadata": {"name": "cache-4f7a-k9k9k", "namespace": "shop", "ownerReferences": [{"kind": "ReplicaSet", "name": "cache-4f7a"}]},
+		 "spec": {"initContainers": [{"name": "warmup"}], "containers": [{"name": "redis", "livenessProbe": {"tcpSocket": {"port": 6379}, "timeoutSeconds": 5}}]}, ` + conditions("50", "55") + `},
+		{"metadata": {"name": "worker-6b5c-y2y2y", "namespace": "shop", "ownerReferences": [{"kind": "ReplicaSet", "name": "worker-6b5c"}]},
+		 "spec": {"containers": [{"name": "worker", "readinessProbe": {"exec": {"command": ["cat", "/tmp/ready"]}}}]}}
+	]}`).Items
+	workloads := BuildWorkloads(pods, []Pdb{})
+
+	if _, p90 := (StartupGroup{Pods: workloads[0].Pods}).GetPhasesPercentile(90); p90 != 40*time.Second {
+		t.Fatalf("Test failed! p90 %s", p90)
+	}
+	// the cache pod starts after 55s, but its container is ready after 5s
+	if _, p90 := (StartupGroup{Pods: workloads[1].Pods}).GetPhasesPercentile(90); p90 != 5*time.Second {
+		t.Fatalf("Test failed! p90 %s", p90)
+	}
+	if c := workloads[0].Pods[0].Spec.Containers[0]; c.LivenessProbe.GetEndpoint() != "8080/health" || c.LivenessProbe.GetTimings() != "0s/10s/3/1s" || c.LivenessProbe.GetFailureSeconds() != 30 {
+		t.Fatalf("Test failed! %+v", c.LivenessProbe)
+	}
+
+	findings := BuildProbeFindings(workloads)
+	expected := []string{
+		"Deployment/api PROBE-LIVENESS-BEFORE-STARTUP container server liveness probe can restart it after 30s, the p90 container startup is 40s, add a startup probe or a bigger initialDelaySeconds",
+		"Deployment/api PROBE-SAME-ENDPOINT container server liveness and readiness probes use HttpGet: /health, a slow dependency restarts the container instead of taking it out of the service",
+		"Deployment/api PROBE-SHORT-TIMEOUT container server liveness probe timeout is 1s, a gc pause or a slow response restarts the container",
+		"Deployment/worker PROBE-READINESS-WITHOUT-LIVENESS container worker has a readiness probe but no liveness probe, a hung process is never restarted",
+	}
+	if len(findings) != len(expected) {
+		t.Fatalf("Test failed! found %d expected %d: %+v", len(findings), len(expected), findings)
+	}
+	for i, f := range findings {
+		if actual := f.Object + " " + f.ID + " " + f.Message; actual != expected[i] {
+			t.Fatalf("Test failed! found %s expected %s", actual, expected[i])
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PrometheusResponse instant query response (/api/v1/query)
+type PrometheusResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		Result []struct {
+			Metric map[string]string `json:"metric"`
+			Value  []interface{}     `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// prometheusTimeout the quantile_over_time subqueries over a long range can take a while on big clusters
+const prometheusTimeout = 2 * time.Minute
+
+var prometheusClient = &http.Client{Timeout: prometheusTimeout}
+
+// queryPrometheus executes an instant query
+// returns key = <namespace>|<pod>|<container>
+func queryPrometheus(baseURL string, query string) map[string]float64 {
+	body, err := fetchPrometheus(baseURL, query)
+	if err != nil {
+		log.Fatalf("Failed to query prometheus: %s", err)
+	}
+	return buildPrometheusResult(body)
+}
+
+// fetchPrometheus returns the body of the instant query response, an error on a non-2xx status
+func fetchPrometheus(baseURL string, query string) (string, error) {
+	u := strings.TrimSuffix(baseURL, "/") + "/api/v1/query?" + url.Values{"query": {query}}.Encode()
+	resp, err := prometheusClient.Get(u)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message := strings.TrimSpace(string(body))
+		if len(message) > 200 {
+			message = message[:200] + "..."
+		}
+		return "", fmt.Errorf("%s returned %s: %s", u, resp.Status, message)
+	}
+	return string(body), nil
+}
+
+func buildPrometheusResult(str string) map[string]float64 {
+	response := PrometheusResponse{}
+	err := json.Unmarshal([]byte(str), &response)
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+	if response.Status != "success" {
+		log.Fatalf("Prometheus query failed: %s", response.Error)
+	}
+	result := make(map[string]float64)
+	for _, r := range response.Data.Result {
+		if len(r.Value) != 2 {
+			continue
+		}
+		str, _ := r.Value[1].(string)
+		value, err := strconv.ParseFloat(str, 64)
+		if err != nil || math.IsNaN(value) {
+			continue
+		}
+		result[r.Metric["namespace"]+"|"+r.Metric["pod"]+"|"+r.Metric["container"]] = value
+	}
+	return result
+}
+
+// RetrievePrometheusUsageSamples queries the cpu and memory usage percentile of each container over the range, eg. 7d
+// the value of each pod is one sample of the workload container
+func RetrievePrometheusUsageSamples(baseURL string, promRange string, p int, podList []Pod) map[string]*UsageSamples {
+	q := float64(p) / 100
+	cpuQuery := fmt.Sprintf(`quantile_over_time(%g, sum by (namespace, pod, container) (rate(container_cpu_usage_seconds_total{container!="", container!="POD"}[5m]))[%s:5m])`, q, promRange)
+	// cadvisor can expose several series per container (eg. around restarts), the biggest one is kept
+	memoryQuery := fmt.Sprintf(`max by (namespace, pod, container) (quantile_over_time(%g, container_memory_working_set_bytes{container!="", container!="POD"}[%s]))`, q, promRange)
+	return buildPrometheusUsageSamples(podList, queryPrometheus(baseURL, cpuQuery), queryPrometheus(baseURL, memoryQuery))
+}
+
+func buildPrometheusUsageSamples(podList []Pod, cpu map[string]float64, memory map[string]float64) map[string]*UsageSamples {
+	samples := make(map[string]*UsageSamples)
+	for _, pod := range podList {
+		for _, c := range pod.Spec.Containers {
+			key := pod.GetPodKey() + "|" + c.Name
+			cores, ok := cpu[key]
+			if !ok {
+				continue
+			}
+			s, ok := samples[usageKey(pod, c.Name)]
+			if !ok {
+				s = &UsageSamples{Source: "prometheus", Snapshots: 1}
+				samples[usageKey(pod, c.Name)] = s
+			}
+			s.MilliCPU = append(s.MilliCPU, int(math.Ceil(cores*1000)))
+			// a missing memory series is not a 0Mi usage
+			if bytes, ok := memory[key]; ok {
+				s.MiMemory = append(s.MiMemory, int(math.Ceil(bytes/(1024*1024))))
+			}
+		}
+	}
+	return samples
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchPrometheus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("query") == "bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status": "error", "errorType": "bad_data", "error": "parse error"}`))
+			return
+		}
+		w.Write([]byte(`{"status": "success", "data": {"resultType": "vector", "result": []}}`))
+	}))
+	defer server.Close()
+
+	if body, err := fetchPrometheus(server.URL+"/", "up"); err != nil || !strings.Contains(body, "success") {
+		t.Fatalf("Test failed! %s %v", body, err)
+	}
+	// a 4xx/5xx body must not be parsed as an empty result
+	if _, err := fetchPrometheus(server.URL, "bad"); err == nil || !strings.Contains(err.Error(), "400 Bad Request") || !strings.Contains(err.Error(), "parse error") {
+		t.Fatalf("Test failed! %v", err)
+	}
+	if _, err := fetchPrometheus(server.URL+"/prefix", "up"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("Test failed! %v", err)
+	}
+}
+
+func loadPrometheusResult(t *testing.T, file string) map[string]float64 {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buildPrometheusResult(string(b))
+}
+
+func TestBuildPrometheusResult(t *testing.T) {
+	// NaN and empty values are skipped
+	cpu := loadPrometheusResult(t, "test-data/prometheus-cpu.json")
+	if len(cpu) != 5 || cpu["default|adservice-74c5fd9c95-mmhkn|server"] != 0.0213 || cpu["default|adservice-74c5fd9c95-nq8nh|istio-proxy"] != 0.0052 {
+		t.Fatalf("Test failed! %+v", cpu)
+	}
+	if _, ok := cpu["default|gone-pod|server"]; ok {
+		t.Fatalf("Test failed! NaN kept %+v", cpu)
+	}
+	memory := loadPrometheusResult(t, "test-data/prometheus-memory.json")
+	if len(memory) != 5 || memory["default|redis-cart-6fd95746bf-qm9zl|redis"] != 10485760 {
+		t.Fatalf("Test failed! %+v", memory)
+	}
+}
+
+func TestBuildPrometheusUsageSamples(t *testing.T) {
+	cpu := loadPrometheusResult(t, "test-data/prometheus-cpu.json")
+	memory := loadPrometheusResult(t, "test-data/prometheus-memory.json")
+	pods := loadPodsWithTop(t, "test-data/many-pods.json", "test-data/top-many-pods.txt")
+	samples := buildPrometheusUsageSamples(pods, cpu, memory)
+	if len(samples) != 3 {
+		t.Fatalf("Test failed! %+v", samples)
+	}
+	s := samples["default|Deployment/adservice|server"]
+	if s.Source != "prometheus" || len(s.MilliCPU) != 2 || s.MilliCPU[0] != 22 || s.MilliCPU[1] != 31 || s.MiMemory[0] != 190 || s.MiMemory[1] != 180 {
+		t.Fatalf("Test failed! %+v", s)
+	}
+	if c := (ContainerRecommendation{Usage: *s}); c.GetConfidence() != ConfidenceHigh {
+		t.Fatalf("Test failed! %s", c.GetConfidence())
+	}
+	// the istio-proxy has no memory series and the redis no cpu series
+	if p := samples["default|Deployment/adservice|istio-proxy"]; len(p.MilliCPU) != 1 || len(p.MiMemory) != 0 {
+		t.Fatalf("Test failed! %+v", p)
+	}
+	if _, ok := samples["default|Deployment/redis-cart|redis"]; ok {
+		t.Fatalf("Test failed! %+v", samples)
+	}
+
+	// the value of each pod is one sample, the recommendation takes the percentile across the pods
+	workloads := BuildWorkloads(pods, []Pdb{})
+	for _, p := range []struct {
+		percentile, observedMilliCPU, observedMiMemory, requestsMilliCPU int
+	}{
+		{50, 150, 70, 180},
+		{95, 410, 90, 492},
+	} {
+		opts := RecommendOptions{Headroom: 20, Percentile: p.percentile, MinMilliCPU: 10, MinMiMemory: 32}
+		for _, r := range BuildRecommendations(workloads, samples, opts) {
+			switch r.Workload.GetWorkloadKey() {
+			case "default|Deployment/cartservice":
+				if c := r.Containers[0]; c.ObservedMilliCPU != p.observedMilliCPU || c.ObservedMiMemory != p.observedMiMemory || c.RequestsMilliCPU != p.requestsMilliCPU {
+					t.Fatalf("Test failed! p%d %+v", p.percentile, c)
+				}
+			case "default|Deployment/adservice":
+				// no memory series, the memory request and limit are kept
+				if proxy := r.Containers[1]; proxy.RequestsMilliCPU != 10 || proxy.ObservedMiMemory != 0 || proxy.RequestsMiMemory != 128 || proxy.LimitsMiMemory != 128 {
+					t.Fatalf("Test failed! %+v", proxy)
+				}
+			}
+		}
+	}
+}
+
+func TestRetrievePrometheusUsageSamples(t *testing.T) {
+	queries := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("query")
+		queries = append(queries, query)
+		file := "test-data/prometheus-memory.json"
+		if strings.Contains(query, "container_cpu_usage_seconds_total") {
+			file = "test-data/prometheus-cpu.json"
+		}
+		b, _ := ioutil.ReadFile(file)
+		w.Write(b)
+	}))
+	defer server.Close()
+
+	pods := loadPodsWithTop(t, "test-data/many-pods.json", "test-data/top-many-pods.txt")
+	samples := RetrievePrometheusUsageSamples(server.URL, "7d", 95, pods)
+	if len(samples) != 3 || len(queries) != 2 {
+		t.Fatalf("Test failed! %d samples %d queries", len(samples), len(queries))
+	}
+	for _, query := range queries {
+		if !strings.HasPrefix(query, "quantile_over_time(0.95,") && !strings.Contains(query, "(quantile_over_time(0.95,") || !strings.Contains(query, "[7d") {
+			t.Fatalf("Test failed! %s", query)
+		}
+	}
+}
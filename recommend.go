@@ -0,0 +1,232 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Confidence levels of a recommendation
+const (
+	ConfidenceNone   = "none"
+	ConfidenceLow    = "low"
+	ConfidenceMedium = "medium"
+	ConfidenceHigh   = "high"
+)
+
+// UsageSamples observed usage of a container across all pods of a workload
+// Snapshots is the number of times the usage was collected, Source is top, samples or prometheus
+type UsageSamples struct {
+	Source    string
+	Snapshots int
+	MilliCPU  []int
+	MiMemory  []int
+}
+
+// RecommendOptions ..
+type RecommendOptions struct {
+	Headroom    int
+	Percentile  int
+	MinMilliCPU int
+	MinMiMemory int
+}
+
+// usageKey returns <namespace>|<kind>/<name>|<container>
+func usageKey(pod Pod, container string) string {
+	return pod.GetWorkloadKey() + "|" + container
+}
+
+// RetrieveTopSamples executes kubectl top pods count times, waiting interval between each execution
+func RetrieveTopSamples(ns string, count int, interval time.Duration) []map[string]Top {
+	samples := []map[string]Top{}
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			time.Sleep(interval)
+		}
+		samples = append(samples, RetrieveTopMap(ns))
+	}
+	return samples
+}
+
+// BuildTopUsageSamples groups the kubectl top snapshots by workload container
+func BuildTopUsageSamples(podList []Pod, topMaps []map[string]Top) map[string]*UsageSamples {
+	source := "top"
+	if len(topMaps) > 1 {
+		source = "samples"
+	}
+	samples := make(map[string]*UsageSamples)
+	for _, topMap := range topMaps {
+		for _, pod := range podList {
+			top, ok := topMap[pod.GetPodKey()]
+			if !ok {
+				continue
+			}
+			for _, c := range top.Containers {
+				key := usageKey(pod, c.Name)
+				s, ok := samples[key]
+				if !ok {
+					s = &UsageSamples{Source: source, Snapshots: len(topMaps)}
+					samples[key] = s
+				}
+				s.MilliCPU = append(s.MilliCPU, c.GetMilliCPU())
+				s.MiMemory = append(s.MiMemory, c.GetMiMemory())
+			}
+		}
+	}
+	return samples
+}
+
+// ContainerRecommendation current and recommended resources of one container of a workload
+type ContainerRecommendation struct {
+	Spec             ContainerSpec
+	Usage            UsageSamples
+	RequestsMilliCPU int
+	RequestsMiMemory int
+	LimitsMilliCPU   int
+	LimitsMiMemory   int
+	ObservedMilliCPU int
+	ObservedMiMemory int
+}
+
+// GetConfidence depends on the data source and on the number of snapshots
+func (c ContainerRecommendation) GetConfidence() string {
+	switch {
+	case len(c.Usage.MilliCPU) == 0:
+		return ConfidenceNone
+	case c.Usage.Source == "prometheus" || c.Usage.Snapshots >= 30:
+		return ConfidenceHigh
+	case c.Usage.Snapshots >= 5:
+		return ConfidenceMedium
+	default:
+		return ConfidenceLow
+	}
+}
+
+// WorkloadRecommendation ..
+type WorkloadRecommendation struct {
+	Workload   Workload
+	Containers []ContainerRecommendation
+}
+
+// GetRequestsMilliCPU current requests of all pods
+func (r WorkloadRecommendation) GetRequestsMilliCPU() int {
+	return Wrapper{Pods: r.Workload.Pods}.GetRequestsMilliCPU()
+}
+
+// GetRequestsMiMemory current requests of all pods
+func (r WorkloadRecommendation) GetRequestsMiMemory() int {
+	return Wrapper{Pods: r.Workload.Pods}.GetRequestsMiMemory()
+}
+
+// GetRecommendedRequestsMilliCPU recommended requests of all pods
+func (r WorkloadRecommendation) GetRecommendedRequestsMilliCPU() int {
+	total := 0
+	for _, c := range r.Containers {
+		total += c.RequestsMilliCPU
+	}
+	return total * len(r.Workload.Pods)
+}
+
+// GetRecommendedRequestsMiMemory recommended requests of all pods
+func (r WorkloadRecommendation) GetRecommendedRequestsMiMemory() int {
+	total := 0
+	for _, c := range r.Containers {
+		total += c.RequestsMiMemory
+	}
+	return total * len(r.Workload.Pods)
+}
+
+// GetDeltaMilliCPU recommended - current, negative means savings
+func (r WorkloadRecommendation) GetDeltaMilliCPU() int {
+	return r.GetRecommendedRequestsMilliCPU() - r.GetRequestsMilliCPU()
+}
+
+// GetDeltaMiMemory recommended - current, negative means savings
+func (r WorkloadRecommendation) GetDeltaMiMemory() int {
+	return r.GetRecommendedRequestsMiMemory() - r.GetRequestsMiMemory()
+}
+
+// GetConfidence returns the lowest confidence of the containers
+func (r WorkloadRecommendation) GetConfidence() string {
+	levels := []string{ConfidenceNone, ConfidenceLow, ConfidenceMedium, ConfidenceHigh}
+	lowest := len(levels) - 1
+	for _, c := range r.Containers {
+		for i, level := range levels {
+			if level == c.GetConfidence() && i < lowest {
+				lowest = i
+			}
+		}
+	}
+	return levels[lowest]
+}
+
+// recommendRequest returns the usage percentile plus headroom, never lower than min
+func recommendRequest(usage []int, opts RecommendOptions, min int) int {
+	observed := percentile(usage, opts.Percentile)
+	recommended := int(math.Ceil(float64(observed) * float64(100+opts.Headroom) / 100))
+	if recommended < min {
+		return min
+	}
+	return recommended
+}
+
+// recommendLimit keeps the current limit/request ratio
+// no limit stays no limit, and a limit without request is kept unless it is lower than the recommended request
+func recommendLimit(request int, limit int, recommendedRequest int) int {
+	switch {
+	case limit == 0:
+		return 0
+	case request == 0:
+		if limit < recommendedRequest {
+			return recommendedRequest
+		}
+		return limit
+	default:
+		return int(math.Ceil(float64(recommendedRequest) * float64(limit) / float64(request)))
+	}
+}
+
+// BuildRecommendations proposes new requests and limits for each container of each workload
+// containers without usage keep their current values, as the memory of the containers without memory samples
+// the result is sorted by cpu delta ascending, so the biggest savings are on the top
+func BuildRecommendations(workloadList []Workload, samples map[string]*UsageSamples, opts RecommendOptions) []WorkloadRecommendation {
+	recommendations := []WorkloadRecommendation{}
+	for _, workload := range workloadList {
+		if len(workload.Pods) == 0 {
+			continue
+		}
+		r := WorkloadRecommendation{Workload: workload}
+		for _, spec := range workload.Pods[0].Spec.Containers {
+			requests, limits := spec.Resources.Requests, spec.Resources.Limits
+			c := ContainerRecommendation{
+				Spec:             spec,
+				Usage:            UsageSamples{Source: "none"},
+				RequestsMilliCPU: requests.GetMilliCPU(),
+				RequestsMiMemory: requests.GetMiMemory(),
+				LimitsMilliCPU:   limits.GetMilliCPU(),
+				LimitsMiMemory:   limits.GetMiMemory(),
+			}
+			if usage, ok := samples[usageKey(workload.Pods[0], spec.Name)]; ok && len(usage.MilliCPU) > 0 {
+				c.Usage = *usage
+				c.ObservedMilliCPU = percentile(usage.MilliCPU, opts.Percentile)
+				c.RequestsMilliCPU = recommendRequest(usage.MilliCPU, opts, opts.MinMilliCPU)
+				c.LimitsMilliCPU = recommendLimit(requests.GetMilliCPU(), limits.GetMilliCPU(), c.RequestsMilliCPU)
+				if len(usage.MiMemory) > 0 {
+					c.ObservedMiMemory = percentile(usage.MiMemory, opts.Percentile)
+					c.RequestsMiMemory = recommendRequest(usage.MiMemory, opts, opts.MinMiMemory)
+					c.LimitsMiMemory = recommendLimit(requests.GetMiMemory(), limits.GetMiMemory(), c.RequestsMiMemory)
+				}
+			}
+			r.Containers = append(r.Containers, c)
+		}
+		recommendations = append(recommendations, r)
+	}
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		di, dj := recommendations[i].GetDeltaMilliCPU(), recommendations[j].GetDeltaMilliCPU()
+		if di != dj {
+			return di < dj
+		}
+		return recommendations[i].GetDeltaMiMemory() < recommendations[j].GetDeltaMiMemory()
+	})
+	return recommendations
+}
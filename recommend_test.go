@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestRecommendRequestAndLimit(t *testing.T) {
+	opts := RecommendOptions{Headroom: 20, Percentile: 90, MinMilliCPU: 10, MinMiMemory: 32}
+	usage := []int{100, 10, 20, 30, 40, 50, 60, 70, 80, 90}
+	if r := recommendRequest(usage, opts, opts.MinMilliCPU); r != 108 {
+		t.Fatalf("Test failed! %d but expected %d", r, 108)
+	}
+	if r := recommendRequest([]int{1, 2}, opts, opts.MinMilliCPU); r != 10 {
+		t.Fatalf("Test failed! %d but expected %d", r, 10)
+	}
+
+	tests := []struct {
+		request, limit, recommended, expected int
+	}{
+		{200, 300, 24, 36},
+		{100, 0, 50, 0},
+		{0, 500, 50, 500},
+		{0, 40, 50, 50},
+	}
+	for _, test := range tests {
+		if l := recommendLimit(test.request, test.limit, test.recommended); l != test.expected {
+			t.Fatalf("Test failed! %d but expected %d for %+v", l, test.expected, test)
+		}
+	}
+}
+
+func TestBuildRecommendations(t *testing.T) {
+	pods := loadPodsWithTop(t, "test-data/many-pods.json", "test-data/top-many-pods.txt")
+	topMap := make(map[string]Top)
+	for _, pod := range pods {
+		topMap[pod.GetPodKey()] = pod.Top
+	}
+	usage := BuildTopUsageSamples(pods, []map[string]Top{topMap})
+	opts := RecommendOptions{Headroom: 20, Percentile: 95, MinMilliCPU: 10, MinMiMemory: 32}
+	recommendations := BuildRecommendations(BuildWorkloads(pods, []Pdb{}), usage, opts)
+
+	var adservice WorkloadRecommendation
+	for _, r := range recommendations {
+		if r.Workload.GetWorkloadKey() == "default|Deployment/adservice" {
+			adservice = r
+		}
+	}
+	server, proxy := adservice.Containers[0], adservice.Containers[1]
+	if server.Spec.Name != "server" || server.ObservedMilliCPU != 20 || server.RequestsMilliCPU != 24 || server.LimitsMilliCPU != 36 ||
+		server.ObservedMiMemory != 185 || server.RequestsMiMemory != 222 || server.LimitsMiMemory != 370 {
+		t.Fatalf("Test failed! %+v", server)
+	}
+	if proxy.Spec.Name != "istio-proxy" || proxy.RequestsMilliCPU != 10 || proxy.LimitsMilliCPU != 200 || proxy.RequestsMiMemory != 47 || proxy.LimitsMiMemory != 47 {
+		t.Fatalf("Test failed! %+v", proxy)
+	}
+	if adservice.GetRequestsMilliCPU() != 600 || adservice.GetRecommendedRequestsMilliCPU() != 68 ||
+		adservice.GetDeltaMilliCPU() != -532 || adservice.GetConfidence() != ConfidenceLow {
+		t.Fatalf("Test failed! %d %d %s", adservice.GetRequestsMilliCPU(), adservice.GetRecommendedRequestsMilliCPU(), adservice.GetConfidence())
+	}
+	for i := 1; i < len(recommendations); i++ {
+		if recommendations[i-1].GetDeltaMilliCPU() > recommendations[i].GetDeltaMilliCPU() {
+			t.Fatalf("Test failed! not sorted by delta at %d", i)
+		}
+	}
+}
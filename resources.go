@@ -6,6 +6,7 @@ import (
 	"log"
 	"os/exec"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 )
@@ -24,9 +25,11 @@ type Pod struct {
 		ContainerStatuses []struct {
 			RestartCount int `json:"restartCount"`
 		} `json:"containerStatuses"`
-		Phase string
+		Phase    string
+		QosClass string `json:"qosClass"`
 	}
-	Top Top
+	Top   Top
+	Stats PodStats
 }
 
 // Condition struct
@@ -41,6 +44,7 @@ type Metadata struct {
 	Name            string
 	Namespace       string
 	Labels          map[string]string
+	Annotations     map[string]string
 	OwnerReferences []struct {
 		Kind string
 		Name string
@@ -49,56 +53,204 @@ type Metadata struct {
 
 // Spec struct
 type Spec struct {
-	NodeName   string
-	Containers []struct {
-		Name      string
-		Lifecycle struct {
-			PreStop struct {
-				Exec struct {
-					Command []string
-				}
-				HTTPGet struct {
-					Path string
-				}
-			}
-		}
-		LivenessProbe struct {
-			HTTPGet struct {
-				Path string `json:"path"`
-			} `json:"httpGet,omitempty"`
+	NodeName                  string
+	Containers                []ContainerSpec
+	PriorityClassName         string                     `json:"priorityClassName"`
+	Priority                  *int                       `json:"priority"`
+	TopologySpreadConstraints []TopologySpreadConstraint `json:"topologySpreadConstraints"`
+	NodeSelector              map[string]string          `json:"nodeSelector"`
+	Tolerations               []Toleration               `json:"tolerations"`
+	Affinity                  struct {
+		NodeAffinity struct {
+			Required struct {
+				NodeSelectorTerms []NodeSelectorTerm `json:"nodeSelectorTerms"`
+			} `json:"requiredDuringSchedulingIgnoredDuringExecution"`
+		} `json:"nodeAffinity"`
+		PodAntiAffinity struct {
+			Required  []PodAffinityTerm `json:"requiredDuringSchedulingIgnoredDuringExecution"`
+			Preferred []struct {
+				Weight          int             `json:"weight"`
+				PodAffinityTerm PodAffinityTerm `json:"podAffinityTerm"`
+			} `json:"preferredDuringSchedulingIgnoredDuringExecution"`
+		} `json:"podAntiAffinity"`
+	} `json:"affinity"`
+	Volumes []Volume `json:"volumes"`
+}
+
+// TopologySpreadConstraint struct
+type TopologySpreadConstraint struct {
+	MaxSkew           int    `json:"maxSkew"`
+	TopologyKey       string `json:"topologyKey"`
+	WhenUnsatisfiable string `json:"whenUnsatisfiable"`
+}
+
+// Volume struct, only the emptyDir source is decoded
+type Volume struct {
+	Name     string    `json:"name"`
+	EmptyDir *struct{} `json:"emptyDir"`
+}
+
+// PodAffinityTerm struct
+type PodAffinityTerm struct {
+	TopologyKey string `json:"topologyKey"`
+}
+
+// ContainerSpec struct
+type ContainerSpec struct {
+	Name      string
+	Image     string
+	Lifecycle struct {
+		PreStop struct {
 			Exec struct {
-				Command []string `json:"command"`
-			} `json:"exec,omitempty"`
-			FailureThreshold    int `json:"failureThreshold"`
-			InitialDelaySeconds int `json:"initialDelaySeconds"`
-			PeriodSeconds       int `json:"periodSeconds"`
-			SuccessThreshold    int `json:"successThreshold"`
-			TimeoutSeconds      int `json:"timeoutSeconds"`
-		} `json:"livenessProbe,omitempty"`
-		ReadinessProbe struct {
+				Command []string
+			}
 			HTTPGet struct {
-				Path string `json:"path"`
-			} `json:"httpGet,omitempty"`
-			Exec struct {
-				Command []string `json:"command"`
-			} `json:"exec,omitempty"`
-			FailureThreshold    int `json:"failureThreshold"`
-			InitialDelaySeconds int `json:"initialDelaySeconds"`
-			PeriodSeconds       int `json:"periodSeconds"`
-			SuccessThreshold    int `json:"successThreshold"`
-			TimeoutSeconds      int `json:"timeoutSeconds"`
-		} `json:"readinessProbe,omitempty"`
-		Resources struct {
-			Requests Resource
-			Limits   Resource
+				Path string
+			}
 		}
 	}
+	LivenessProbe  Probe `json:"livenessProbe,omitempty"`
+	ReadinessProbe Probe `json:"readinessProbe,omitempty"`
+	StartupProbe   Probe `json:"startupProbe,omitempty"`
+	Resources      struct {
+		Requests Resource
+		Limits   Resource
+	}
+}
+
+// Probe struct
+type Probe struct {
+	HTTPGet struct {
+		Path string      `json:"path"`
+		Port IntOrString `json:"port"`
+	} `json:"httpGet,omitempty"`
+	Exec struct {
+		Command []string `json:"command"`
+	} `json:"exec,omitempty"`
+	TCPSocket           *struct{} `json:"tcpSocket,omitempty"`
+	GRPC                *struct{} `json:"grpc,omitempty"`
+	FailureThreshold    int       `json:"failureThreshold"`
+	InitialDelaySeconds int       `json:"initialDelaySeconds"`
+	PeriodSeconds       int       `json:"periodSeconds"`
+	SuccessThreshold    int       `json:"successThreshold"`
+	TimeoutSeconds      int       `json:"timeoutSeconds"`
+}
+
+// IsSet returns true if the probe has an http get path, an exec command, a tcp socket or a grpc port
+func (p Probe) IsSet() bool {
+	return p.HTTPGet.Path != "" || p.Exec.Command != nil || p.TCPSocket != nil || p.GRPC != nil
+}
+
+// String returns "HttpGet: <path>", "Exec: <command>", "TcpSocket" or "Grpc", empty otherwise
+func (p Probe) String() string {
+	if p.HTTPGet.Path != "" {
+		return "HttpGet: " + p.HTTPGet.Path
+	} else if p.Exec.Command != nil {
+		return "Exec: " + strings.Join(p.Exec.Command, " ")
+	} else if p.TCPSocket != nil {
+		return "TcpSocket"
+	} else if p.GRPC != nil {
+		return "Grpc"
+	}
+	return ""
+}
+
+// Default probe timings of kubernetes, used when the field is not set
+const (
+	defaultProbePeriodSeconds    = 10
+	defaultProbeFailureThreshold = 3
+	defaultProbeTimeoutSeconds   = 1
+)
+
+// GetPeriodSeconds ..
+func (p Probe) GetPeriodSeconds() int {
+	if p.PeriodSeconds == 0 {
+		return defaultProbePeriodSeconds
+	}
+	return p.PeriodSeconds
+}
+
+// GetFailureThreshold ..
+func (p Probe) GetFailureThreshold() int {
+	if p.FailureThreshold == 0 {
+		return defaultProbeFailureThreshold
+	}
+	return p.FailureThreshold
+}
+
+// GetTimeoutSeconds ..
+func (p Probe) GetTimeoutSeconds() int {
+	if p.TimeoutSeconds == 0 {
+		return defaultProbeTimeoutSeconds
+	}
+	return p.TimeoutSeconds
+}
+
+// GetFailureSeconds returns initialDelaySeconds + periodSeconds * failureThreshold, the earliest time a failing probe acts
+func (p Probe) GetFailureSeconds() int {
+	return p.InitialDelaySeconds + p.GetPeriodSeconds()*p.GetFailureThreshold()
+}
+
+// GetTimings returns "<initial delay>s/<period>s/<failure threshold>/<timeout>s", empty if the probe is not set
+func (p Probe) GetTimings() string {
+	if !p.IsSet() {
+		return ""
+	}
+	return fmt.Sprintf("%ds/%ds/%d/%ds", p.InitialDelaySeconds, p.GetPeriodSeconds(), p.GetFailureThreshold(), p.GetTimeoutSeconds())
+}
+
+// GetEndpoint returns the port and path of http probes and the command of exec probes, empty otherwise
+func (p Probe) GetEndpoint() string {
+	if p.HTTPGet.Path != "" {
+		return p.HTTPGet.Port.String() + p.HTTPGet.Path
+	} else if p.Exec.Command != nil {
+		return strings.Join(p.Exec.Command, " ")
+	}
+	return ""
+}
+
+// HasPreStop ..
+func (c ContainerSpec) HasPreStop() bool {
+	return c.Lifecycle.PreStop.HTTPGet.Path != "" || c.Lifecycle.PreStop.Exec.Command != nil
+}
+
+// GetPreStop returns "HttpGet: <path>" or "Exec: <command>", empty otherwise
+func (c ContainerSpec) GetPreStop() string {
+	if c.Lifecycle.PreStop.HTTPGet.Path != "" {
+		return "HttpGet: " + c.Lifecycle.PreStop.HTTPGet.Path
+	} else if c.Lifecycle.PreStop.Exec.Command != nil {
+		return "Exec: " + strings.Join(c.Lifecycle.PreStop.Exec.Command, " ")
+	}
+	return ""
 }
 
 // Resource struct
 type Resource struct {
-	CPU    string
-	Memory string
+	CPU              string
+	Memory           string
+	EphemeralStorage string `json:"ephemeral-storage"`
+	// Extended keeps every other resource, eg. nvidia.com/gpu or hugepages-2Mi
+	Extended map[string]string
+}
+
+// UnmarshalJSON reads the resource list as a generic map
+func (r *Resource) UnmarshalJSON(data []byte) error {
+	values := make(map[string]string)
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*r = Resource{
+		CPU:              values["cpu"],
+		Memory:           values["memory"],
+		EphemeralStorage: values["ephemeral-storage"],
+		Extended:         extendedResources(values),
+	}
+	return nil
+}
+
+// GetExtended returns the quantity of an extended resource, hugepages in Mi
+func (r Resource) GetExtended(name string) int {
+	return String2Quantity(name, r.Extended[name])
 }
 
 // GetMilliCPU returns the CPU in MilliCPU
@@ -111,6 +263,11 @@ func (r Resource) GetMiMemory() int {
 	return String2MiMemory(r.Memory)
 }
 
+// GetMiEphemeralStorage returns the ephemeral storage in Mi
+func (r Resource) GetMiEphemeralStorage() int {
+	return String2MiMemory(r.EphemeralStorage)
+}
+
 // GetPodKey returns <namespace>-<pod name>
 func (p Pod) GetPodKey() string {
 	return p.Metadata.Namespace + "|" + p.Metadata.Name
@@ -128,11 +285,7 @@ func (p Pod) GetReplicaSetKey() string {
 
 // GetStartupDuration returns the best effort for geting startup time (ready - schedule), 0 otherwise
 func (p Pod) GetStartupDuration() time.Duration {
-	restartCount := 0
-	for _, cs := range p.Status.ContainerStatuses {
-		restartCount = restartCount + cs.RestartCount
-	}
-
+	restartCount := p.GetRestartCount()
 	ready := p.findStatusCondition(func(c Condition) bool { return c.Status == "True" && c.Type == "Ready" })
 	podScheduled := p.findStatusCondition(func(c Condition) bool { return c.Status == "True" && c.Type == "PodScheduled" })
 	if restartCount > 0 || ready.Status == "NA" || podScheduled.Status == "NA" {
@@ -147,6 +300,39 @@ func (p Pod) GetStartupDuration() time.Duration {
 	return diff
 }
 
+// GetRestartCount returns the restarts of all the containers of the pod
+func (p Pod) GetRestartCount() int {
+	restartCount := 0
+	for _, cs := range p.Status.ContainerStatuses {
+		restartCount = restartCount + cs.RestartCount
+	}
+	return restartCount
+}
+
+// GetStartupPhases splits the startup duration: scheduled -> initialized (init containers and volumes) and
+// initialized -> containers ready (image pull, container start and readiness probe)
+// ok is false when the pod has no startup duration, see GetStartupDuration
+func (p Pod) GetStartupPhases() (initialization time.Duration, containers time.Duration, ok bool) {
+	if p.GetStartupDuration() == time.Duration(0) {
+		return 0, 0, false
+	}
+	scheduled := p.findStatusCondition(func(c Condition) bool { return c.Status == "True" && c.Type == "PodScheduled" })
+	initialized := p.findStatusCondition(func(c Condition) bool { return c.Status == "True" && c.Type == "Initialized" })
+	containersReady := p.findStatusCondition(func(c Condition) bool { return c.Status == "True" && c.Type == "ContainersReady" })
+	if initialized.Status == "NA" || containersReady.Status == "NA" {
+		return 0, 0, false
+	}
+	initialization = initialized.LastTransitionTime.Sub(scheduled.LastTransitionTime)
+	if initialization < 0 {
+		initialization = 0
+	}
+	containers = containersReady.LastTransitionTime.Sub(initialized.LastTransitionTime)
+	if containers < 0 {
+		containers = 0
+	}
+	return initialization, containers, true
+}
+
 func (p Pod) findStatusCondition(test func(Condition) bool) Condition {
 	for _, c := range p.Status.Conditions {
 		if test(c) {
@@ -189,6 +375,68 @@ func (p Pod) GetReplicaSetName() string {
 	return p.Metadata.OwnerReferences[0].Name
 }
 
+const cronJobPattern = `^(.*)-(\d+)$`
+
+// GetWorkloadKind returns the kind of the controller that created the pod (Deployment, StatefulSet, DaemonSet, Job, CronJob) or Pod if none
+// ReplicaSets are assumed to be managed by a Deployment and Jobs with a numeric suffix are assumed to be created by a CronJob
+func (p Pod) GetWorkloadKind() string {
+	if len(p.Metadata.OwnerReferences) == 0 {
+		return "Pod"
+	}
+	owner := p.Metadata.OwnerReferences[0]
+	switch owner.Kind {
+	case "ReplicaSet":
+		return "Deployment"
+	case "Job":
+		if match, _ := regexp.MatchString(cronJobPattern, owner.Name); match {
+			return "CronJob"
+		}
+		return "Job"
+	default:
+		return owner.Kind
+	}
+}
+
+// GetWorkloadName returns the name of the controller that created the pod, or the pod name if none
+func (p Pod) GetWorkloadName() string {
+	switch p.GetWorkloadKind() {
+	case "Pod":
+		return p.Metadata.Name
+	case "Deployment":
+		return p.GetDeploymentName()
+	case "CronJob":
+		reg, _ := regexp.Compile(cronJobPattern)
+		return reg.FindStringSubmatch(p.Metadata.OwnerReferences[0].Name)[1]
+	default:
+		return p.Metadata.OwnerReferences[0].Name
+	}
+}
+
+// GetWorkloadKey returns <namespace>|<workload kind>/<workload name>
+func (p Pod) GetWorkloadKey() string {
+	return p.Metadata.Namespace + "|" + p.GetWorkloadKind() + "/" + p.GetWorkloadName()
+}
+
+// HasRequests returns true if all containers define both cpu and memory requests
+func (p Pod) HasRequests() bool {
+	for _, c := range p.Spec.Containers {
+		if c.Resources.Requests.CPU == "" || c.Resources.Requests.Memory == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// HasProbes returns true if all containers define at least a liveness or a readiness probe
+func (p Pod) HasProbes() bool {
+	for _, c := range p.Spec.Containers {
+		if !c.LivenessProbe.IsSet() && !c.ReadinessProbe.IsSet() {
+			return false
+		}
+	}
+	return true
+}
+
 // GetRequestsMilliCPU total
 func (p Pod) GetRequestsMilliCPU() int {
 	total := 0
@@ -259,12 +507,143 @@ func (p Pod) GetLimitsMiMemory() int {
 	return total
 }
 
+// HasLocalStorage returns true if the pod has an emptyDir volume, its data is lost when the pod is evicted
+func (p Pod) HasLocalStorage() bool {
+	for _, v := range p.Spec.Volumes {
+		if v.EmptyDir != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// GetQosClass returns status.qosClass, or computes it from the containers resources when not reported
+func (p Pod) GetQosClass() string {
+	if p.Status.QosClass != "" {
+		return p.Status.QosClass
+	}
+	bestEffort, guaranteed := true, true
+	for _, c := range p.Spec.Containers {
+		requests, limits := c.Resources.Requests, c.Resources.Limits
+		if requests.CPU != "" || requests.Memory != "" || limits.CPU != "" || limits.Memory != "" {
+			bestEffort = false
+		}
+		if limits.CPU == "" || limits.Memory == "" ||
+			(requests.CPU != "" && requests.GetMilliCPU() != limits.GetMilliCPU()) ||
+			(requests.Memory != "" && requests.GetMiMemory() != limits.GetMiMemory()) {
+			guaranteed = false
+		}
+	}
+	if bestEffort {
+		return "BestEffort"
+	} else if guaranteed {
+		return "Guaranteed"
+	}
+	return "Burstable"
+}
+
+// GetPriority returns spec.priority, 0 if not set
+func (p Pod) GetPriority() int {
+	if p.Spec.Priority == nil {
+		return 0
+	}
+	return *p.Spec.Priority
+}
+
+// GetRequestsMiEphemeralStorage total
+func (p Pod) GetRequestsMiEphemeralStorage() int {
+	total := 0
+	for _, c := range p.Spec.Containers {
+		total += c.Resources.Requests.GetMiEphemeralStorage()
+	}
+	return total
+}
+
+// GetLimitsMiEphemeralStorage total
+func (p Pod) GetLimitsMiEphemeralStorage() int {
+	total := 0
+	for _, c := range p.Spec.Containers {
+		total += c.Resources.Limits.GetMiEphemeralStorage()
+	}
+	return total
+}
+
+// GetRequestsExtended returns the quantity requested by all containers
+// extended resources can't be overcommitted, so the limit is used when the request is not set
+func (p Pod) GetRequestsExtended(name string) int {
+	total := 0
+	for _, c := range p.Spec.Containers {
+		if _, ok := c.Resources.Requests.Extended[name]; ok {
+			total += c.Resources.Requests.GetExtended(name)
+		} else {
+			total += c.Resources.Limits.GetExtended(name)
+		}
+	}
+	return total
+}
+
+// GetRequestsGpus total
+func (p Pod) GetRequestsGpus() int {
+	total := 0
+	for _, name := range p.GetExtendedResourceNames() {
+		if isGpuResource(name) {
+			total += p.GetRequestsExtended(name)
+		}
+	}
+	return total
+}
+
+// GetExtendedResourceNames returns the sorted names of the extended resources requested or limited by the containers
+func (p Pod) GetExtendedResourceNames() []string {
+	nameMap := make(map[string]bool)
+	for _, c := range p.Spec.Containers {
+		for name := range c.Resources.Requests.Extended {
+			nameMap[name] = true
+		}
+		for name := range c.Resources.Limits.Extended {
+			nameMap[name] = true
+		}
+	}
+	names := []string{}
+	for name := range nameMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetExtendedResources returns eg. "hugepages-2Mi=256Mi,nvidia.com/gpu=1"
+func (p Pod) GetExtendedResources() string {
+	values := make(map[string]int)
+	for _, name := range p.GetExtendedResourceNames() {
+		values[name] = p.GetRequestsExtended(name)
+	}
+	return formatExtendedResources(values)
+}
+
+// GetUsedMiEphemeralStorage usage reported by the kubelet stats summary
+func (p Pod) GetUsedMiEphemeralStorage() int {
+	return p.Stats.EphemeralStorage.GetUsedMi()
+}
+
+// GetUsageEphemeralStorage %
+func (p Pod) GetUsageEphemeralStorage() float32 {
+	used := float32(p.GetUsedMiEphemeralStorage())
+	requests := float32(p.GetRequestsMiEphemeralStorage())
+	if used == 0 && requests != 0 {
+		return 0
+	} else if requests == 0 {
+		return 100
+	}
+	return used / requests * 100
+}
+
 // CountLivenessProbes ..
 func (p Pod) CountLivenessProbes() string {
 	numContainers := len(p.Spec.Containers)
 	numLiveness := 0
 	for _, c := range p.Spec.Containers {
-		if c.LivenessProbe.HTTPGet.Path != "" || c.LivenessProbe.Exec.Command != nil {
+		if c.LivenessProbe.IsSet() {
 			numLiveness = numLiveness + 1
 		}
 	}
@@ -276,7 +655,7 @@ func (p Pod) CountReadinessProbes() string {
 	numContainers := len(p.Spec.Containers)
 	numReadiness := 0
 	for _, c := range p.Spec.Containers {
-		if c.ReadinessProbe.HTTPGet.Path != "" || c.ReadinessProbe.Exec.Command != nil {
+		if c.ReadinessProbe.IsSet() {
 			numReadiness = numReadiness + 1
 		}
 	}
@@ -288,7 +667,7 @@ func (p Pod) CountLifecyclePreStop() string {
 	numContainers := len(p.Spec.Containers)
 	preStop := 0
 	for _, c := range p.Spec.Containers {
-		if c.Lifecycle.PreStop.HTTPGet.Path != "" || c.Lifecycle.PreStop.Exec.Command != nil {
+		if c.HasPreStop() {
 			preStop = preStop + 1
 		}
 	}
@@ -302,13 +681,7 @@ func (p Pod) GetLivenessProbes() string {
 		if len(str) > 0 {
 			str += "\n"
 		}
-		str += c.Name + " {"
-		if c.LivenessProbe.HTTPGet.Path != "" {
-			str += "HttpGet: " + c.LivenessProbe.HTTPGet.Path
-		} else if c.LivenessProbe.Exec.Command != nil {
-			str += "Exec: " + strings.Join(c.LivenessProbe.Exec.Command, " ")
-		}
-		str += "}"
+		str += c.Name + " {" + c.LivenessProbe.String() + "}"
 	}
 	return str
 }
@@ -320,13 +693,7 @@ func (p Pod) GetReadinessProbes() string {
 		if len(str) > 0 {
 			str += "\n"
 		}
-		str += c.Name + " {"
-		if c.ReadinessProbe.HTTPGet.Path != "" {
-			str += "HttpGet: " + c.ReadinessProbe.HTTPGet.Path
-		} else if c.ReadinessProbe.Exec.Command != nil {
-			str += "Exec: " + strings.Join(c.ReadinessProbe.Exec.Command, " ")
-		}
-		str += "}"
+		str += c.Name + " {" + c.ReadinessProbe.String() + "}"
 	}
 	return str
 }
@@ -339,13 +706,7 @@ func (p Pod) GetLifecyclePreStop() string {
 		if len(str) > 0 {
 			str += "\n"
 		}
-		str += c.Name + " {"
-		if c.Lifecycle.PreStop.HTTPGet.Path != "" {
-			str += "HttpGet: " + c.Lifecycle.PreStop.HTTPGet.Path
-		} else if c.Lifecycle.PreStop.Exec.Command != nil {
-			str += "Exec: " + strings.Join(c.Lifecycle.PreStop.Exec.Command, " ")
-		}
-		str += "}"
+		str += c.Name + " {" + c.GetPreStop() + "}"
 	}
 	return str
 }
@@ -184,3 +184,19 @@ func TestBuildKubectlCmd(t *testing.T) {
 		}
 	}
 }
+
+func TestGetQosClass(t *testing.T) {
+	b, err := ioutil.ReadFile("test-data/one-pod.json")
+	if err != nil {
+		fmt.Print(err)
+	}
+	pod := buildPodList(string(b)).Items[0]
+	if qos := pod.GetQosClass(); qos != "Burstable" {
+		t.Fatalf("Test failed! %s but expected %s", qos, "Burstable")
+	}
+
+	pod.Status.QosClass = ""
+	if qos := pod.GetQosClass(); qos != "Burstable" {
+		t.Fatalf("Test failed! computed %s but expected %s", qos, "Burstable")
+	}
+}
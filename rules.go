@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rule scopes, detected from the variables used by the expression
+const (
+	RuleScopeWorkload  = "workload"
+	RuleScopeContainer = "container"
+	RuleScopeNode      = "node"
+)
+
+// Rule user-defined rule of the -rules file
+// the message is a text/template evaluated with the same variables as the expression, eg. {{.workload.name}}
+type Rule struct {
+	ID         string `yaml:"id"`
+	Severity   string `yaml:"severity"`
+	Expression string `yaml:"expression"`
+	Message    string `yaml:"message"`
+	scope      string
+	expr       exprNode
+	template   *template.Template
+}
+
+// RulesFile ..
+type RulesFile struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// LoadRules reads and validates the rules file
+func LoadRules(file string) []Rule {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		log.Fatalf("Failed to read rules file: %s", err)
+	}
+	rules, err := buildRules(string(b))
+	if err != nil {
+		log.Fatalf("Invalid rules file %s: %s", file, err)
+	}
+	return rules
+}
+
+func buildRules(str string) ([]Rule, error) {
+	f := RulesFile{}
+	if err := yaml.Unmarshal([]byte(str), &f); err != nil {
+		return nil, err
+	}
+	for i := range f.Rules {
+		r := &f.Rules[i]
+		if r.ID == "" {
+			return nil, fmt.Errorf("rule %d has no id", i+1)
+		}
+		if severityLevel(r.Severity) == -1 {
+			return nil, fmt.Errorf("rule %s: invalid severity %q, valid values info|warning|error", r.ID, r.Severity)
+		}
+		expr, err := ParseExpression(r.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %s", r.ID, err)
+		}
+		r.expr = expr
+		if r.scope, err = getRuleScope(r.Expression); err != nil {
+			return nil, fmt.Errorf("rule %s: %s", r.ID, err)
+		}
+		if err := checkRuleExpression(r.expr, ruleSchemaEnv(r.scope)); err != nil {
+			return nil, fmt.Errorf("rule %s: %s", r.ID, err)
+		}
+		if r.Message == "" {
+			r.Message = r.Expression
+		}
+		if r.template, err = template.New(r.ID).Parse(r.Message); err != nil {
+			return nil, fmt.Errorf("rule %s: %s", r.ID, err)
+		}
+	}
+	return f.Rules, nil
+}
+
+// getRuleScope returns node if the expression uses node variables, container if it uses container variables, workload otherwise
+// node variables can't be mixed with workload and container variables
+func getRuleScope(expression string) (string, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return "", err
+	}
+	roots := make(map[string]bool)
+	for _, t := range tokens {
+		if t.kind == "ident" {
+			roots[strings.Split(t.value, ".")[0]] = true
+		}
+	}
+	switch {
+	case roots[RuleScopeNode] && (roots[RuleScopeWorkload] || roots[RuleScopeContainer] || roots["pod"]):
+		return "", fmt.Errorf("node variables can't be used with workload, pod or container variables")
+	case roots[RuleScopeNode]:
+		return RuleScopeNode, nil
+	case roots[RuleScopeContainer]:
+		return RuleScopeContainer, nil
+	default:
+		return RuleScopeWorkload, nil
+	}
+}
+
+// ruleSchemaEnv returns the variables of the scope with zero values, used to validate the expressions
+func ruleSchemaEnv(scope string) map[string]interface{} {
+	if scope == RuleScopeNode {
+		return nodeEnv(Node{})
+	}
+	env := workloadEnv(Workload{Pods: []Pod{{}}}, lintContext{})
+	if scope == RuleScopeContainer {
+		env = containerEnv(env, PodContainer{})
+	}
+	return env
+}
+
+// checkRuleExpression returns an error for unknown variables, objects used as values, type errors and non boolean expressions
+// the types are inferred from the schema without evaluating the expression, so every branch is checked
+func checkRuleExpression(expr exprNode, schema map[string]interface{}) error {
+	t, err := exprType(expr, schema)
+	if err != nil {
+		return err
+	}
+	if t != exprBool {
+		return fmt.Errorf("the expression must be true or false, found a %s", t)
+	}
+	return nil
+}
+
+// evaluate returns the message if the expression is true
+// a division by zero doesn't produce a finding, the other evaluation errors are returned
+func (r Rule) evaluate(env map[string]interface{}) (string, bool, error) {
+	value, err := r.expr.eval(env)
+	if err == errDivisionByZero {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	if value != true {
+		return "", false, nil
+	}
+	var b bytes.Buffer
+	if err := r.template.Execute(&b, env); err != nil {
+		return r.Message, true, nil
+	}
+	return b.String(), true, nil
+}
+
+// labelsEnv converts labels to expression values
+func labelsEnv(labels map[string]string) map[string]interface{} {
+	env := make(map[string]interface{})
+	for k, v := range labels {
+		env[k] = v
+	}
+	return env
+}
+
+// cpuMemoryEnv returns {"cpu": <m>, "memory": <Mi>}
+func cpuMemoryEnv(milliCPU int, miMemory int) map[string]interface{} {
+	return map[string]interface{}{"cpu": float64(milliCPU), "memory": float64(miMemory)}
+}
+
+// workloadEnv variables of a workload and of its first pod
+func workloadEnv(w Workload, ctx lintContext) map[string]interface{} {
+	wp := Wrapper{Pods: w.Pods}
+	pod := w.Pods[0]
+	return map[string]interface{}{
+		"workload": map[string]interface{}{
+			"namespace":             w.Namespace,
+			"kind":                  w.Kind,
+			"name":                  w.Name,
+			"replicas":              float64(len(w.Pods)),
+			"containers":            float64(len(pod.Spec.Containers)),
+			"hasPdb":                w.HasPdb(),
+			"hasHpa":                ctx.hpaTargets[w.GetWorkloadKey()],
+			"pdbDisruptionsAllowed": float64(w.Pdb.Status.DisruptionsAllowed),
+			"qosClass":              w.GetQosClass(),
+			"priorityClass":         w.GetPriorityClassName(),
+			"labels":                labelsEnv(pod.Metadata.Labels),
+			"requests":              cpuMemoryEnv(wp.GetRequestsMilliCPU(), wp.GetRequestsMiMemory()),
+			"limits":                cpuMemoryEnv(wp.GetLimitsMilliCPU(), wp.GetLimitsMiMemory()),
+			"usage":                 cpuMemoryEnv(wp.GetTopMilliCPU(), wp.GetTopMiMemory()),
+		},
+		"pod": map[string]interface{}{
+			"name":        pod.Metadata.Name,
+			"nodeName":    pod.Spec.NodeName,
+			"priority":    float64(pod.GetPriority()),
+			"annotations": labelsEnv(pod.Metadata.Annotations),
+		},
+	}
+}
+
+// containerEnv adds the variables of a container of the first pod to the workload ones
+func containerEnv(env map[string]interface{}, c PodContainer) map[string]interface{} {
+	containerEnv := make(map[string]interface{})
+	for k, v := range env {
+		containerEnv[k] = v
+	}
+	containerEnv["container"] = map[string]interface{}{
+		"name":              c.Spec.Name,
+		"image":             c.Spec.Image,
+		"tag":               getImageTag(c.Spec.Image),
+		"hasReadinessProbe": c.Spec.ReadinessProbe.IsSet(),
+		"hasLivenessProbe":  c.Spec.LivenessProbe.IsSet(),
+		"hasPreStop":        c.Spec.HasPreStop(),
+		"requests":          cpuMemoryEnv(c.Spec.Resources.Requests.GetMilliCPU(), c.Spec.Resources.Requests.GetMiMemory()),
+		"limits":            cpuMemoryEnv(c.Spec.Resources.Limits.GetMilliCPU(), c.Spec.Resources.Limits.GetMiMemory()),
+		"usage":             cpuMemoryEnv(c.GetTopMilliCPU(), c.GetTopMiMemory()),
+	}
+	return containerEnv
+}
+
+// nodeEnv variables of a node
+func nodeEnv(n Node) map[string]interface{} {
+	w := Wrapper{Pods: n.Pods}
+	allocatable := cpuMemoryEnv(n.GetAllocatableMilliCPU(), n.GetAllocatableMiMemory())
+	allocatable["pods"] = float64(n.GetAllocatablePods())
+	return map[string]interface{}{
+		"node": map[string]interface{}{
+			"name":          n.GetName(),
+			"nodepool":      n.GetNodepool(),
+			"instanceType":  n.GetInstanceType(),
+			"zone":          n.GetZone(),
+			"pods":          float64(len(n.Pods)),
+			"ready":         n.IsReady(),
+			"unschedulable": n.IsUnschedulable(),
+			"spot":          n.IsSpot(),
+			"labels":        labelsEnv(n.Metadata.Labels),
+			"allocatable":   allocatable,
+			"requests":      cpuMemoryEnv(w.GetRequestsMilliCPU(), w.GetRequestsMiMemory()),
+			"usage":         cpuMemoryEnv(n.GetTopMilliCPU(), n.GetTopMiMemory()),
+		},
+	}
+}
+
+// BuildRuleFindings evaluates the user-defined rules on each workload, each container of the first pod of each workload and each node
+// static pods are ignored, as in the built-in rules
+func BuildRuleFindings(rules []Rule, workloads []Workload, hpaList []Hpa, nodeList []Node) []Finding {
+	ctx := newLintContext(hpaList)
+	findings := []Finding{}
+	reported := make(map[string]bool)
+	add := func(r Rule, namespace string, object string, env map[string]interface{}) {
+		message, ok, err := r.evaluate(env)
+		if err != nil {
+			// reported once per rule and error on stderr, so the output stays usable
+			if key := r.ID + "|" + err.Error(); !reported[key] {
+				reported[key] = true
+				log.Printf("Warning: rule %s can't be evaluated on %s: %s", r.ID, object, err)
+			}
+			return
+		}
+		if ok {
+			findings = append(findings, Finding{ID: r.ID, Severity: r.Severity, Namespace: namespace, Object: object, Message: message})
+		}
+	}
+	for _, w := range workloads {
+		if w.Kind == "Node" || len(w.Pods) == 0 {
+			continue
+		}
+		env := workloadEnv(w, ctx)
+		for _, r := range rules {
+			switch r.scope {
+			case RuleScopeWorkload:
+				add(r, w.Namespace, w.GetReference(), env)
+			case RuleScopeContainer:
+				for _, c := range BuildPodContainers(w.Pods[:1]) {
+					add(r, w.Namespace, w.GetReference(), containerEnv(env, c))
+				}
+			}
+		}
+	}
+	for _, n := range nodeList {
+		env := nodeEnv(n)
+		for _, r := range rules {
+			if r.scope == RuleScopeNode {
+				add(r, "", "Node/"+n.GetName(), env)
+			}
+		}
+	}
+	sortFindings(findings)
+	return findings
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestBuildRules(t *testing.T) {
+	invalid := []string{
+		"rules:\n- id: A\n  severity: fatal\n  expression: workload.replicas > 1",
+		"rules:\n- id: A\n  severity: error\n  expression: workload.replicas >",
+		"rules:\n- id: A\n  severity: error\n  expression: node.pods > 1 && workload.replicas > 1",
+		"rules:\n- id: A\n  severity: error\n  expression: workload.replicas > 1\n  message: '{{.workload.name'",
+		"rules:\n- id: A\n  severity: error\n  expression: workload.replicaz > 0",
+		"rules:\n- id: A\n  severity: error\n  expression: workload.replicas > 1 || container.limit.memory > 1",
+		"rules:\n- id: A\n  severity: error\n  expression: workload.requests > 1",
+		"rules:\n- id: A\n  severity: error\n  expression: workload.name > 1",
+		"rules:\n- id: A\n  severity: error\n  expression: workload.replicas + 1",
+		"rules:\n- id: A\n  severity: error\n  expression: node.labels.pool == '' && node.pods > 'x'",
+		"rules:\n- id: A\n  severity: error\n  expression: workload.hasPdb && workload.name > 3",
+		"rules:\n- id: A\n  severity: error\n  expression: workload.hasPdb || !workload.replicas",
+		"rules:\n- id: A\n  severity: error\n  expression: container.limits.memory / container.requests.memory > 'x'",
+		"rules:\n- id: A\n  severity: error\n  expression: workload.labels.team == 1",
+		"rules:\n- id: A\n  severity: error\n  expression: contains(workload.name, workload.replicas)",
+		"rules:\n- id: A\n  severity: error\n  expression: matches(workload.name, '[')",
+		"rules:\n- id: A\n  severity: error\n  expression: workload.hasPdb > false",
+	}
+	for _, str := range invalid {
+		if _, err := buildRules(str); err == nil {
+			t.Fatalf("Test failed! %s must be invalid", str)
+		}
+	}
+
+	valid := []string{
+		"rules:\n- id: A\n  severity: error\n  expression: container.limits.memory / container.requests.memory > 4",
+		"rules:\n- id: A\n  severity: error\n  expression: workload.labels['cost-center'] == '' && pod.annotations.owner == ''",
+		"rules:\n- id: A\n  severity: error\n  expression: workload.replicas % 0.5 > 0",
+		"rules:\n- id: A\n  severity: error\n  expression: -workload.replicas < 0 && (workload.name + '-x' == 'api-x' || matches(workload.labels.team, '^pay'))",
+	}
+	for _, str := range valid {
+		if _, err := buildRules(str); err != nil {
+			t.Fatalf("Test failed! %s: %s", str, err)
+		}
+	}
+}
+
+func TestBuildRuleFindings(t *testing.T) {
+	b, err := ioutil.ReadFile("test-data/rules.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, err := buildRules(string(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules[0].scope != RuleScopeWorkload || rules[1].scope != RuleScopeContainer || rules[4].scope != RuleScopeNode {
+		t.Fatalf("Test failed! %+v", rules)
+	}
+
+	pods := buildPodList(`{"items": [
+		{"metadata": {"name": "api-7d9f-xk2lp", "namespace": "shop", "labels": {"app": "api", "cost-center": "cc-100"}, "ownerReferences": [{"kind": "ReplicaSet", "name": "api-7d9f"}]},
+		 "spec": {"nodeName": "node-a", "containers": [{"name": "server", "image": "gcr.io/acme/api:1.0", "resources": {"requests": {"memory": "128Mi"}, "limits": {"memory": "1Gi"}}},
+		  {"name": "proxy", "image": "envoyproxy/envoy:v1.18", "resources": {"limits": {"memory": "128Mi"}}}]}},
+		{"metadata": {"name": "api-7d9f-pq7rt", "namespace": "shop", "labels": {"app": "api", "cost-center": "cc-100"}, "ownerReferences": [{"kind": "ReplicaSet", "name": "api-7d9f"}]},
+		 "spec": {"nodeName": "node-a", "containers": [{"name": "server", "image": "gcr.io/acme/api:1.0"}]}},
+		{"metadata": {"name": "report-1572566400-z3z3z", "namespace": "batch", "ownerReferences": [{"kind": "Job", "name": "report-1572566400"}]},
+		 "spec": {"containers": [{"name": "report", "image": "gcr.io/acme/report:2.0"}]}}
+	]}`).Items
+	nodes := buildNodeList(`{"items": [
+		{"metadata": {"name": "node-a"}, "spec": {"unschedulable": true}},
+		{"metadata": {"name": "node-b"}, "spec": {"unschedulable": true}}
+	]}`).Items
+	nodes[0].Pods = pods[:2]
+
+	findings := BuildRuleFindings(rules, BuildWorkloads(pods, []Pdb{}), []Hpa{}, nodes)
+	expected := []string{
+		" Node/node-a TEAM-NODE-CORDONED node node-a is cordoned with 2 pods",
+		"batch CronJob/report TEAM-COST-CENTER CronJob/report has no cost-center label",
+		"shop Deployment/api TEAM-MEMORY-LIMIT-RATIO container server limit is more than 4x its request",
+		"shop Deployment/api TEAM-PDB-REQUIRED api runs 2 replicas without pdb",
+		"shop Deployment/api TEAM-PRIVATE-REGISTRY image envoyproxy/envoy:v1.18 is not from the private registry",
+	}
+	if len(findings) != len(expected) {
+		t.Fatalf("Test failed! found %d expected %d: %+v", len(findings), len(expected), findings)
+	}
+	for i, f := range findings {
+		if actual := f.Namespace + " " + f.Object + " " + f.ID + " " + f.Message; actual != expected[i] {
+			t.Fatalf("Test failed! found %s expected %s", actual, expected[i])
+		}
+	}
+}
+
+func TestRuleEvaluateErrors(t *testing.T) {
+	rules, err := buildRules("rules:\n- id: A\n  severity: error\n  expression: matches(workload.name, workload.labels.pattern)\n- id: B\n  severity: error\n  expression: workload.replicas / workload.containers > 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	workload := Workload{Namespace: "shop", Kind: "Deployment", Name: "api", Pods: buildPodList(`{"items": [{"metadata": {"name": "api-7d9f-xk2lp", "labels": {"pattern": "["}}}]}`).Items}
+	env := workloadEnv(workload, lintContext{})
+	if _, ok, err := rules[0].evaluate(env); ok || err == nil {
+		t.Fatalf("Test failed! invalid regex must be reported %v %v", ok, err)
+	}
+	// a division by zero is not an error, the rule just doesn't fire
+	if _, ok, err := rules[1].evaluate(env); ok || err != nil {
+		t.Fatalf("Test failed! %v %v", ok, err)
+	}
+}
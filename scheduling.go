@@ -0,0 +1,138 @@
+package main
+
+import (
+	"strconv"
+)
+
+// Toleration struct
+type Toleration struct {
+	Key      string `json:"key"`
+	Operator string `json:"operator"`
+	Value    string `json:"value"`
+	Effect   string `json:"effect"`
+}
+
+// NodeSelectorTerm struct, the requirements are ANDed
+type NodeSelectorTerm struct {
+	MatchExpressions []NodeSelectorRequirement `json:"matchExpressions"`
+}
+
+// NodeSelectorRequirement struct, also used for the match expressions of the label selectors (In, NotIn, Exists, DoesNotExist)
+type NodeSelectorRequirement struct {
+	Key      string   `json:"key"`
+	Operator string   `json:"operator"`
+	Values   []string `json:"values"`
+}
+
+// Tolerates returns true if the toleration matches the taint
+func (t Toleration) Tolerates(taint Taint) bool {
+	if t.Effect != "" && t.Effect != taint.Effect {
+		return false
+	}
+	if t.Key == "" && t.Operator == "Exists" {
+		return true
+	}
+	if t.Key != taint.Key {
+		return false
+	}
+	return t.Operator == "Exists" || t.Value == taint.Value
+}
+
+// Matches returns true if the node labels match the requirement
+func (r NodeSelectorRequirement) Matches(labels map[string]string) bool {
+	value, ok := labels[r.Key]
+	switch r.Operator {
+	case "In":
+		return ok && contains(r.Values, value)
+	case "NotIn":
+		return !ok || !contains(r.Values, value)
+	case "Exists":
+		return ok
+	case "DoesNotExist":
+		return !ok
+	case "Gt", "Lt":
+		if !ok || len(r.Values) != 1 {
+			return false
+		}
+		v, err := strconv.Atoi(value)
+		limit, err2 := strconv.Atoi(r.Values[0])
+		if err != nil || err2 != nil {
+			return false
+		}
+		if r.Operator == "Gt" {
+			return v > limit
+		}
+		return v < limit
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ToleratesTaints returns true if the pod tolerates all NoSchedule and NoExecute taints of the node
+func (p Pod) ToleratesTaints(node Node) bool {
+	for _, taint := range node.Spec.Taints {
+		if taint.Effect == "PreferNoSchedule" {
+			continue
+		}
+		tolerated := false
+		for _, toleration := range p.Spec.Tolerations {
+			if toleration.Tolerates(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchesNodeSelector returns true if the node matches the nodeSelector and the required node affinity
+// node selector terms are ORed
+func (p Pod) MatchesNodeSelector(node Node) bool {
+	for k, v := range p.Spec.NodeSelector {
+		if node.Metadata.Labels[k] != v {
+			return false
+		}
+	}
+	terms := p.Spec.Affinity.NodeAffinity.Required.NodeSelectorTerms
+	if len(terms) == 0 {
+		return true
+	}
+	for _, term := range terms {
+		matches := true
+		for _, requirement := range term.MatchExpressions {
+			if !requirement.Matches(node.Metadata.Labels) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+// GetUnsimulatedConstraint returns the required pod anti-affinity or DoNotSchedule topology spread constraint of the pod,
+// the simulations don't place pods against the other pods, so such pods can't be moved safely
+func (p Pod) GetUnsimulatedConstraint() string {
+	if len(p.Spec.Affinity.PodAntiAffinity.Required) > 0 {
+		return "required pod anti-affinity on " + p.Spec.Affinity.PodAntiAffinity.Required[0].TopologyKey
+	}
+	for _, c := range p.Spec.TopologySpreadConstraints {
+		if c.WhenUnsatisfiable == "DoNotSchedule" {
+			return "DoNotSchedule topology spread constraint on " + c.TopologyKey
+		}
+	}
+	return ""
+}
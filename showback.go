@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// unlabelled bucket of the pods without the label, in the pod and in its namespace
+const unlabelled = "unlabelled"
+
+// labelFlag repeatable flag, eg. -group-by-label team -group-by-label cost-center
+type labelFlag []string
+
+func (l *labelFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+// Set ..
+func (l *labelFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// NamespaceItems a list of namespaces
+type NamespaceItems struct {
+	Items []struct {
+		Metadata struct {
+			Name   string            `json:"name"`
+			Labels map[string]string `json:"labels"`
+		} `json:"metadata"`
+	}
+}
+
+// RetrieveNamespaceLabels executes kubectl get namespaces command
+// returns the labels by namespace name
+func RetrieveNamespaceLabels() map[string]map[string]string {
+	cmd := "kubectl get namespaces -o json"
+	out, err := exec.Command("bash", "-c", cmd).CombinedOutput()
+	if err != nil {
+		log.Fatalf("Failed to execute command: %s", cmd)
+	}
+	return buildNamespaceLabels(string(out))
+}
+
+func buildNamespaceLabels(str string) map[string]map[string]string {
+	namespaces := NamespaceItems{}
+	err := json.Unmarshal([]byte(str), &namespaces)
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+	labels := make(map[string]map[string]string)
+	for _, ns := range namespaces.Items {
+		labels[ns.Metadata.Name] = ns.Metadata.Labels
+	}
+	return labels
+}
+
+// LabelGroup pods with the same values of the showback labels
+type LabelGroup struct {
+	Values []string
+	Pods   []Pod
+}
+
+// GetKey returns the values joined by |
+func (g LabelGroup) GetKey() string {
+	return strings.Join(g.Values, "|")
+}
+
+// GetWasteMilliCPU returns requests - top
+func (g LabelGroup) GetWasteMilliCPU() int {
+	w := Wrapper{Pods: g.Pods}
+	return w.GetRequestsMilliCPU() - w.GetTopMilliCPU()
+}
+
+// GetWasteMiMemory returns requests - top
+func (g LabelGroup) GetWasteMiMemory() int {
+	w := Wrapper{Pods: g.Pods}
+	return w.GetRequestsMiMemory() - w.GetTopMiMemory()
+}
+
+// CountNamespaces ..
+func (g LabelGroup) CountNamespaces() int {
+	namespaces := make(map[string]bool)
+	for _, p := range g.Pods {
+		namespaces[p.Metadata.Namespace] = true
+	}
+	return len(namespaces)
+}
+
+// getLabelValue returns the pod label, falls back to the namespace label
+func getLabelValue(pod Pod, label string, nsLabels map[string]map[string]string) string {
+	if value, ok := pod.Metadata.Labels[label]; ok && value != "" {
+		return value
+	}
+	if value, ok := nsLabels[pod.Metadata.Namespace][label]; ok && value != "" {
+		return value
+	}
+	return unlabelled
+}
+
+// BuildLabelGroups groups the pods by the values of the labels
+// the result is sorted by requests cost descending, then by cpu waste descending
+func BuildLabelGroups(podList []Pod, labels []string, nsLabels map[string]map[string]string) []LabelGroup {
+	groupMap := make(map[string]*LabelGroup)
+	for _, pod := range podList {
+		values := []string{}
+		for _, label := range labels {
+			values = append(values, getLabelValue(pod, label, nsLabels))
+		}
+		key := strings.Join(values, "|")
+		group, ok := groupMap[key]
+		if !ok {
+			group = &LabelGroup{Values: values}
+			groupMap[key] = group
+		}
+		group.Pods = append(group.Pods, pod)
+	}
+
+	groups := []LabelGroup{}
+	for _, group := range groupMap {
+		groups = append(groups, *group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		ci, cj := Wrapper{Pods: groups[i].Pods}.GetRequestsCost(), Wrapper{Pods: groups[j].Pods}.GetRequestsCost()
+		if ci != cj {
+			return ci > cj
+		}
+		wi, wj := groups[i].GetWasteMilliCPU(), groups[j].GetWasteMilliCPU()
+		if wi != wj {
+			return wi > wj
+		}
+		return groups[i].GetKey() < groups[j].GetKey()
+	})
+	return groups
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"strconv"
+	"testing"
+)
+
+func TestLabelFlag(t *testing.T) {
+	var labels labelFlag
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(&labels, "group-by-label", "")
+	if err := fs.Parse([]string{"-group-by-label", "team", "-group-by-label", "cost-center"}); err != nil {
+		t.Fatal(err)
+	}
+	if labels.String() != "team,cost-center" {
+		t.Fatalf("Test failed! %s", labels.String())
+	}
+}
+
+func TestBuildLabelGroups(t *testing.T) {
+	nsLabels := buildNamespaceLabels(`{"items": [
+		{"metadata": {"name": "shop", "labels": {"team": "checkout", "cost-center": "cc-100"}}},
+		{"metadata": {"name": "tools"}}
+	]}`)
+	pods := buildPodList(`{"items": [
+		{"metadata": {"name": "api-a", "namespace": "shop", "labels": {"team": "payments"}}, "spec": {"containers": [{"name": "c", "resources": {"requests": {"cpu": "1", "memory": "1Gi"}}}]}},
+		{"metadata": {"name": "web-a", "namespace": "shop"}, "spec": {"containers": [{"name": "c", "resources": {"requests": {"cpu": "500m", "memory": "512Mi"}}}]}},
+		{"metadata": {"name": "web-b", "namespace": "shop"}, "spec": {"containers": [{"name": "c", "resources": {"requests": {"cpu": "500m", "memory": "512Mi"}}}]}},
+		{"metadata": {"name": "ci-a", "namespace": "tools"}, "spec": {"containers": [{"name": "c", "resources": {"requests": {"cpu": "100m", "memory": "128Mi"}}}]}}
+	]}`).Items
+
+	groups := BuildLabelGroups(pods, []string{"team", "cost-center"}, nsLabels)
+	expected := []string{"checkout|cc-100 2", "payments|cc-100 1", "unlabelled|unlabelled 1"}
+	if len(groups) != len(expected) {
+		t.Fatalf("Test failed! found %d expected %d: %+v", len(groups), len(expected), groups)
+	}
+	for i, g := range groups {
+		if actual := g.GetKey() + " " + strconv.Itoa(len(g.Pods)); actual != expected[i] {
+			t.Fatalf("Test failed! found %s expected %s", actual, expected[i])
+		}
+	}
+	if groups[0].GetWasteMilliCPU() != 1000 || groups[0].GetWasteMiMemory() != 1024 || groups[0].CountNamespaces() != 1 {
+		t.Fatalf("Test failed! %+v", groups[0])
+	}
+}
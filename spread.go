@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+)
+
+const hostnameTopologyKey = "kubernetes.io/hostname"
+
+// Spread how the running pods of a workload are spread across nodes and zones
+type Spread struct {
+	Pods  int
+	Nodes int
+	Zones int
+}
+
+// String returns eg. 3 pods / 2 nodes / 1 zone
+func (s Spread) String() string {
+	return fmt.Sprintf("%s / %s / %s", plural(s.Pods, "pod"), plural(s.Nodes, "node"), plural(s.Zones, "zone"))
+}
+
+// GetSpread counts the distinct nodes and zones of the workload pods
+func (w Workload) GetSpread(nodeMap map[string]Node) Spread {
+	nodes := make(map[string]bool)
+	zones := make(map[string]bool)
+	for _, pod := range w.Pods {
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		nodes[pod.Spec.NodeName] = true
+		if zone := nodeMap[pod.Spec.NodeName].GetZone(); zone != "" {
+			zones[zone] = true
+		}
+	}
+	return Spread{Pods: len(w.Pods), Nodes: len(nodes), Zones: len(zones)}
+}
+
+// getTopologyDomain returns the node value for the topology key
+func getTopologyDomain(node Node, topologyKey string) string {
+	switch topologyKey {
+	case hostnameTopologyKey:
+		return node.GetName()
+	case "topology.kubernetes.io/zone", "failure-domain.beta.kubernetes.io/zone":
+		return node.GetZone()
+	default:
+		return node.Metadata.Labels[topologyKey]
+	}
+}
+
+// getEligibleNodes returns the nodes the pod can be scheduled on: taints, nodeSelector and required node affinity
+func getEligibleNodes(pod Pod, nodeList []Node) []Node {
+	eligible := []Node{}
+	for _, node := range nodeList {
+		if pod.ToleratesTaints(node) && pod.MatchesNodeSelector(node) {
+			eligible = append(eligible, node)
+		}
+	}
+	return eligible
+}
+
+// countPodsPerDomain counts the workload pods in each topology domain, domains come from the eligible nodes, so empty domains count as 0
+func (w Workload) countPodsPerDomain(nodeMap map[string]Node, eligible []Node, topologyKey string) map[string]int {
+	counts := make(map[string]int)
+	for _, node := range eligible {
+		if domain := getTopologyDomain(node, topologyKey); domain != "" {
+			counts[domain] = 0
+		}
+	}
+	for _, pod := range w.Pods {
+		node, ok := nodeMap[pod.Spec.NodeName]
+		if !ok {
+			continue
+		}
+		if domain := getTopologyDomain(node, topologyKey); domain != "" {
+			counts[domain]++
+		}
+	}
+	return counts
+}
+
+// isSpreadWorkload returns true for workloads expected to run replicas in different nodes/zones
+func (w Workload) isSpreadWorkload() bool {
+	return w.Kind == "Deployment" || w.Kind == "StatefulSet"
+}
+
+// BuildSpreadFindings flags Deployments and StatefulSets with all replicas in one node or zone
+// and workloads whose topologySpreadConstraints or pod anti-affinity are not met in practice.
+// Constraints are assumed to select the workload own pods. Zones and topology domains only come from the nodes
+// the workload can be scheduled on, so workloads confined to a node pool are compared with that node pool
+func BuildSpreadFindings(workloads []Workload, nodeList []Node) []Finding {
+	nodeMap := make(map[string]Node)
+	for _, node := range nodeList {
+		nodeMap[node.GetName()] = node
+	}
+
+	findings := []Finding{}
+	for _, w := range workloads {
+		if !w.isSpreadWorkload() || len(w.Pods) < 2 {
+			continue
+		}
+		add := func(id string, severity string, message string) {
+			findings = append(findings, Finding{ID: id, Severity: severity, Namespace: w.Namespace, Object: w.GetReference(), Message: message})
+		}
+		eligible := getEligibleNodes(w.Pods[0], nodeList)
+		eligibleZones := make(map[string]bool)
+		for _, node := range eligible {
+			if zone := node.GetZone(); zone != "" {
+				eligibleZones[zone] = true
+			}
+		}
+		spread := w.GetSpread(nodeMap)
+		if spread.Nodes == 1 {
+			add("SPREAD-SINGLE-NODE", SeverityError, fmt.Sprintf("all %d pods run on node %s, the node is a single point of failure", spread.Pods, w.Pods[0].Spec.NodeName))
+		}
+		if spread.Zones == 1 && len(eligibleZones) > 1 {
+			add("SPREAD-SINGLE-ZONE", SeverityWarning, fmt.Sprintf("all %d pods run in one zone while their nodes span %d zones", spread.Pods, len(eligibleZones)))
+		}
+
+		spec := w.Pods[0].Spec
+		for _, c := range spec.TopologySpreadConstraints {
+			min, max := -1, 0
+			for _, count := range w.countPodsPerDomain(nodeMap, eligible, c.TopologyKey) {
+				if min == -1 || count < min {
+					min = count
+				}
+				if count > max {
+					max = count
+				}
+			}
+			if skew := max - min; min != -1 && skew > c.MaxSkew {
+				add("SPREAD-CONSTRAINT-UNMET", SeverityWarning, fmt.Sprintf("topologySpreadConstraint on %s allows maxSkew %d but actual skew is %d (%s)", c.TopologyKey, c.MaxSkew, skew, c.WhenUnsatisfiable))
+			}
+		}
+
+		var antiAffinityKeys []string
+		for _, term := range spec.Affinity.PodAntiAffinity.Required {
+			antiAffinityKeys = append(antiAffinityKeys, term.TopologyKey)
+		}
+		for _, weighted := range spec.Affinity.PodAntiAffinity.Preferred {
+			antiAffinityKeys = append(antiAffinityKeys, weighted.PodAffinityTerm.TopologyKey)
+		}
+		for _, key := range antiAffinityKeys {
+			shared := 0
+			for _, count := range w.countPodsPerDomain(nodeMap, eligible, key) {
+				if count > 1 {
+					shared += count
+				}
+			}
+			if shared > 0 {
+				add("SPREAD-ANTI-AFFINITY-UNMET", SeverityWarning, fmt.Sprintf("pod anti-affinity on %s is not met, %d pods share the same %s", key, shared, key))
+			}
+		}
+	}
+	sortFindings(findings)
+	return findings
+}
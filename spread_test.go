@@ -0,0 +1,69 @@
+package main
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func buildSpreadTestNodes() []Node {
+	return buildNodeList(`{"items": [
+		{"metadata": {"name": "node-a1", "labels": {"topology.kubernetes.io/zone": "zone-a"}}},
+		{"metadata": {"name": "node-a2", "labels": {"topology.kubernetes.io/zone": "zone-a"}}},
+		{"metadata": {"name": "node-b1", "labels": {"topology.kubernetes.io/zone": "zone-b"}}}
+	]}`).Items
+}
+
+func TestGetSpread(t *testing.T) {
+	b, err := ioutil.ReadFile("test-data/nodes.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodeMap := make(map[string]Node)
+	for _, node := range buildNodeList(string(b)).Items {
+		nodeMap[node.GetName()] = node
+	}
+	pods := loadPodsWithTop(t, "test-data/many-pods.json", "test-data/top-many-pods.txt")
+	workloads := BuildWorkloads(pods, []Pdb{})
+
+	frontend := workloads[5]
+	if spread := frontend.GetSpread(nodeMap); frontend.Name != "frontend" || spread.String() != "3 pods / 2 nodes / 1 zone" {
+		t.Fatalf("Test failed! %s %s", frontend.Name, spread)
+	}
+}
+
+func TestBuildSpreadFindings(t *testing.T) {
+	pods := buildPodList(`{"items": [
+		{"metadata": {"name": "api-7d9f-xk2lp", "namespace": "shop", "ownerReferences": [{"kind": "ReplicaSet", "name": "api-7d9f"}]}, "spec": {"nodeName": "node-a1"}},
+		{"metadata": {"name": "api-7d9f-pq7rt", "namespace": "shop", "ownerReferences": [{"kind": "ReplicaSet", "name": "api-7d9f"}]}, "spec": {"nodeName": "node-a1"}},
+		{"metadata": {"name": "web-5c4b-a1b2c", "namespace": "shop", "ownerReferences": [{"kind": "ReplicaSet", "name": "web-5c4b"}]}, "spec": {"nodeName": "node-a1",
+			"topologySpreadConstraints": [{"maxSkew": 1, "topologyKey": "topology.kubernetes.io/zone", "whenUnsatisfiable": "ScheduleAnyway"}],
+			"affinity": {"podAntiAffinity": {"preferredDuringSchedulingIgnoredDuringExecution": [{"weight": 100, "podAffinityTerm": {"topologyKey": "kubernetes.io/hostname"}}]}}}},
+		{"metadata": {"name": "web-5c4b-d3e4f", "namespace": "shop", "ownerReferences": [{"kind": "ReplicaSet", "name": "web-5c4b"}]}, "spec": {"nodeName": "node-a2"}},
+		{"metadata": {"name": "web-5c4b-g5h6i", "namespace": "shop", "ownerReferences": [{"kind": "ReplicaSet", "name": "web-5c4b"}]}, "spec": {"nodeName": "node-a2"}},
+		{"metadata": {"name": "db-0", "namespace": "shop", "ownerReferences": [{"kind": "StatefulSet", "name": "db"}]}, "spec": {"nodeName": "node-a1"}},
+		{"metadata": {"name": "db-1", "namespace": "shop", "ownerReferences": [{"kind": "StatefulSet", "name": "db"}]}, "spec": {"nodeName": "node-b1"}},
+		{"metadata": {"name": "batch-8f2a-j7k8l", "namespace": "shop", "ownerReferences": [{"kind": "ReplicaSet", "name": "batch-8f2a"}]}, "spec": {"nodeName": "node-a1",
+			"nodeSelector": {"topology.kubernetes.io/zone": "zone-a"},
+			"topologySpreadConstraints": [{"maxSkew": 1, "topologyKey": "topology.kubernetes.io/zone", "whenUnsatisfiable": "DoNotSchedule"}]}},
+		{"metadata": {"name": "batch-8f2a-m9n0p", "namespace": "shop", "ownerReferences": [{"kind": "ReplicaSet", "name": "batch-8f2a"}]}, "spec": {"nodeName": "node-a2", "nodeSelector": {"topology.kubernetes.io/zone": "zone-a"}}},
+		{"metadata": {"name": "agent-x1", "namespace": "shop", "ownerReferences": [{"kind": "DaemonSet", "name": "agent"}]}, "spec": {"nodeName": "node-a1"}},
+		{"metadata": {"name": "agent-x2", "namespace": "shop", "ownerReferences": [{"kind": "DaemonSet", "name": "agent"}]}, "spec": {"nodeName": "node-a1"}}
+	]}`).Items
+
+	findings := BuildSpreadFindings(BuildWorkloads(pods, []Pdb{}), buildSpreadTestNodes())
+	expected := []string{
+		"Deployment/api SPREAD-SINGLE-NODE",
+		"Deployment/api SPREAD-SINGLE-ZONE",
+		"Deployment/web SPREAD-ANTI-AFFINITY-UNMET",
+		"Deployment/web SPREAD-CONSTRAINT-UNMET",
+		"Deployment/web SPREAD-SINGLE-ZONE",
+	}
+	if len(findings) != len(expected) {
+		t.Fatalf("Test failed! found %d expected %d: %+v", len(findings), len(expected), findings)
+	}
+	for i, f := range findings {
+		if result := f.Object + " " + f.ID; result != expected[i] {
+			t.Fatalf("Test failed! %s but expected %s", result, expected[i])
+		}
+	}
+}
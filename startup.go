@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// StartupGroup pods grouped by workload, node pool or image
+// pods without startup info (restarted, not ready or not scheduled) are counted but not sampled,
+// the conditions only keep the last transition so the first startup of a restarted pod is lost
+type StartupGroup struct {
+	Name string
+	Pods []Pod
+}
+
+// CountSamples returns the number of pods with startup info
+func (g StartupGroup) CountSamples() int {
+	count := 0
+	for _, pod := range g.Pods {
+		if pod.GetStartupDuration() != time.Duration(0) {
+			count++
+		}
+	}
+	return count
+}
+
+// CountRestarted returns the number of pods left out of the samples because they restarted
+func (g StartupGroup) CountRestarted() int {
+	count := 0
+	for _, pod := range g.Pods {
+		if pod.GetRestartCount() > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// GetStartupPercentile ..
+func (g StartupGroup) GetStartupPercentile(p int) time.Duration {
+	return Wrapper{Pods: g.Pods}.GetStartupPercentile(p)
+}
+
+// GetPhasesPercentile returns the percentile of the scheduled -> initialized and initialized -> containers ready phases
+func (g StartupGroup) GetPhasesPercentile(p int) (time.Duration, time.Duration) {
+	initializations, containers := []int{}, []int{}
+	for _, pod := range g.Pods {
+		if i, c, ok := pod.GetStartupPhases(); ok {
+			initializations = append(initializations, int(i/time.Millisecond))
+			containers = append(containers, int(c/time.Millisecond))
+		}
+	}
+	return time.Duration(percentile(initializations, p)) * time.Millisecond, time.Duration(percentile(containers, p)) * time.Millisecond
+}
+
+// GetInitialization returns the p50/p90 of the scheduled -> initialized phase, eg. "1s/3s"
+func (g StartupGroup) GetInitialization() string {
+	p50, _ := g.GetPhasesPercentile(50)
+	p90, _ := g.GetPhasesPercentile(90)
+	return fmt.Sprintf("%s/%s", p50, p90)
+}
+
+// GetContainersReady returns the p50/p90 of the initialized -> containers ready phase, eg. "12s/40s"
+func (g StartupGroup) GetContainersReady() string {
+	_, p50 := g.GetPhasesPercentile(50)
+	_, p90 := g.GetPhasesPercentile(90)
+	return fmt.Sprintf("%s/%s", p50, p90)
+}
+
+// sortStartupGroups drops the groups without samples nor restarted pods and sorts by p90 descending, then by name
+func sortStartupGroups(groups []StartupGroup) []StartupGroup {
+	sampled := []StartupGroup{}
+	for _, g := range groups {
+		if g.CountSamples() > 0 || g.CountRestarted() > 0 {
+			sampled = append(sampled, g)
+		}
+	}
+	sort.SliceStable(sampled, func(i, j int) bool {
+		pi, pj := sampled[i].GetStartupPercentile(90), sampled[j].GetStartupPercentile(90)
+		if pi != pj {
+			return pi > pj
+		}
+		return sampled[i].Name < sampled[j].Name
+	})
+	return sampled
+}
+
+// BuildWorkloadStartups returns the startup of each workload, named <namespace>/<kind>/<name>
+func BuildWorkloadStartups(workloads []Workload) []StartupGroup {
+	groups := []StartupGroup{}
+	for _, w := range workloads {
+		groups = append(groups, StartupGroup{Name: w.Namespace + "/" + w.GetReference(), Pods: w.Pods})
+	}
+	return sortStartupGroups(groups)
+}
+
+// BuildNodepoolStartups returns the startup of the pods of each node pool
+func BuildNodepoolStartups(nodeList []Node) []StartupGroup {
+	groups := []StartupGroup{}
+	index := make(map[string]int)
+	for _, node := range nodeList {
+		name := node.GetNodepool()
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, StartupGroup{Name: name})
+		}
+		groups[i].Pods = append(groups[i].Pods, node.Pods...)
+	}
+	return sortStartupGroups(groups)
+}
+
+// BuildImageStartups returns the startup of the pods running each image, a pod with several images is counted in each of them
+func BuildImageStartups(podList []Pod) []StartupGroup {
+	groups := []StartupGroup{}
+	index := make(map[string]int)
+	for _, pod := range podList {
+		images := make(map[string]bool)
+		for _, c := range pod.Spec.Containers {
+			if images[c.Image] {
+				continue
+			}
+			images[c.Image] = true
+			i, ok := index[c.Image]
+			if !ok {
+				i = len(groups)
+				index[c.Image] = i
+				groups = append(groups, StartupGroup{Name: c.Image})
+			}
+			groups[i].Pods = append(groups[i].Pods, pod)
+		}
+	}
+	return sortStartupGroups(groups)
+}
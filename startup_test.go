@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// startupPod returns a pod scheduled at 10:00:00 and initialized, containers ready and ready after the given seconds
+func startupPod(name string, image string, node string, initialized int, ready int, restarts int) string {
+	at := func(seconds int) string {
+		return fmt.Sprintf("2020-01-01T10:%02d:%02dZ", seconds/60, seconds%60)
+	}
+	return fmt.Sprintf(`{"metadata": {"name": "%s", "namespace": "shop", "ownerReferences": [{"kind": "ReplicaSet", "name": "%s"}]},
+		"spec": {"nodeName": "%s", "containers": [{"name": "c", "image": "%s"}, {"name": "proxy", "image": "envoy:v1"}]},
+		"status": {"containerStatuses": [{"restartCount": %d}], "conditions": [
+			{"type": "PodScheduled", "status": "True", "lastTransitionTime": "%s"},
+			{"type": "Initialized", "status": "True", "lastTransitionTime": "%s"},
+			{"type": "ContainersReady", "status": "True", "lastTransitionTime": "%s"},
+			{"type": "Ready", "status": "True", "lastTransitionTime": "%s"}]}}`,
+		name, name[:len(name)-6], node, image, restarts, at(0), at(initialized), at(ready), at(ready))
+}
+
+func TestBuildStartups(t *testing.T) {
+	pods := buildPodList(`{"items": [` +
+		startupPod("api-7d9f-aaaaa", "acme/api:1.0", "node-a", 2, 10, 0) + "," +
+		startupPod("api-7d9f-bbbbb", "acme/api:1.0", "node-a", 4, 20, 0) + "," +
+		startupPod("api-7d9f-ccccc", "acme/api:1.0", "node-b", 6, 90, 0) + "," +
+		startupPod("api-7d9f-ddddd", "acme/api:1.0", "node-b", 1, 5, 2) + "," +
+		startupPod("web-5c8d-eeeee", "acme/web:3.1", "node-b", 1, 5, 0) + "," +
+		startupPod("job-6b5c-fffff", "acme/job:1.2", "node-b", 1, 5, 3) + `]}`).Items
+
+	if i, c, ok := pods[0].GetStartupPhases(); !ok || i != 2*time.Second || c != 8*time.Second {
+		t.Fatalf("Test failed! %s %s %v", i, c, ok)
+	}
+	// restarted pods have no startup info
+	if _, _, ok := pods[3].GetStartupPhases(); ok {
+		t.Fatalf("Test failed! restarted pod")
+	}
+
+	workloads := BuildWorkloadStartups(BuildWorkloads(pods, []Pdb{}))
+	api := workloads[0]
+	if api.Name != "shop/Deployment/api" || len(api.Pods) != 4 || api.CountSamples() != 3 || api.CountRestarted() != 1 || api.GetStartupPercentile(50) != 20*time.Second || api.GetStartupPercentile(90) != 90*time.Second || api.GetStartupPercentile(100) != 90*time.Second {
+		t.Fatalf("Test failed! %+v", api)
+	}
+	// a workload whose pods all restarted is kept without percentiles
+	if job := workloads[len(workloads)-1]; job.Name != "shop/Deployment/job" || job.CountSamples() != 0 || job.CountRestarted() != 1 || job.GetStartupPercentile(90) != 0 {
+		t.Fatalf("Test failed! %+v", job)
+	}
+	if api.GetInitialization() != "4s/6s" || api.GetContainersReady() != "16s/1m24s" {
+		t.Fatalf("Test failed! %s %s", api.GetInitialization(), api.GetContainersReady())
+	}
+
+	nodes := buildNodeList(`{"items": [
+		{"metadata": {"name": "node-a", "labels": {"cloud.google.com/gke-nodepool": "default"}}},
+		{"metadata": {"name": "node-b", "labels": {"cloud.google.com/gke-nodepool": "highmem"}}}
+	]}`).Items
+	nodes[0].Pods, nodes[1].Pods = pods[:2], pods[2:]
+	nodepools := BuildNodepoolStartups(nodes)
+	if len(nodepools) != 2 || nodepools[0].Name != "highmem" || nodepools[0].GetStartupPercentile(90) != 90*time.Second || nodepools[1].GetStartupPercentile(90) != 20*time.Second {
+		t.Fatalf("Test failed! %+v", nodepools)
+	}
+
+	// the envoy sidecar runs in all the pods
+	images := BuildImageStartups(pods)
+	expected := []string{"acme/api:1.0 4 3 1", "envoy:v1 6 4 2", "acme/web:3.1 1 1 0", "acme/job:1.2 1 0 1"}
+	if len(images) != len(expected) {
+		t.Fatalf("Test failed! found %d expected %d", len(images), len(expected))
+	}
+	for i, g := range images {
+		if actual := fmt.Sprintf("%s %d %d %d", g.Name, len(g.Pods), g.CountSamples(), g.CountRestarted()); actual != expected[i] {
+			t.Fatalf("Test failed! found %s expected %s", actual, expected[i])
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os/exec"
+)
+
+// StatsSummary kubelet stats summary (/api/v1/nodes/<node>/proxy/stats/summary)
+type StatsSummary struct {
+	Node NodeStats  `json:"node"`
+	Pods []PodStats `json:"pods"`
+}
+
+// NodeStats struct
+type NodeStats struct {
+	NodeName string  `json:"nodeName"`
+	Fs       FsStats `json:"fs"`
+}
+
+// PodStats struct
+type PodStats struct {
+	PodRef struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"podRef"`
+	EphemeralStorage FsStats `json:"ephemeral-storage"`
+}
+
+// FsStats struct
+type FsStats struct {
+	AvailableBytes int64 `json:"availableBytes"`
+	CapacityBytes  int64 `json:"capacityBytes"`
+	UsedBytes      int64 `json:"usedBytes"`
+}
+
+// GetUsedMi returns the used bytes in Mi
+func (f FsStats) GetUsedMi() int {
+	return int(f.UsedBytes / (1024 * 1024))
+}
+
+// HasStats returns true when the kubelet stats summary of the pod was collected (-ephemeral-usage)
+func (p Pod) HasStats() bool {
+	return p.Stats.PodRef.Name != ""
+}
+
+// HasStats returns true when the stats of at least one pod were collected
+func (d Wrapper) HasStats() bool {
+	for _, p := range d.Pods {
+		if p.HasStats() {
+			return true
+		}
+	}
+	return false
+}
+
+// formatEphemeralUsage returns the ephemeral storage usage %, N/A in the table and empty in the csv when the stats were not collected
+func formatEphemeralUsage(usage float32, hasStats bool, table bool) string {
+	switch {
+	case !hasStats && table:
+		return "N/A"
+	case !hasStats:
+		return ""
+	case table:
+		return fmt.Sprintf("%.2f%%", usage)
+	}
+	return fmt.Sprintf("%.2f", usage)
+}
+
+// RetrieveStatsSummaryMap executes kubectl get --raw for the stats summary of each node running the pods
+// returns key = node name, a node whose summary can't be read (NotReady, no nodes/proxy permission) is skipped
+// and the usage of its pods is N/A
+func RetrieveStatsSummaryMap(podList []Pod) map[string]StatsSummary {
+	summaries := make(map[string]StatsSummary)
+	failed := make(map[string]bool)
+	for _, pod := range podList {
+		nodeName := pod.Spec.NodeName
+		if _, ok := summaries[nodeName]; ok || failed[nodeName] || nodeName == "" {
+			continue
+		}
+		cmd := fmt.Sprintf("kubectl get --raw /api/v1/nodes/%s/proxy/stats/summary", nodeName)
+		out, err := exec.Command("bash", "-c", cmd).CombinedOutput()
+		if err != nil {
+			log.Printf("Warning: failed to execute command: %s, ephemeral storage usage of node %s is not available", cmd, nodeName)
+			failed[nodeName] = true
+			continue
+		}
+		summaries[nodeName] = buildStatsSummary(string(out))
+	}
+	return summaries
+}
+
+func enrichPodsWithStats(pods []Pod, summaries map[string]StatsSummary) []Pod {
+	statsMap := make(map[string]PodStats)
+	for _, summary := range summaries {
+		for _, stats := range summary.Pods {
+			statsMap[stats.PodRef.Namespace+"|"+stats.PodRef.Name] = stats
+		}
+	}
+	for i, pod := range pods {
+		pods[i].Stats = statsMap[pod.GetPodKey()]
+	}
+	return pods
+}
+
+func enrichNodesWithStats(nodes []Node, summaries map[string]StatsSummary) []Node {
+	for i, node := range nodes {
+		nodes[i].Stats = summaries[node.GetName()].Node
+	}
+	return nodes
+}
+
+func buildStatsSummary(str string) StatsSummary {
+	summary := StatsSummary{}
+	err := json.Unmarshal([]byte(str), &summary)
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+	return summary
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestBuildStatsSummary(t *testing.T) {
+	b, err := ioutil.ReadFile("test-data/stats-summary.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary := buildStatsSummary(string(b))
+
+	if summary.Node.NodeName != "gke-central-pool-1-47d730e3-709m" ||
+		summary.Node.Fs.GetUsedMi() != 35952 ||
+		len(summary.Pods) != 1 ||
+		summary.Pods[0].EphemeralStorage.GetUsedMi() != 200 {
+		t.Fatalf("Test failed! %+v", summary)
+	}
+}
+
+func TestEnrichWithStats(t *testing.T) {
+	b, err := ioutil.ReadFile("test-data/stats-summary.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary := buildStatsSummary(string(b))
+	summaries := map[string]StatsSummary{summary.Node.NodeName: summary}
+
+	b, err = ioutil.ReadFile("test-data/many-pods.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pods := enrichPodsWithStats(buildPodList(string(b)).Items, summaries)
+	for _, pod := range pods {
+		used := pod.GetUsedMiEphemeralStorage()
+		if pod.Metadata.Name == "adservice-74c5fd9c95-mmhkn" && (used != 200 || pod.GetUsageEphemeralStorage() != 100) {
+			t.Fatalf("Test failed! %+v", pod)
+		} else if pod.Metadata.Name != "adservice-74c5fd9c95-mmhkn" && used != 0 {
+			t.Fatalf("Test failed! %+v", pod)
+		}
+	}
+
+	b, err = ioutil.ReadFile("test-data/nodes.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := enrichNodesWithStats(buildNodeList(string(b)).Items, summaries)
+	if nodes[0].GetUsedMiFs() != 35952 || nodes[1].GetUsedMiFs() != 0 ||
+		nodes[0].GetAllocatableMiEphemeralStorage() != 44912 {
+		t.Fatalf("Test failed! %+v", nodes[0])
+	}
+}
+
+func TestPodEphemeralStorage(t *testing.T) {
+	pod := Pod{}
+	c := ContainerSpec{}
+	c.Resources.Requests = Resource{EphemeralStorage: "1Gi"}
+	c.Resources.Limits = Resource{EphemeralStorage: "2Gi"}
+	pod.Spec.Containers = []ContainerSpec{c, c}
+	pod.Stats.EphemeralStorage.UsedBytes = 512 * 1024 * 1024
+
+	if pod.GetRequestsMiEphemeralStorage() != 2048 ||
+		pod.GetLimitsMiEphemeralStorage() != 4096 ||
+		pod.GetUsedMiEphemeralStorage() != 512 ||
+		pod.GetUsageEphemeralStorage() != 25 {
+		t.Fatalf("Test failed! %+v", pod)
+	}
+}
+
+func TestFormatEphemeralUsage(t *testing.T) {
+	pod := Pod{}
+	if u := formatEphemeralUsage(pod.GetUsageEphemeralStorage(), pod.HasStats(), true); u != "N/A" {
+		t.Fatalf("Test failed! %s", u)
+	}
+	if u := formatEphemeralUsage(pod.GetUsageEphemeralStorage(), Wrapper{Pods: []Pod{pod}}.HasStats(), false); u != "" {
+		t.Fatalf("Test failed! %s", u)
+	}
+	pod.Stats.PodRef.Name = "api"
+	if u := formatEphemeralUsage(25, pod.HasStats(), true); u != "25.00%" {
+		t.Fatalf("Test failed! %s", u)
+	}
+	if u := formatEphemeralUsage(25, pod.HasStats(), false); u != "25.00" {
+		t.Fatalf("Test failed! %s", u)
+	}
+}
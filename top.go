@@ -23,6 +23,43 @@ type Container struct {
 	Memory string
 }
 
+// NodeTop struct
+type NodeTop struct {
+	Name   string
+	CPU    string
+	Memory string
+}
+
+// GetMilliCPU node cpu
+func (t NodeTop) GetMilliCPU() int {
+	return String2MilliCPU(t.CPU)
+}
+
+// GetMiMemory node memory in Mi
+func (t NodeTop) GetMiMemory() int {
+	return String2MiMemory(t.Memory)
+}
+
+// GetMilliCPU container cpu
+func (c Container) GetMilliCPU() int {
+	return String2MilliCPU(c.CPU)
+}
+
+// GetMiMemory container memory in Mi
+func (c Container) GetMiMemory() int {
+	return String2MiMemory(c.Memory)
+}
+
+// GetContainer returns the top of the container with the given name
+func (t Top) GetContainer(name string) (Container, bool) {
+	for _, c := range t.Containers {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Container{}, false
+}
+
 // GetDeploymentName should work for most of the cases
 func (t Top) GetDeploymentName() string {
 	reg, _ := regexp.Compile(`(.*)-([^-]*)-([^-]*)`)
@@ -107,3 +144,40 @@ func buildTopMap(data string, nsFilter string) map[string]Top {
 	}
 	return top
 }
+
+// RetrieveNodeTopMap executes kubectl top nodes command
+// returns key = node name, empty when the node metrics are not available
+func RetrieveNodeTopMap() map[string]NodeTop {
+	cmd := "kubectl top nodes --no-headers"
+	out, err := exec.Command("bash", "-c", cmd).CombinedOutput()
+	if err != nil {
+		log.Printf("Warning: failed to execute command: %s, node usage is not available", cmd)
+		return make(map[string]NodeTop)
+	}
+	data := string(out)
+	return buildNodeTopMap(data)
+}
+
+func buildNodeTopMap(data string) map[string]NodeTop {
+	r := strings.NewReader(data)
+	scanner := bufio.NewScanner(r)
+	top := make(map[string]NodeTop)
+	for scanner.Scan() {
+		reg, _ := regexp.Compile(`(\S*)\s*(\S*)\s*(\S*)\s*(\S*)\s*(\S*)\s*`)
+		groups := reg.FindStringSubmatch(scanner.Text())
+		if groups[1] == "" || groups[1] == "NAME" || groups[2] == "<unknown>" {
+			// nodes without metrics, eg. NotReady, are printed with <unknown> values
+			continue
+		}
+		top[groups[1]] = NodeTop{
+			Name:   groups[1],
+			CPU:    groups[2],
+			Memory: groups[4],
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		log.Fatal(err)
+	}
+	return top
+}
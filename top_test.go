@@ -68,3 +68,26 @@ func TestBuildOnePodTop(t *testing.T) {
 		t.Fatalf("Test failed! %d but expected %d", mem, expectedMemory)
 	}
 }
+
+func TestBuildNodeTopMap(t *testing.T) {
+	b, err := ioutil.ReadFile("test-data/top-nodes.txt")
+	if err != nil {
+		log.Fatal(err)
+	}
+	data := string(b)
+
+	tops := buildNodeTopMap(data)
+	if l := len(tops); l != 4 {
+		t.Fatalf("Test failed! found %d expected %d", l, 4)
+	}
+	top := tops["gke-central-pool-1-47d730e3-sh01"]
+	if top.GetMilliCPU() != 530 || top.GetMiMemory() != 4421 {
+		t.Fatalf("Test failed! %+v", top)
+	}
+
+	// NotReady nodes are printed with <unknown> values
+	tops = buildNodeTopMap("node-1   250m   6%   1200Mi   20%\nnode-2   <unknown>   <unknown>   <unknown>   <unknown>\n")
+	if _, ok := tops["node-2"]; ok || len(tops) != 1 {
+		t.Fatalf("Test failed! %+v", tops)
+	}
+}
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"fmt"
 	"math"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // String2MilliCPU converts String to Milli CPU
@@ -56,3 +59,59 @@ func String2MiMemory(men string) int {
 		128974848, 129e6, 129M, 123Mi
 	*/
 }
+
+// String2Quantity converts an extended resource quantity to int, hugepages are converted to Mi
+func String2Quantity(name string, quantity string) int {
+	if strings.HasPrefix(name, "hugepages-") {
+		return String2MiMemory(quantity)
+	}
+	count, _ := strconv.Atoi(quantity)
+	return count
+}
+
+// Duration2Age converts a duration to the kubectl age format, eg. 133d, 5h, 10m, 30s
+func Duration2Age(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+}
+
+// percentage returns value / total * 100, 0 if total is 0
+func percentage(value int, total int) float32 {
+	if total == 0 {
+		return 0
+	}
+	return float32(value) / float32(total) * 100
+}
+
+// percentile returns the nearest-rank percentile (0-100) of the values, 0 if empty
+// p out of range is clamped to the min or the max value
+func percentile(values []int, p int) int {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]int{}, values...)
+	sort.Ints(sorted)
+	rank := int(math.Ceil(float64(p) / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	} else if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
+
+// plural returns eg. 1 pod, 2 pods
+func plural(count int, singular string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, singular)
+	}
+	return fmt.Sprintf("%d %ss", count, singular)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"testing"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -44,3 +45,30 @@ func TestString2MiMemory(t *testing.T) {
 		}
 	}
 }
+
+func TestDuration2Age(t *testing.T) {
+	tests := map[time.Duration]string{
+		30 * time.Second:          "30s",
+		5 * time.Minute:           "5m",
+		5*time.Hour + time.Minute: "5h",
+		133 * 24 * time.Hour:      "133d",
+	}
+	for in, out := range tests {
+		if result := Duration2Age(in); result != out {
+			t.Fatalf("Test failed! %s but expected %s", result, out)
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	values := []int{15, 20, 35, 40, 50}
+	tests := map[int]int{-10: 15, 0: 15, 30: 20, 40: 20, 50: 35, 100: 50, 150: 50}
+	for p, expected := range tests {
+		if result := percentile(values, p); result != expected {
+			t.Fatalf("Test failed! %d but expected %d for P%d", result, expected, p)
+		}
+	}
+	if result := percentile([]int{}, 95); result != 0 {
+		t.Fatalf("Test failed! %d but expected 0", result)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// hpaTolerance the hpa controller doesn't scale while the metric ratio is within 10% of 1
+const hpaTolerance = 0.1
+
+// WhatIfOptions changes applied to all hpas, zero values keep the current state
+// Target replaces the target cpu %, CPURequestsChange is a % added to the cpu requests (eg. -30)
+// and Traffic multiplies the cpu usage (eg. 2 means traffic doubled)
+type WhatIfOptions struct {
+	Target            int
+	CPURequestsChange int
+	Traffic           float64
+}
+
+// HpaWhatIf replicas and requests of an hpa before and after the changes
+// Reason explains why the hpa was not simulated, the current values are kept in that case
+type HpaWhatIf struct {
+	Hpa              Hpa
+	Target           int
+	Utilization      int
+	Replicas         int
+	RequestsMilliCPU int
+	RequestsMiMemory int
+	Reason           string
+}
+
+// GetRequestsMilliCPU current requests of all pods
+func (r HpaWhatIf) GetRequestsMilliCPU() int {
+	return Wrapper{Pods: r.Hpa.Pods}.GetRequestsMilliCPU()
+}
+
+// GetRequestsMiMemory current requests of all pods
+func (r HpaWhatIf) GetRequestsMiMemory() int {
+	return Wrapper{Pods: r.Hpa.Pods}.GetRequestsMiMemory()
+}
+
+// WhatIfCluster requests of all pods in the cluster before and after the changes
+type WhatIfCluster struct {
+	AllocatableMilliCPU   int
+	AllocatableMiMemory   int
+	RequestsMilliCPU      int
+	RequestsMiMemory      int
+	RequestsMilliCPUAfter int
+	RequestsMiMemoryAfter int
+}
+
+// FitsCPU returns true if the allocatable cpu of the nodes still covers the requests
+func (c WhatIfCluster) FitsCPU() bool {
+	return c.RequestsMilliCPUAfter <= c.AllocatableMilliCPU
+}
+
+// FitsMemory returns true if the allocatable memory of the nodes still covers the requests
+func (c WhatIfCluster) FitsMemory() bool {
+	return c.RequestsMiMemoryAfter <= c.AllocatableMiMemory
+}
+
+// desiredReplicas applies the hpa formula desired = ceil(current * currentMetric / target), bounded by min and max
+func desiredReplicas(current int, utilization float64, target int, min int, max int) int {
+	desired := current
+	ratio := utilization / float64(target)
+	if math.Abs(ratio-1) > hpaTolerance {
+		desired = int(math.Ceil(float64(current) * ratio))
+	}
+	if desired < min {
+		return min
+	}
+	if desired > max {
+		return max
+	}
+	return desired
+}
+
+// simulateHpa returns the replicas the hpa would settle on with the changes
+// the usage is derived from the current utilization, so it only changes with the traffic
+func simulateHpa(h Hpa, opts WhatIfOptions) HpaWhatIf {
+	w := Wrapper{Pods: h.Pods}
+	r := HpaWhatIf{Hpa: h, Target: h.Target, Utilization: h.UsageCPU, Replicas: h.Replicas, RequestsMilliCPU: w.GetRequestsMilliCPU(), RequestsMiMemory: w.GetRequestsMiMemory()}
+	if opts.Target > 0 {
+		r.Target = opts.Target
+	}
+	switch {
+	case h.UsageCPU == -1:
+		r.Reason = "current cpu is <unknown>"
+		return r
+	case len(h.Pods) == 0:
+		r.Reason = "pods not found"
+		return r
+	case h.Replicas == 0:
+		r.Reason = "scaled to 0"
+		return r
+	}
+
+	traffic := opts.Traffic
+	if traffic <= 0 {
+		traffic = 1
+	}
+	requestsFactor := float64(100+opts.CPURequestsChange) / 100
+	if requestsFactor <= 0 {
+		r.Reason = "cpu requests can't be reduced by 100% or more"
+		return r
+	}
+	utilization := float64(h.UsageCPU) * traffic / requestsFactor
+	r.Utilization = int(math.Round(utilization))
+	r.Replicas = desiredReplicas(h.Replicas, utilization, r.Target, h.MinPods, h.MaxPods)
+
+	podMilliCPU := float64(w.GetRequestsMilliCPU()) / float64(len(h.Pods))
+	podMiMemory := float64(w.GetRequestsMiMemory()) / float64(len(h.Pods))
+	r.RequestsMilliCPU = int(math.Round(podMilliCPU * requestsFactor * float64(r.Replicas)))
+	r.RequestsMiMemory = int(math.Round(podMiMemory * float64(r.Replicas)))
+	return r
+}
+
+// SimulateWhatIf simulates each hpa with the changes and checks if the cluster allocatable still fits the requests
+// workloads without hpa keep their requests, the nodes must hold all their pods, so the -n filter only selects the hpas.
+// The result is sorted by the replicas added, descending
+func SimulateWhatIf(hpaList []Hpa, nodeList []Node, opts WhatIfOptions) ([]HpaWhatIf, WhatIfCluster) {
+	cluster := WhatIfCluster{}
+	for _, node := range nodeList {
+		w := Wrapper{Pods: node.Pods}
+		cluster.AllocatableMilliCPU += node.GetAllocatableMilliCPU()
+		cluster.AllocatableMiMemory += node.GetAllocatableMiMemory()
+		cluster.RequestsMilliCPU += w.GetRequestsMilliCPU()
+		cluster.RequestsMiMemory += w.GetRequestsMiMemory()
+	}
+	cluster.RequestsMilliCPUAfter = cluster.RequestsMilliCPU
+	cluster.RequestsMiMemoryAfter = cluster.RequestsMiMemory
+
+	results := []HpaWhatIf{}
+	for _, h := range hpaList {
+		r := simulateHpa(h, opts)
+		cluster.RequestsMilliCPUAfter += r.RequestsMilliCPU - r.GetRequestsMilliCPU()
+		cluster.RequestsMiMemoryAfter += r.RequestsMiMemory - r.GetRequestsMiMemory()
+		results = append(results, r)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Replicas-results[i].Hpa.Replicas > results[j].Replicas-results[j].Hpa.Replicas
+	})
+	return results, cluster
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+)
+
+const whatIfHpas = `shop           api        Deployment/api        60%/80%         1         30        1          3d
+shop           worker     Deployment/worker     90%/80%         1         2         1          3d
+shop           web        Deployment/web        <unknown>/80%   1         5         2          3d`
+
+func TestDesiredReplicas(t *testing.T) {
+	// within the 10% tolerance nothing changes
+	if r := desiredReplicas(4, 85, 80, 1, 10); r != 4 {
+		t.Fatalf("Test failed! found %d expected 4", r)
+	}
+	if r := desiredReplicas(4, 120, 80, 1, 10); r != 6 {
+		t.Fatalf("Test failed! found %d expected 6", r)
+	}
+	if r := desiredReplicas(4, 10, 80, 2, 10); r != 2 {
+		t.Fatalf("Test failed! found %d expected 2", r)
+	}
+}
+
+func TestSimulateWhatIf(t *testing.T) {
+	nodes := loadConsolidationNodes()
+	hpas := buildHpaList(whatIfHpas, "", buildPodList(consolidationPods).Items)
+
+	results, cluster := SimulateWhatIf(hpas, nodes, WhatIfOptions{Traffic: 2})
+	api, worker, web := results[0], results[1], results[2]
+	if api.Hpa.Name != "api" || api.Utilization != 120 || api.Replicas != 2 || api.RequestsMilliCPU != 1000 || api.RequestsMiMemory != 1024 {
+		t.Fatalf("Test failed! %+v", api)
+	}
+	// bounded by max replicas
+	if worker.Hpa.Name != "worker" || worker.Replicas != 2 || worker.RequestsMilliCPU != 6000 {
+		t.Fatalf("Test failed! %+v", worker)
+	}
+	if web.Reason != "current cpu is <unknown>" || web.Replicas != 2 {
+		t.Fatalf("Test failed! %+v", web)
+	}
+	if cluster.AllocatableMilliCPU != 12000 || cluster.RequestsMilliCPU != 4700 || cluster.RequestsMilliCPUAfter != 8200 || cluster.RequestsMiMemoryAfter != 10496 || !cluster.FitsCPU() || !cluster.FitsMemory() {
+		t.Fatalf("Test failed! %+v", cluster)
+	}
+
+	results, _ = SimulateWhatIf(hpas, nodes, WhatIfOptions{Target: 70, CPURequestsChange: -30})
+	api = results[0]
+	if api.Hpa.Name != "api" || api.Target != 70 || api.Utilization != 86 || api.Replicas != 2 || api.RequestsMilliCPU != 700 {
+		t.Fatalf("Test failed! %+v", api)
+	}
+
+	_, cluster = SimulateWhatIf(hpas, nodes, WhatIfOptions{Traffic: 20})
+	if cluster.RequestsMilliCPUAfter != 14700 || cluster.FitsCPU() || !cluster.FitsMemory() {
+		t.Fatalf("Test failed! %+v", cluster)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"sort"
+)
+
+// Workload groups the pods created by the same controller (Deployment, StatefulSet, DaemonSet, Job, CronJob or bare Pod)
+type Workload struct {
+	Namespace string
+	Kind      string
+	Name      string
+	Pods      []Pod
+	Pdb       Pdb
+}
+
+// GetWorkloadKey returns <namespace>|<kind>/<name>
+func (w Workload) GetWorkloadKey() string {
+	return w.Namespace + "|" + w.GetReference()
+}
+
+// GetReference returns <kind>/<name>
+func (w Workload) GetReference() string {
+	return w.Kind + "/" + w.Name
+}
+
+// HasPdb ..
+func (w Workload) HasPdb() bool {
+	return w.Pdb.Metadata.Name != ""
+}
+
+// HasProbes ..
+func (w Workload) HasProbes() bool {
+	if len(w.Pods) > 0 {
+		return w.Pods[0].HasProbes()
+	}
+	return false
+}
+
+// CountLivenessProbes ..
+func (w Workload) CountLivenessProbes() string {
+	if len(w.Pods) > 0 {
+		return w.Pods[0].CountLivenessProbes()
+	}
+	return "N/A"
+}
+
+// CountReadinessProbes ..
+func (w Workload) CountReadinessProbes() string {
+	if len(w.Pods) > 0 {
+		return w.Pods[0].CountReadinessProbes()
+	}
+	return "N/A"
+}
+
+// CountLifecyclePreStop ..
+func (w Workload) CountLifecyclePreStop() string {
+	if len(w.Pods) > 0 {
+		return w.Pods[0].CountLifecyclePreStop()
+	}
+	return "N/A"
+}
+
+// GetQosClass ..
+func (w Workload) GetQosClass() string {
+	if len(w.Pods) > 0 {
+		return w.Pods[0].GetQosClass()
+	}
+	return "N/A"
+}
+
+// GetPriorityClassName ..
+func (w Workload) GetPriorityClassName() string {
+	if len(w.Pods) > 0 {
+		return w.Pods[0].Spec.PriorityClassName
+	}
+	return "N/A"
+}
+
+// BuildWorkloads groups the pods by workload and enrich them with the matching pdb
+// the result is sorted by namespace, kind and name
+func BuildWorkloads(podList []Pod, pdbList []Pdb) []Workload {
+	workloadMap := make(map[string]*Workload)
+	var keys []string
+	for _, pod := range podList {
+		key := pod.GetWorkloadKey()
+		workload, ok := workloadMap[key]
+		if !ok {
+			workload = &Workload{
+				Namespace: pod.Metadata.Namespace,
+				Kind:      pod.GetWorkloadKind(),
+				Name:      pod.GetWorkloadName(),
+			}
+			workloadMap[key] = workload
+			keys = append(keys, key)
+		}
+		workload.Pods = append(workload.Pods, pod)
+	}
+	sort.Strings(keys)
+
+	workloads := []Workload{}
+	for _, key := range keys {
+		workload := *workloadMap[key]
+		for _, pdb := range pdbList {
+			if pdb.Metadata.Namespace == workload.Namespace && pdb.match(workload.Pods[0].Metadata.Labels) {
+				workload.Pdb = pdb
+				break
+			}
+		}
+		workloads = append(workloads, workload)
+	}
+	return workloads
+}
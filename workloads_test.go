@@ -0,0 +1,74 @@
+package main
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func loadPodsWithTop(t *testing.T, podsFile string, topFile string) []Pod {
+	b, err := ioutil.ReadFile(podsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pods := buildPodList(string(b)).Items
+
+	b, err = ioutil.ReadFile(topFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	topMap := buildTopMap(string(b), "")
+	for i, pod := range pods {
+		pods[i].Top = topMap[pod.GetPodKey()]
+	}
+	return pods
+}
+
+func TestGetWorkloadKindAndName(t *testing.T) {
+	type owner = struct {
+		Kind string
+		Name string
+	}
+	type testWorkload struct {
+		res          Pod
+		expectedKind string
+		expectedName string
+	}
+	tests := []testWorkload{
+		testWorkload{res: Pod{Metadata: Metadata{Name: "shippingservice-545f46fb7f-f4c5b", OwnerReferences: []owner{{Kind: "ReplicaSet", Name: "shippingservice-545f46fb7f"}}}}, expectedKind: "Deployment", expectedName: "shippingservice"},
+		testWorkload{res: Pod{Metadata: Metadata{Name: "redis-slave-0", OwnerReferences: []owner{{Kind: "StatefulSet", Name: "redis-slave"}}}}, expectedKind: "StatefulSet", expectedName: "redis-slave"},
+		testWorkload{res: Pod{Metadata: Metadata{Name: "fluentd-gcp-v3.2.0-2x7hp", OwnerReferences: []owner{{Kind: "DaemonSet", Name: "fluentd-gcp-v3.2.0"}}}}, expectedKind: "DaemonSet", expectedName: "fluentd-gcp-v3.2.0"},
+		testWorkload{res: Pod{Metadata: Metadata{Name: "pentaho-report-1572104400-rklgx", OwnerReferences: []owner{{Kind: "Job", Name: "pentaho-report-1572104400"}}}}, expectedKind: "CronJob", expectedName: "pentaho-report"},
+		testWorkload{res: Pod{Metadata: Metadata{Name: "db-migration-rklgx", OwnerReferences: []owner{{Kind: "Job", Name: "db-migration"}}}}, expectedKind: "Job", expectedName: "db-migration"},
+		testWorkload{res: Pod{Metadata: Metadata{Name: "my-debug-pod"}}, expectedKind: "Pod", expectedName: "my-debug-pod"},
+	}
+
+	for _, test := range tests {
+		if kind, name := test.res.GetWorkloadKind(), test.res.GetWorkloadName(); kind != test.expectedKind || name != test.expectedName {
+			t.Fatalf("Test failed! %s/%s but expected %s/%s", kind, name, test.expectedKind, test.expectedName)
+		}
+	}
+}
+
+func TestBuildWorkloads(t *testing.T) {
+	pods := loadPodsWithTop(t, "test-data/many-pods.json", "test-data/top-many-pods.txt")
+	b, err := ioutil.ReadFile("test-data/pdb.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pdbs := buildPdbItems(string(b)).Items
+
+	workloads := BuildWorkloads(pods, pdbs)
+	ex := 12
+	if l := len(workloads); l != ex {
+		t.Fatalf("Test failed! found %d expected %d", l, ex)
+	}
+	adservice := workloads[0]
+	if adservice.GetWorkloadKey() != "default|Deployment/adservice" || len(adservice.Pods) != 2 || !adservice.HasPdb() || !adservice.HasProbes() {
+		t.Fatalf("Test failed! %+v", adservice)
+	}
+	for _, workload := range workloads[1:] {
+		if workload.HasPdb() {
+			t.Fatalf("Test failed! %s should not have pdb", workload.GetWorkloadKey())
+		}
+	}
+}